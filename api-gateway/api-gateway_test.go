@@ -1,20 +1,309 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/server"
 )
 
 func TestHealthCheck(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/health", nil)
 	rr := httptest.NewRecorder()
 
-	config := Config{Port: "8080"}
-	app := NewApp(config)
+	app := NewApp(DefaultConfig())
 	app.router.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
 		t.Errorf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestReadinessDependsOnDownstreams(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("При доступных сервисах ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	app.config.Services.CommentServiceURL = down.URL
+	down.Close()
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+	var report server.ReadinessReport
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if rr.Code != http.StatusServiceUnavailable || report.Checks[ServiceComments] == "ok" || report.Checks[ServiceNewsAggregator] != "ok" {
+		t.Errorf("Недоступный comment-service должен делать шлюз неготовым: %d %+v", rr.Code, report)
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/livez", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Проба живости не должна зависеть от внутренних сервисов, получен статус %d", rr.Code)
+	}
+}
+
+func TestInternalAPIsNotExposed(t *testing.T) {
+	var got http.Header
+	handler := StripInternalHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.Header }))
+	req := httptest.NewRequest("POST", "/api/v1/comment", nil)
+	req.Header.Set(idempotency.HashHeader, "forged")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if got.Get(idempotency.HashHeader) != "" {
+		t.Errorf("Заголовок %s от клиента не должен проходить через шлюз", idempotency.HashHeader)
+	}
+
+	// Правила словаря меняются только через внутренний API Censor Service
+	app := newTestApp()
+	for _, path := range []string{"/rules", "/api/v1/rules", "/api/v1/rules/audit"} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, moderatorRequest("POST", path, `{"pattern":"x"}`))
+		if rr.Code != http.StatusNotFound && rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: шлюз не должен публиковать API правил, получен статус %d", path, rr.Code)
+		}
+	}
+}
+
+func TestGetNewsFilters(t *testing.T) {
+	var gotQuery url.Values
+	newsService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		w.Write([]byte(`{"status":"success","data":[],"pagination":{"page":1,"page_size":10,"total":0,"page_count":0}}`))
+	}))
+	defer newsService.Close()
+
+	app := NewApp(DefaultConfig())
+	app.config.Services.NewsAggregatorURL = newsService.URL
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news?from=2023-01-01&to=2023-01-31&source=ria&category=sport&sort=date_asc&extra=1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
+	}
+	for name, want := range map[string]string{"from": "2023-01-01", "to": "2023-01-31", "source": "ria", "category": "sport", "sort": "date_asc", "extra": ""} {
+		if got := gotQuery.Get(name); got != want {
+			t.Errorf("Параметр %s: ожидалось %q, получено %q", name, want, got)
+		}
+	}
+
+	// Дата без времени в "to" включает весь день, как и в News Aggregator
+	for _, query := range []string{"from=2023-01-02T12:00:00Z&to=2023-01-02", "from=2023-01-02&to=2023-01-02"} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news?"+query, nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("%q: ожидался статус %d, получен %d", query, http.StatusOK, rr.Code)
+		}
+	}
+
+	for _, query := range []string{"from=today", "from=2023-02-01&to=2023-01-01", "sort=random", "sort=relevance"} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%q: ожидался статус %d, получен %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestGetNewsWithCommentCount(t *testing.T) {
+	var countCalls int
+	var gotIDs string
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countCalls++
+		gotIDs = r.URL.Query().Get("news_id")
+		w.Write([]byte(`{"status":"success","data":{"1":4,"2":0}}`))
+	}))
+	defer commentService.Close()
+	newsService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":[{"id":1,"title":"a"},{"id":2,"title":"b"}],"pagination":{"page":1,"page_size":10,"total":2,"page_count":1}}`))
+	}))
+	defer newsService.Close()
+
+	app := newTestApp()
+	app.config.Services.NewsAggregatorURL = newsService.URL
+	app.config.Services.CommentServiceURL = commentService.URL
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news", nil))
+	if countCalls != 0 || strings.Contains(rr.Body.String(), "comment_count") {
+		t.Errorf("Без include число комментариев не запрашивается: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news?include=comment_count", nil))
+	var resp struct {
+		Data []News `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if countCalls != 1 || gotIDs != "1,2" {
+		t.Errorf("Ожидался один вызов /comments/counts для 1,2, получено %d вызовов, news_id=%q", countCalls, gotIDs)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].CommentCount == nil || *resp.Data[0].CommentCount != 4 ||
+		resp.Data[1].CommentCount == nil || *resp.Data[1].CommentCount != 0 {
+		t.Errorf("Неверное число комментариев: %s", rr.Body.String())
+	}
+}
+
+func TestGetNewsPagination(t *testing.T) {
+	body := `{"status":"success","data":[{"id":11,"title":"a"}],"pagination":{"page":2,"page_size":10,"total":23,"page_count":3}}`
+	newsService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer newsService.Close()
+
+	app := newTestApp()
+	app.config.Services.NewsAggregatorURL = newsService.URL
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news?page=2&page_size=10", nil))
+	var resp struct {
+		Pagination httpx.Pagination `json:"pagination"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	want := httpx.Pagination{Page: 2, PageSize: 10, Total: 23, PageCount: 3}
+	if rr.Code != http.StatusOK || resp.Pagination != want {
+		t.Errorf("Ожидалась пагинация %+v от News Aggregator, получено %d %s", want, rr.Code, rr.Body.String())
+	}
+
+	// Без пагинации в ответе общее число новостей неизвестно
+	body = `{"status":"success","data":[]}`
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news", nil))
+	if rr.Code != http.StatusBadGateway {
+		t.Errorf("Ожидался статус %d, получен %d", http.StatusBadGateway, rr.Code)
+	}
+}
+
+func TestCreateCommentIdempotencyKey(t *testing.T) {
+	var createCalls int
+	var gotKey, gotHash string
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		createCalls++
+		gotKey = r.Header.Get(idempotency.Header)
+		gotHash = r.Header.Get(idempotency.HashHeader)
+		w.Write([]byte(`{"status":"success","data":{"id":5,"news_id":1,"text":"test"}}`))
+	}))
+	defer commentService.Close()
+
+	app := newTestApp()
+	fakeBackends(t, app)
+	app.config.Services.CommentServiceURL = commentService.URL
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(body))
+		req.Header.Set(idempotency.Header, "key-1")
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := post(`{"news_id":1,"text":"test"}`)
+	retry := post(`{"news_id":1,"text":"test"}`)
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("Повтор должен получить исходный ответ: %d %s / %d %s", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if createCalls != 1 || gotKey != "key-1" {
+		t.Errorf("Ожидался один вызов Comment Service с ключом key-1, получено %d вызовов, ключ %q", createCalls, gotKey)
+	}
+	// Comment Service сравнивает повторы по запросу клиента: тело, которое отправляет шлюз, зависит от оценки спама
+	if want := idempotency.Hash([]byte("POST"), []byte("/api/v1/comment"), []byte(`{"news_id":1,"text":"test"}`)); gotHash != want {
+		t.Errorf("Ожидался хеш запроса клиента %s, получено %q", want, gotHash)
+	}
+	if retry.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("Ожидался заголовок %s в ответе на повтор", idempotency.ReplayedHeader)
+	}
+
+	rr := post(`{"news_id":1,"text":"другой"}`)
+	if p := decodeProblem(t, rr); rr.Code != http.StatusUnprocessableEntity || p.Code != httpx.CodeIdempotencyMismatch {
+		t.Errorf("Ожидалась ошибка %s, получено %d %+v", httpx.CodeIdempotencyMismatch, rr.Code, p)
+	}
+
+	// Хеш запроса передают только доверенные сервисы: клиент не может выдать другой запрос за повтор
+	forged := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(body))
+		req.Header.Set(idempotency.Header, "key-2")
+		req.Header.Set(idempotency.HashHeader, "forged")
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		return rr
+	}
+	forged(`{"news_id":1,"text":"test"}`)
+	if rr := forged(`{"news_id":1,"text":"другой"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Заголовок %s от клиента должен игнорироваться: %d %s", idempotency.HashHeader, rr.Code, rr.Body.String())
+	}
+}
+
+func TestCreateCommentPassesCensorScope(t *testing.T) {
+	var gotBody, censorBody map[string]any
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"status":"success","data":{"id":5,"news_id":1,"text":"test"}}`))
+	}))
+	defer commentService.Close()
+	censorService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&censorBody)
+		w.Write([]byte(`{"status":"success","data":{"verdict":"accept","spam_score":0,"language":"ru","policy":"kids"}}`))
+	}))
+	defer censorService.Close()
+
+	app := newTestApp()
+	fakeBackends(t, app)
+	app.config.Services.CommentServiceURL = commentService.URL
+	app.config.Services.CensorServiceURL = censorService.URL
+	app.config.CensorPolicies = map[string]string{"politics": "strict"}
+
+	// Язык для проверки может передать клиент, политику выбирает шлюз по разделу новости, а не клиент
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"test","language":"en","policy":"none"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if censorBody["language"] != "en" || censorBody["policy"] != "strict" {
+		t.Errorf("Censor Service должен получить язык клиента и политику раздела politics: %v", censorBody)
+	}
+	// В Comment Service уходят язык и политика, по которым Censor Service проверил текст: они нужны для повторной проверки
+	if gotBody["language"] != "ru" || gotBody["policy"] != "kids" {
+		t.Errorf("В Comment Service должны уходить язык и политика проверки: %v", gotBody)
+	}
+	if strings.Contains(rr.Body.String(), "language") {
+		t.Errorf("Клиенту язык проверки не возвращается: %s", rr.Body.String())
+	}
+}
+
+func TestCreateCommentSanitizedBeforeCensor(t *testing.T) {
+	app, _ := newGraphQLTestApp()
+	comments := &savingComments{}
+	app.comments = comments
+
+	// Управляющие и невидимые символы внутри запрещенного слова не должны скрывать его от Censor Service
+	for _, text := range []string{
+		"купи qw\x00er\u202ety",
+		"купи qwe\u200brty",
+		"купи q\u2060we\ufeffrty",
+		"купи qwe\u00adrty",
+		"купи qwe\u200drty",
+	} {
+		_, problem := app.createComment(t.Context(), Comment{NewsID: 1, Text: text})
+		if problem == nil || problem.Code != httpx.CodeForbiddenWords {
+			t.Errorf("%q: ожидался отказ forbidden_words, получено %+v", text, problem)
+		}
+	}
+
+	if _, problem := app.createComment(t.Context(), Comment{NewsID: 1, Author: "an\x07na", Text: "при\xffвет\r\n"}); problem != nil {
+		t.Fatal(problem)
+	}
+	if comments.saved.Text != "привет" || comments.saved.Author != "anna" {
+		t.Errorf("В Comment Service должен уходить очищенный текст: %+v", comments.saved)
+	}
+}
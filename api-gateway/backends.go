@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"pkg/httpx"
+)
+
+// Протоколы вызова внутренних сервисов
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// NewsQuery — параметры списка новостей
+type NewsQuery struct {
+	Page     int
+	PageSize int
+	Search   string
+	From     string
+	To       string
+	Source   string
+	Category string
+	Sort     string
+}
+
+// CommentSearch — параметры поиска комментариев
+type CommentSearch struct {
+	Q        string
+	NewsID   int
+	Author   string
+	Status   string
+	From     string
+	To       string
+	Page     int
+	PageSize int
+}
+
+// NewsBackend — клиент News Aggregator
+type NewsBackend interface {
+	// ListNews — страница новостей и число новостей, подходящих под фильтр, на всех страницах
+	ListNews(ctx context.Context, q NewsQuery) ([]News, int, *httpx.Problem)
+	GetNews(ctx context.Context, id int) (*News, *httpx.Problem)
+}
+
+// CommentBackend — клиент Comment Service
+type CommentBackend interface {
+	// ListComments — комментарии к одной или нескольким новостям за один вызов
+	ListComments(ctx context.Context, newsIDs ...int) ([]Comment, *httpx.Problem)
+	// CountComments — число комментариев к каждой из новостей за один вызов
+	CountComments(ctx context.Context, newsIDs ...int) (map[int]int, *httpx.Problem)
+	CreateComment(ctx context.Context, comment Comment) (*Comment, *httpx.Problem)
+	SearchComments(ctx context.Context, q CommentSearch) ([]Comment, *httpx.Problem)
+	// SetCommentStatus — публикует комментарий из очереди модерации или возвращает его в очередь;
+	// возвращает комментарий и его статус до изменения
+	SetCommentStatus(ctx context.Context, id int, status string) (*Comment, string, *httpx.Problem)
+}
+
+// CensorBackend — клиент Censor Service. Запрещенные слова — ошибка forbidden_words,
+// в остальных случаях возвращается решение по оценке спама.
+type CensorBackend interface {
+	CheckText(ctx context.Context, check CensorCheck) (*CensorVerdict, *httpx.Problem)
+}
+
+// CensorCheck — текст для проверки в Censor Service. Пустой Language определяется по тексту,
+// пустая Policy — политика по умолчанию.
+type CensorCheck struct {
+	Text     string
+	Author   string
+	Language string
+	Policy   string
+}
+
+// Решения Censor Service по оценке спама
+const (
+	VerdictAccept = "accept"
+	VerdictQueue  = "queue"
+	VerdictReject = "reject"
+)
+
+// CensorVerdict — решение Censor Service: accept, queue или reject, оценка спама от 0 до 1,
+// сработавшие правила и фрагменты текста, которые нужно скрыть перед публикацией
+type CensorVerdict struct {
+	Verdict   string       `json:"verdict"`
+	SpamScore float64      `json:"spam_score"`
+	Signals   []SpamSignal `json:"signals,omitempty"`
+	Mask      []TextSpan   `json:"mask,omitempty"`
+	// Language и Policy — язык и политика, по которым выбраны правила словаря
+	Language string `json:"language,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+}
+
+// SpamSignal — вклад одного правила в оценку спама
+type SpamSignal struct {
+	Rule   string  `json:"rule"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// httpNews — News Aggregator по HTTP/JSON
+type httpNews struct{ a *App }
+
+func (b httpNews) ListNews(ctx context.Context, q NewsQuery) ([]News, int, *httpx.Problem) {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(q.Page))
+	params.Set("page_size", strconv.Itoa(q.PageSize))
+	setParams(params, map[string]string{
+		"search":   q.Search,
+		"from":     q.From,
+		"to":       q.To,
+		"source":   q.Source,
+		"category": q.Category,
+		"sort":     q.Sort,
+	})
+
+	var news []News
+	target := b.a.config.Services.NewsAggregatorURL + "/news?" + params.Encode()
+	pagination, problem := b.a.callServicePage(ctx, ServiceNewsAggregator, http.MethodGet, target, nil, &news)
+	if problem != nil {
+		return nil, 0, problem
+	}
+	if pagination == nil {
+		return nil, 0, invalidResponseProblem(ServiceNewsAggregator)
+	}
+	return news, pagination.Total, nil
+}
+
+func (b httpNews) GetNews(ctx context.Context, id int) (*News, *httpx.Problem) {
+	var news News
+	target := fmt.Sprintf("%s/news/%d", b.a.config.Services.NewsAggregatorURL, id)
+	if problem := b.a.callService(ctx, ServiceNewsAggregator, http.MethodGet, target, nil, &news); problem != nil {
+		return nil, problem
+	}
+	return &news, nil
+}
+
+// httpComments — Comment Service по HTTP/JSON
+type httpComments struct{ a *App }
+
+func (b httpComments) ListComments(ctx context.Context, newsIDs ...int) ([]Comment, *httpx.Problem) {
+	var comments []Comment
+	target := b.a.config.Services.CommentServiceURL + "/comments?news_id=" + joinIDs(newsIDs)
+	if problem := b.a.callService(ctx, ServiceComments, http.MethodGet, target, nil, &comments); problem != nil {
+		return nil, problem
+	}
+	return comments, nil
+}
+
+func (b httpComments) CountComments(ctx context.Context, newsIDs ...int) (map[int]int, *httpx.Problem) {
+	var counts map[int]int
+	target := b.a.config.Services.CommentServiceURL + "/comments/counts?news_id=" + joinIDs(newsIDs)
+	if problem := b.a.callService(ctx, ServiceComments, http.MethodGet, target, nil, &counts); problem != nil {
+		return nil, problem
+	}
+	return counts, nil
+}
+
+func (b httpComments) CreateComment(ctx context.Context, comment Comment) (*Comment, *httpx.Problem) {
+	var created Comment
+	// Язык и политика не входят в JSON комментария для клиентов, но нужны Comment Service
+	payload := struct {
+		Comment
+		Language string `json:"language,omitempty"`
+		Policy   string `json:"policy,omitempty"`
+	}{comment, comment.Language, comment.Policy}
+	target := b.a.config.Services.CommentServiceURL + "/comments"
+	if problem := b.a.callService(ctx, ServiceComments, http.MethodPost, target, payload, &created); problem != nil {
+		return nil, problem
+	}
+	return &created, nil
+}
+
+func (b httpComments) SearchComments(ctx context.Context, q CommentSearch) ([]Comment, *httpx.Problem) {
+	params := url.Values{}
+	setParams(params, map[string]string{
+		"q":      q.Q,
+		"author": q.Author,
+		"status": q.Status,
+		"from":   q.From,
+		"to":     q.To,
+	})
+	for name, value := range map[string]int{"news_id": q.NewsID, "page": q.Page, "page_size": q.PageSize} {
+		if value != 0 {
+			params.Set(name, strconv.Itoa(value))
+		}
+	}
+
+	var comments []Comment
+	target := b.a.config.Services.CommentServiceURL + "/comments/search?" + params.Encode()
+	if problem := b.a.callService(ctx, ServiceComments, http.MethodGet, target, nil, &comments); problem != nil {
+		return nil, problem
+	}
+	return comments, nil
+}
+
+func (b httpComments) SetCommentStatus(ctx context.Context, id int, status string) (*Comment, string, *httpx.Problem) {
+	var resp struct {
+		Comment
+		PreviousStatus string `json:"previous_status"`
+	}
+	target := fmt.Sprintf("%s/comments/%d/status", b.a.config.Services.CommentServiceURL, id)
+	payload := map[string]string{"status": status}
+	if problem := b.a.callService(ctx, ServiceComments, http.MethodPut, target, payload, &resp); problem != nil {
+		return nil, "", problem
+	}
+	return &resp.Comment, resp.PreviousStatus, nil
+}
+
+// httpCensor — Censor Service по HTTP/JSON
+type httpCensor struct{ a *App }
+
+func (b httpCensor) CheckText(ctx context.Context, check CensorCheck) (*CensorVerdict, *httpx.Problem) {
+	var verdict CensorVerdict
+	payload := map[string]string{"text": check.Text, "author": check.Author, "language": check.Language, "policy": check.Policy}
+	if problem := b.a.callService(ctx, ServiceCensor, http.MethodPost, b.a.config.Services.CensorServiceURL+"/check", payload, &verdict); problem != nil {
+		return nil, problem
+	}
+	return &verdict, nil
+}
+
+// joinIDs — список ID через запятую для параметра news_id
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+// setParams — добавляет непустые параметры запроса
+func setParams(params url.Values, values map[string]string) {
+	for name, value := range values {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния предохранителя
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// CircuitBreaker — предохранитель для вызовов внутреннего сервиса.
+// После failureThreshold сбоев подряд он размыкается и на openTimeout отклоняет вызовы,
+// не нагружая упавший сервис; затем пропускает один пробный вызов (half_open)
+// и по его результату замыкается или снова размыкается.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	trial            bool
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+}
+
+// BreakerStatus — снимок состояния предохранителя
+type BreakerStatus struct {
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// NewCircuitBreaker — создает замкнутый предохранитель
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:            BreakerClosed,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// Allow — можно ли выполнить вызов. В состоянии half_open пропускается только один вызов,
+// пока не известен его результат.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	return true
+}
+
+// Success — фиксирует успешный вызов и замыкает предохранитель
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure — фиксирует сбой; при достижении порога или сбое пробного вызова предохранитель размыкается
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Abort — вызов завершился без результата (например, отменен клиентом);
+// состояние не меняется, но пробный вызов в half_open снова разрешен
+func (b *CircuitBreaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// Status — текущее состояние предохранителя
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{State: b.state, Failures: b.failures}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
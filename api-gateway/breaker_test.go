@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		b.Failure()
+	}
+	if !b.Allow() || b.Status().State != BreakerClosed {
+		t.Fatalf("До порога предохранитель должен быть замкнут: %+v", b.Status())
+	}
+	b.Failure()
+	if b.Allow() || b.Status().State != BreakerOpen {
+		t.Fatalf("После 3 сбоев предохранитель должен разомкнуться: %+v", b.Status())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("После таймаута должен пропускаться пробный вызов")
+	}
+	if b.Allow() || b.Status().State != BreakerHalfOpen {
+		t.Fatalf("В half_open пропускается только один вызов: %+v", b.Status())
+	}
+	b.Failure()
+	if b.Allow() || b.Status().State != BreakerOpen {
+		t.Fatalf("Сбой пробного вызова должен снова разомкнуть предохранитель: %+v", b.Status())
+	}
+
+	now = now.Add(time.Minute)
+	b.Allow()
+	b.Success()
+	if status := b.Status(); status.State != BreakerClosed || status.Failures != 0 || status.OpenedAt != nil {
+		t.Errorf("Успешный пробный вызов должен замкнуть предохранитель: %+v", status)
+	}
+}
+
+func TestCallServiceOpensBreaker(t *testing.T) {
+	calls := 0
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer commentService.Close()
+
+	app := newTestApp()
+	fakeBackends(t, app)
+	app.config.Services.CommentServiceURL = commentService.URL
+	threshold := app.config.Breaker.FailureThreshold
+	for i := 0; i < threshold+2; i++ {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"test"}`)))
+		want := http.StatusBadGateway
+		if i >= threshold {
+			want = http.StatusServiceUnavailable
+		}
+		if rr.Code != want {
+			t.Errorf("Запрос %d: ожидался статус %d, получен %d", i+1, want, rr.Code)
+		}
+	}
+	if calls != threshold {
+		t.Errorf("Разомкнутый предохранитель не должен пропускать запросы: сервис вызван %d раз", calls)
+	}
+	if state := app.breakers[ServiceComments].Status().State; state != BreakerOpen {
+		t.Errorf("Ожидалось состояние %s, получено %s", BreakerOpen, state)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"pkg/config"
+	"pkg/idempotency"
+	"pkg/server"
+)
+
+// Config — конфигурация шлюза
+type Config struct {
+	Port           string                `yaml:"port" desc:"порт HTTP-сервера"`
+	ModeratorToken string                `yaml:"moderator_token" env:"MODERATOR_TOKEN" secret:"true" desc:"токен модератора для поиска комментариев"`
+	RequestTimeout time.Duration         `yaml:"request_timeout" desc:"таймаут обработки входящего запроса"`
+	IdempotencyTTL time.Duration         `yaml:"idempotency_ttl" desc:"сколько хранится ответ на POST /comment с Idempotency-Key"`
+	NewsCacheTTL   time.Duration         `yaml:"news_cache_ttl" desc:"сколько помнить существование новости при проверке комментариев"`
+	Services       ServicesConfig        `yaml:"services"`
+	Limits         LimitsConfig          `yaml:"limits"`
+	Breaker        BreakerConfig         `yaml:"breaker"`
+	GraphQL        GraphQLConfig         `yaml:"graphql"`
+	CensorPolicies map[string]string     `yaml:"censor_policies" desc:"политика Censor Service для раздела (категории) новостей (только в файле)"`
+	Shutdown       server.ShutdownConfig `yaml:"shutdown"`
+}
+
+// ServicesConfig — адреса внутренних сервисов; переменные окружения сохранены прежними.
+// HTTP-адреса нужны при любом протоколе: по ним проверяется доступность сервисов.
+type ServicesConfig struct {
+	Transport          string        `yaml:"transport" desc:"протокол вызова внутренних сервисов: http или grpc"`
+	CallTimeout        time.Duration `yaml:"call_timeout" desc:"крайний срок одного вызова внутреннего сервиса"`
+	NewsAggregatorURL  string        `yaml:"news_aggregator_url" env:"NEWS_AGGREGATOR_URL" desc:"URL сервиса новостей"`
+	CommentServiceURL  string        `yaml:"comment_service_url" env:"COMMENT_SERVICE_URL" desc:"URL сервиса комментариев"`
+	CensorServiceURL   string        `yaml:"censor_service_url" env:"CENSOR_SERVICE_URL" desc:"URL сервиса цензуры"`
+	NewsAggregatorGRPC string        `yaml:"news_aggregator_grpc" env:"NEWS_AGGREGATOR_GRPC" desc:"адрес gRPC API сервиса новостей"`
+	CommentServiceGRPC string        `yaml:"comment_service_grpc" env:"COMMENT_SERVICE_GRPC" desc:"адрес gRPC API сервиса комментариев"`
+	CensorServiceGRPC  string        `yaml:"censor_service_grpc" env:"CENSOR_SERVICE_GRPC" desc:"адрес gRPC API сервиса цензуры"`
+}
+
+// LimitsConfig — ограничения на параметры запросов
+type LimitsConfig struct {
+	MaxSearchLength        int `yaml:"max_search_length" desc:"максимальная длина поиска и фильтров новостей в символах"`
+	MaxCommentSearchLength int `yaml:"max_comment_search_length" desc:"максимальная длина поиска комментариев в символах"`
+	DefaultPageSize        int `yaml:"default_page_size" desc:"размер страницы новостей по умолчанию"`
+	MaxPageSize            int `yaml:"max_page_size" desc:"максимальный размер страницы новостей"`
+}
+
+// BreakerConfig — параметры предохранителей внутренних сервисов
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold" desc:"число сбоев подряд до размыкания"`
+	OpenTimeout      time.Duration `yaml:"open_timeout" desc:"сколько предохранитель остается разомкнутым"`
+}
+
+// GraphQLConfig — ограничения запросов /api/v1/graphql и объединение запросов комментариев
+type GraphQLConfig struct {
+	MaxDepth      int           `yaml:"max_depth" desc:"максимальная глубина запроса GraphQL"`
+	MaxComplexity int           `yaml:"max_complexity" desc:"максимальная оценка сложности запроса GraphQL"`
+	ListSize      int           `yaml:"list_size" desc:"ожидаемая длина списков без pageSize при оценке сложности"`
+	BatchWait     time.Duration `yaml:"batch_wait" desc:"сколько собирать запросы комментариев в один вызов Comment Service"`
+	MaxBatch      int           `yaml:"max_batch" desc:"максимум новостей в одном вызове Comment Service"`
+}
+
+// maxCommentBatch — сколько новостей Comment Service принимает в одном запросе комментариев
+const maxCommentBatch = 100
+
+// DefaultConfig — конфигурация по умолчанию для запуска в docker-compose
+func DefaultConfig() Config {
+	return Config{
+		Port:           "8080",
+		RequestTimeout: 30 * time.Second,
+		IdempotencyTTL: idempotency.DefaultTTL,
+		NewsCacheTTL:   10 * time.Minute,
+		Services: ServicesConfig{
+			Transport:          TransportHTTP,
+			CallTimeout:        10 * time.Second,
+			NewsAggregatorURL:  "http://news-aggregator:8083",
+			CommentServiceURL:  "http://comment-service:8081",
+			CensorServiceURL:   "http://censor-service:8082",
+			NewsAggregatorGRPC: "news-aggregator:9083",
+			CommentServiceGRPC: "comment-service:9081",
+			CensorServiceGRPC:  "censor-service:9082",
+		},
+		Limits: LimitsConfig{
+			MaxSearchLength:        100,
+			MaxCommentSearchLength: 200,
+			DefaultPageSize:        10,
+			MaxPageSize:            100,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      6,
+			MaxComplexity: 1000,
+			ListSize:      10,
+			BatchWait:     2 * time.Millisecond,
+			MaxBatch:      maxCommentBatch,
+		},
+		Shutdown: server.DefaultShutdownConfig(),
+	}
+}
+
+// Validate — проверяет конфигурацию и сообщает обо всех ошибках сразу
+func (c *Config) Validate() error {
+	var errs config.Errors
+	errs.Port("port", c.Port)
+	errs.PositiveDuration("request_timeout", c.RequestTimeout)
+	errs.PositiveDuration("idempotency_ttl", c.IdempotencyTTL)
+	errs.PositiveDuration("news_cache_ttl", c.NewsCacheTTL)
+	errs.OneOf("services.transport", c.Services.Transport, TransportHTTP, TransportGRPC)
+	errs.PositiveDuration("services.call_timeout", c.Services.CallTimeout)
+	errs.URL("services.news_aggregator_url", c.Services.NewsAggregatorURL)
+	errs.URL("services.comment_service_url", c.Services.CommentServiceURL)
+	errs.URL("services.censor_service_url", c.Services.CensorServiceURL)
+	if c.Services.Transport == TransportGRPC {
+		errs.HostPort("services.news_aggregator_grpc", c.Services.NewsAggregatorGRPC)
+		errs.HostPort("services.comment_service_grpc", c.Services.CommentServiceGRPC)
+		errs.HostPort("services.censor_service_grpc", c.Services.CensorServiceGRPC)
+	}
+	errs.Positive("limits.max_search_length", c.Limits.MaxSearchLength)
+	errs.Positive("limits.max_comment_search_length", c.Limits.MaxCommentSearchLength)
+	errs.Positive("limits.default_page_size", c.Limits.DefaultPageSize)
+	errs.Positive("limits.max_page_size", c.Limits.MaxPageSize)
+	if c.Limits.DefaultPageSize > c.Limits.MaxPageSize {
+		errs.Add("limits.default_page_size", "must not exceed limits.max_page_size (%d)", c.Limits.MaxPageSize)
+	}
+	errs.Positive("breaker.failure_threshold", c.Breaker.FailureThreshold)
+	errs.PositiveDuration("breaker.open_timeout", c.Breaker.OpenTimeout)
+	errs.Positive("graphql.max_depth", c.GraphQL.MaxDepth)
+	errs.Positive("graphql.max_complexity", c.GraphQL.MaxComplexity)
+	errs.Positive("graphql.list_size", c.GraphQL.ListSize)
+	errs.NonNegativeDuration("graphql.batch_wait", c.GraphQL.BatchWait)
+	errs.Positive("graphql.max_batch", c.GraphQL.MaxBatch)
+	if c.GraphQL.MaxBatch > maxCommentBatch {
+		errs.Add("graphql.max_batch", "must not exceed %d, got %d", maxCommentBatch, c.GraphQL.MaxBatch)
+	}
+	sections := make([]string, 0, len(c.CensorPolicies))
+	for section := range c.CensorPolicies {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		if policy := c.CensorPolicies[section]; policy == "" || policy != strings.ToLower(strings.TrimSpace(policy)) {
+			errs.Add("censor_policies."+section, "policy name must be non-empty and lowercase, got %q", policy)
+		}
+	}
+	c.Shutdown.Validate(&errs, "shutdown")
+	return errs.Err()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pkg/config"
+)
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Конфигурация по умолчанию должна быть корректной: %v", err)
+	}
+}
+
+func TestConfigValidateReportsAllErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Port = "http"
+	cfg.Services.CommentServiceURL = "comment-service:8081"
+	cfg.Limits.DefaultPageSize = 500
+	cfg.Breaker.OpenTimeout = 0
+	cfg.Services.Transport = TransportGRPC
+	cfg.Services.CensorServiceGRPC = "censor-service"
+	cfg.GraphQL.MaxBatch = 500
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Ожидалась ошибка проверки")
+	}
+	for _, key := range []string{"port:", "services.comment_service_url:", "limits.default_page_size:", "breaker.open_timeout:", "services.censor_service_grpc:", "graphql.max_batch:"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Ошибка должна упоминать %s: %v", key, err)
+		}
+	}
+}
+
+func TestConfigKeepsLegacyEnv(t *testing.T) {
+	cfg := DefaultConfig()
+	_, err := config.Load("api-gateway", &cfg, []string{"--request-timeout", "5s"}, func(key string) (string, bool) {
+		v, ok := map[string]string{
+			"PORT":                "9090",
+			"MODERATOR_TOKEN":     "secret",
+			"NEWS_AGGREGATOR_URL": "http://localhost:8083",
+		}[key]
+		return v, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "9090" || cfg.ModeratorToken != "secret" || cfg.Services.NewsAggregatorURL != "http://localhost:8083" || cfg.RequestTimeout != 5*time.Second {
+		t.Errorf("Переменные окружения и флаги не применены: %+v", cfg)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pkg/httpx"
+)
+
+// commentLoader — объединяет запросы комментариев к новостям, сделанные в пределах wait,
+// в один вызов Comment Service. Загрузчик создается на каждый запрос GraphQL
+// и хранит полученные комментарии до его завершения.
+type commentLoader struct {
+	ctx      context.Context
+	backend  CommentBackend
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *commentBatch         // пакет, который еще собирается
+	batches map[int]*commentBatch // пакет, загружающий комментарии новости
+}
+
+// commentBatch — один вызов Comment Service для нескольких новостей
+type commentBatch struct {
+	newsIDs  []int
+	done     chan struct{}
+	comments map[int][]Comment
+	problem  *httpx.Problem
+}
+
+// newCommentLoader — загрузчик, выполняющий вызовы в контексте запроса ctx
+func newCommentLoader(ctx context.Context, backend CommentBackend, wait time.Duration, maxBatch int) *commentLoader {
+	return &commentLoader{
+		ctx:      ctx,
+		backend:  backend,
+		wait:     wait,
+		maxBatch: maxBatch,
+		batches:  make(map[int]*commentBatch),
+	}
+}
+
+// Load — комментарии к новости; повторный запрос той же новости не вызывает сервис
+func (l *commentLoader) Load(newsID int) ([]Comment, *httpx.Problem) {
+	l.mu.Lock()
+	b, ok := l.batches[newsID]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &commentBatch{done: make(chan struct{})}
+			pending := l.pending
+			time.AfterFunc(l.wait, func() { l.dispatch(pending) })
+		}
+		b = l.pending
+		b.newsIDs = append(b.newsIDs, newsID)
+		l.batches[newsID] = b
+		if len(b.newsIDs) >= l.maxBatch {
+			l.pending = nil
+			go l.fetch(b)
+		}
+	}
+	l.mu.Unlock()
+
+	<-b.done
+	return b.comments[newsID], b.problem
+}
+
+// dispatch — по истечении wait закрывает сбор пакета, если он еще не отправлен заполненным
+func (l *commentLoader) dispatch(b *commentBatch) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	l.fetch(b)
+}
+
+// fetch — загружает комментарии пакета и будит ожидающих
+func (l *commentLoader) fetch(b *commentBatch) {
+	comments, problem := l.backend.ListComments(l.ctx, b.newsIDs...)
+	b.comments = make(map[int][]Comment, len(b.newsIDs))
+	for _, c := range comments {
+		b.comments[c.NewsID] = append(b.comments[c.NewsID], c)
+	}
+	b.problem = problem
+	close(b.done)
+}
+
+type commentLoaderKey struct{}
+
+// withCommentLoader — контекст запроса GraphQL с загрузчиком комментариев
+func withCommentLoader(ctx context.Context, l *commentLoader) context.Context {
+	return context.WithValue(ctx, commentLoaderKey{}, l)
+}
+
+// commentLoaderFrom — загрузчик комментариев текущего запроса GraphQL
+func commentLoaderFrom(ctx context.Context) *commentLoader {
+	l, _ := ctx.Value(commentLoaderKey{}).(*commentLoader)
+	return l
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"pkg/httpx"
+)
+
+// fakeComments — Comment Service в памяти, запоминающий запрошенные пакеты новостей
+type fakeComments struct {
+	mu       sync.Mutex
+	calls    [][]int
+	comments []Comment
+	problem  *httpx.Problem
+}
+
+func (f *fakeComments) ListComments(_ context.Context, newsIDs ...int) ([]Comment, *httpx.Problem) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, newsIDs)
+	if f.problem != nil {
+		return nil, f.problem
+	}
+	var result []Comment
+	for _, c := range f.comments {
+		for _, id := range newsIDs {
+			if c.NewsID == id {
+				result = append(result, c)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeComments) CountComments(_ context.Context, newsIDs ...int) (map[int]int, *httpx.Problem) {
+	comments, problem := f.ListComments(context.Background(), newsIDs...)
+	counts := make(map[int]int)
+	for _, c := range comments {
+		counts[c.NewsID]++
+	}
+	return counts, problem
+}
+
+func (f *fakeComments) CreateComment(_ context.Context, comment Comment) (*Comment, *httpx.Problem) {
+	comment.ID = 100
+	return &comment, nil
+}
+
+func (f *fakeComments) SearchComments(context.Context, CommentSearch) ([]Comment, *httpx.Problem) {
+	return nil, nil
+}
+
+func (f *fakeComments) SetCommentStatus(_ context.Context, id int, status string) (*Comment, string, *httpx.Problem) {
+	return &Comment{ID: id, Status: status}, CommentPending, nil
+}
+
+func (f *fakeComments) batches() [][]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]int(nil), f.calls...)
+}
+
+func loadAll(l *commentLoader, newsIDs ...int) [][]Comment {
+	result := make([][]Comment, len(newsIDs))
+	var wg sync.WaitGroup
+	for i, id := range newsIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result[i], _ = l.Load(id)
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+func TestCommentLoaderBatches(t *testing.T) {
+	backend := &fakeComments{comments: []Comment{
+		{ID: 1, NewsID: 1, Text: "a"},
+		{ID: 2, NewsID: 2, Text: "b"},
+		{ID: 3, NewsID: 1, Text: "c"},
+	}}
+	l := newCommentLoader(context.Background(), backend, 10*time.Millisecond, 100)
+
+	result := loadAll(l, 1, 2, 3, 1)
+	if calls := backend.batches(); len(calls) != 1 || len(calls[0]) != 3 {
+		t.Fatalf("Ожидался один вызов для новостей 1, 2, 3, получено %v", calls)
+	}
+	if len(result[0]) != 2 || len(result[1]) != 1 || len(result[2]) != 0 || len(result[3]) != 2 {
+		t.Errorf("Неверная раскладка комментариев по новостям: %v", result)
+	}
+
+	// Повторная загрузка берется из кэша загрузчика
+	if comments, _ := l.Load(2); len(comments) != 1 || len(backend.batches()) != 1 {
+		t.Errorf("Повторный запрос не должен вызывать сервис: %v %v", comments, backend.batches())
+	}
+}
+
+func TestCommentLoaderMaxBatch(t *testing.T) {
+	backend := &fakeComments{}
+	l := newCommentLoader(context.Background(), backend, time.Hour, 2)
+
+	// При заполнении пакет отправляется, не дожидаясь окончания wait
+	done := make(chan struct{})
+	go func() {
+		loadAll(l, 1, 2)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Заполненный пакет не был отправлен")
+	}
+	if calls := backend.batches(); len(calls) != 1 || len(calls[0]) != 2 {
+		t.Errorf("Ожидался один вызов для двух новостей, получено %v", calls)
+	}
+}
+
+func TestCommentLoaderProblem(t *testing.T) {
+	backend := &fakeComments{problem: unavailableProblem(ServiceComments)}
+	l := newCommentLoader(context.Background(), backend, time.Millisecond, 100)
+
+	if _, problem := l.Load(1); problem == nil || problem.Status != http.StatusServiceUnavailable {
+		t.Errorf("Ожидалась ошибка недоступности сервиса, получено %v", problem)
+	}
+}
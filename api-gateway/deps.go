@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"pkg/buildinfo"
+	"pkg/httpx"
+	"pkg/server"
+)
+
+// dependencyCheckTimeout — сколько ждать ответа одного сервиса при проверке
+const dependencyCheckTimeout = 2 * time.Second
+
+// Состояния зависимостей на панели
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+// DependencyStatus — состояние внутреннего сервиса
+type DependencyStatus struct {
+	Name      string        `json:"name"`
+	URL       string        `json:"url"`
+	Status    string        `json:"status"`
+	LatencyMS float64       `json:"latency_ms"`
+	Version   string        `json:"version,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Breaker   BreakerStatus `json:"breaker"`
+}
+
+// DependencyReport — сводка по шлюзу и его зависимостям
+type DependencyReport struct {
+	Status       string             `json:"status"`
+	Version      string             `json:"version"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// dependencies — внутренние сервисы шлюза и их адреса
+func (a *App) dependencies() []DependencyStatus {
+	return []DependencyStatus{
+		{Name: ServiceNewsAggregator, URL: a.config.Services.NewsAggregatorURL},
+		{Name: ServiceComments, URL: a.config.Services.CommentServiceURL},
+		{Name: ServiceCensor, URL: a.config.Services.CensorServiceURL},
+	}
+}
+
+// checkDependencies — параллельно опрашивает /livez всех внутренних сервисов
+func (a *App) checkDependencies(ctx context.Context) DependencyReport {
+	deps := a.dependencies()
+
+	var wg sync.WaitGroup
+	for i := range deps {
+		wg.Add(1)
+		go func(dep *DependencyStatus) {
+			defer wg.Done()
+			a.checkDependency(ctx, dep)
+		}(&deps[i])
+	}
+	wg.Wait()
+
+	report := DependencyReport{
+		Status:       "ok",
+		Version:      buildinfo.Version(),
+		CheckedAt:    time.Now().UTC(),
+		Dependencies: deps,
+	}
+	for _, dep := range deps {
+		if dep.Status != DependencyUp || dep.Breaker.State != BreakerClosed {
+			report.Status = "degraded"
+		}
+	}
+	return report
+}
+
+// checkDependency — проверяет один сервис и заполняет его состояние
+func (a *App) checkDependency(ctx context.Context, dep *DependencyStatus) {
+	if breaker := a.breakers[dep.Name]; breaker != nil {
+		dep.Breaker = breaker.Status()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, dep.URL+"/livez", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := a.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		var live server.LivenessReport
+		if err := json.NewDecoder(resp.Body).Decode(&live); err == nil {
+			dep.Version = live.Version
+		}
+		return nil
+	}()
+	dep.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+
+	if err != nil {
+		dep.Status = DependencyDown
+		dep.Error = err.Error()
+		return
+	}
+	dep.Status = DependencyUp
+}
+
+// HealthDeps — состояние внутренних сервисов: доступность, задержка, версия и предохранитель
+func (a *App) HealthDeps(w http.ResponseWriter, r *http.Request) {
+	httpx.SendJSON(w, http.StatusOK, a.checkDependencies(r.Context()))
+}
+
+// statusPage — HTML-страница состояния, обновляется каждые 10 секунд
+var statusPage = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <meta http-equiv="refresh" content="10">
+  <title>API Gateway — состояние сервисов</title>
+  <style>
+    body { font-family: sans-serif; margin: 2em; }
+    table { border-collapse: collapse; }
+    th, td { padding: .4em 1em; border-bottom: 1px solid #ddd; text-align: left; }
+    .up, .closed, .ok { color: #1a7f37; }
+    .down, .open, .degraded { color: #cf222e; }
+    .half_open { color: #9a6700; }
+  </style>
+</head>
+<body>
+  <h1>Состояние сервисов: <span class="{{.Status}}">{{.Status}}</span></h1>
+  <p>API Gateway {{.Version}}, проверено {{.CheckedAt.Format "2006-01-02 15:04:05"}} UTC</p>
+  <table>
+    <tr><th>Сервис</th><th>Состояние</th><th>Задержка, мс</th><th>Версия</th><th>Предохранитель</th><th>Ошибка</th></tr>
+    {{- range .Dependencies}}
+    <tr>
+      <td>{{.Name}}</td>
+      <td class="{{.Status}}">{{.Status}}</td>
+      <td>{{printf "%.1f" .LatencyMS}}</td>
+      <td>{{.Version}}</td>
+      <td class="{{.Breaker.State}}">{{.Breaker.State}}{{if .Breaker.Failures}} ({{.Breaker.Failures}}){{end}}</td>
+      <td>{{.Error}}</td>
+    </tr>
+    {{- end}}
+  </table>
+</body>
+</html>
+`))
+
+// StatusPage — HTML-панель состояния внутренних сервисов. Страница собирается целиком до отправки,
+// чтобы ошибка шаблона вернулась клиенту как 500, а не как оборванный ответ 200
+func (a *App) StatusPage(w http.ResponseWriter, r *http.Request) {
+	var page bytes.Buffer
+	if err := statusPage.Execute(&page, a.checkDependencies(r.Context())); err != nil {
+		a.logger.Error().Err(err).Msg("status page render failed")
+		httpx.SendError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Failed to render status page")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(page.Bytes())
+}
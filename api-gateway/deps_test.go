@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pkg/httpx"
+)
+
+func TestHealthDeps(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+	down := httptest.NewServer(http.NotFoundHandler())
+	app.config.Services.CensorServiceURL = down.URL
+	down.Close()
+
+	app.breakers[ServiceComments].Failure()
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest("GET", "/health/deps", ""))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
+	}
+	var report DependencyReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != "degraded" || len(report.Dependencies) != 3 {
+		t.Fatalf("Неверная сводка: %+v", report)
+	}
+
+	deps := make(map[string]DependencyStatus)
+	for _, dep := range report.Dependencies {
+		deps[dep.Name] = dep
+	}
+	if news := deps[ServiceNewsAggregator]; news.Status != DependencyUp || news.Version != "test" || news.Breaker.State != BreakerClosed {
+		t.Errorf("news-aggregator должен быть доступен: %+v", news)
+	}
+	if comments := deps[ServiceComments]; comments.Breaker.Failures != 1 {
+		t.Errorf("Должно учитываться состояние предохранителя: %+v", comments)
+	}
+	if censor := deps[ServiceCensor]; censor.Status != DependencyDown || censor.Error == "" {
+		t.Errorf("censor-service должен быть недоступен: %+v", censor)
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest("GET", "/health/status", ""))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("Ожидалась HTML-страница, получено %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{ServiceNewsAggregator, ServiceComments, ServiceCensor, `class="down"`, "degraded"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("На странице состояния нет %q", want)
+		}
+	}
+}
+
+func TestHealthDepsRequiresModerator(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+	for _, path := range []string{"/health/deps", "/health/status"} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusUnauthorized || strings.Contains(rr.Body.String(), "127.0.0.1") {
+			t.Errorf("%s: без токена модератора ожидался статус %d без адресов сервисов, получено %d %s", path, http.StatusUnauthorized, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestStatusPageRenderError(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+
+	saved := statusPage
+	statusPage = template.Must(template.New("status").Parse(`{{.Missing}}`))
+	defer func() { statusPage = saved }()
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest("GET", "/health/status", ""))
+	if rr.Code != http.StatusInternalServerError || rr.Header().Get("Content-Type") != httpx.ProblemContentType {
+		t.Errorf("Ошибка шаблона должна возвращаться как 500, получено %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestDownstreamCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	cfg := DefaultConfig()
+	cfg.Services.CallTimeout = 100 * time.Millisecond
+	app := NewApp(cfg)
+
+	// Клиент ограничивает запрос сам, даже если у контекста нет крайнего срока
+	done := make(chan error, 1)
+	go func() {
+		done <- downstreamCheck(app.client, func() string { return hanging.URL })(context.Background())
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Проверка зависшего сервиса должна вернуть ошибку")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Проверка зависшего сервиса должна завершиться по call_timeout")
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/server"
+)
+
+// Имена внутренних сервисов для сообщений об ошибках
+const (
+	ServiceNewsAggregator = "news-aggregator"
+	ServiceComments       = "comment-service"
+	ServiceCensor         = "censor-service"
+)
+
+// newBreakers — по предохранителю на каждый внутренний сервис
+func newBreakers(cfg BreakerConfig) map[string]*CircuitBreaker {
+	return map[string]*CircuitBreaker{
+		ServiceNewsAggregator: NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenTimeout),
+		ServiceComments:       NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenTimeout),
+		ServiceCensor:         NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenTimeout),
+	}
+}
+
+// newServiceClient — HTTP-клиент внутренних сервисов: запрос вместе с чтением ответа ограничен callTimeout,
+// даже если вызывающий не задал крайний срок в контексте
+func newServiceClient(callTimeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &http.Client{
+		Timeout: callTimeout,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: callTimeout,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   20,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+}
+
+// callService — выполняет HTTP-запрос к внутреннему сервису не дольше services.call_timeout
+// и разбирает поле data конверта Response в out (если out не nil).
+// Любая ошибка возвращается как *httpx.Problem, готовый для клиента.
+func (a *App) callService(ctx context.Context, service, method, target string, payload, out interface{}) *httpx.Problem {
+	_, problem := a.callServicePage(ctx, service, method, target, payload, out)
+	return problem
+}
+
+// callServicePage — как callService, но возвращает и пагинацию из конверта ответа (nil, если ее нет)
+func (a *App) callServicePage(ctx context.Context, service, method, target string, payload, out interface{}) (*httpx.Pagination, *httpx.Problem) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Failed to encode request to "+service)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, a.config.Services.CallTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(callCtx, method, target, body)
+	if err != nil {
+		return nil, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Failed to build request to "+service)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if id := httpx.RequestID(ctx); id != "" {
+		req.Header.Set(httpx.RequestIDHeader, id)
+	}
+	if key := idempotency.Key(ctx); key != "" {
+		req.Header.Set(idempotency.Header, key)
+	}
+	// Тело запроса к Comment Service зависит от решения Censor Service; повторы сравниваются по запросу клиента
+	if hash := idempotency.RequestHash(ctx); hash != "" {
+		req.Header.Set(idempotency.HashHeader, hash)
+	}
+
+	breaker := a.breakers[service]
+	if breaker != nil && !breaker.Allow() {
+		return nil, breakerOpenProblem(service)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.logger.Error().Err(err).Str("service", service).Msg("downstream request failed")
+		// Отмена запроса клиентом не говорит о состоянии сервиса
+		if breaker != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				breaker.Abort()
+			} else {
+				breaker.Failure()
+			}
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, timeoutProblem(service)
+		}
+		return nil, unavailableProblem(service)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if breaker != nil {
+			breaker.Failure()
+		}
+		return nil, httpx.NewProblem(http.StatusBadGateway, httpx.CodeUpstreamError, "Failed to read response from "+service)
+	}
+
+	if breaker != nil {
+		if resp.StatusCode >= 500 {
+			breaker.Failure()
+		} else {
+			breaker.Success()
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, downstreamProblem(service, resp.StatusCode, resp.Header.Get("Content-Type"), data)
+	}
+
+	var result struct {
+		Data       json.RawMessage   `json:"data"`
+		Pagination *httpx.Pagination `json:"pagination"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, invalidResponseProblem(service)
+	}
+	if out != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return nil, invalidResponseProblem(service)
+		}
+	}
+	return result.Pagination, nil
+}
+
+// Ошибки вызова внутреннего сервиса, общие для HTTP и gRPC
+
+func breakerOpenProblem(service string) *httpx.Problem {
+	return httpx.NewProblem(http.StatusServiceUnavailable, httpx.CodeUpstreamUnavailable, service+" is temporarily unavailable")
+}
+
+func unavailableProblem(service string) *httpx.Problem {
+	return httpx.NewProblem(http.StatusServiceUnavailable, httpx.CodeUpstreamUnavailable, service+" is unavailable")
+}
+
+func timeoutProblem(service string) *httpx.Problem {
+	return httpx.NewProblem(http.StatusGatewayTimeout, httpx.CodeUpstreamTimeout, service+" did not respond in time")
+}
+
+func invalidResponseProblem(service string) *httpx.Problem {
+	return httpx.NewProblem(http.StatusBadGateway, httpx.CodeUpstreamError, "Invalid response from "+service)
+}
+
+// downstreamProblem — переводит ошибку внутреннего сервиса в ошибку для клиента.
+// Ошибки клиента (4xx) передаются с исходным кодом и деталями, сбои сервиса (5xx) — как 502.
+func downstreamProblem(service string, status int, contentType string, body []byte) *httpx.Problem {
+	if status >= 500 {
+		return httpx.NewProblem(http.StatusBadGateway, httpx.CodeUpstreamError, fmt.Sprintf("%s responded with status %d", service, status))
+	}
+
+	var p httpx.Problem
+	if strings.HasPrefix(contentType, httpx.ProblemContentType) && json.Unmarshal(body, &p) == nil && p.Code != "" {
+		forwarded := httpx.NewProblem(status, p.Code, p.Detail)
+		forwarded.Errors = p.Errors
+		return forwarded
+	}
+
+	code := httpx.CodeUpstreamError
+	if status == http.StatusNotFound {
+		code = httpx.CodeNotFound
+	}
+	return httpx.NewProblem(status, code, fmt.Sprintf("%s rejected the request", service))
+}
+
+// downstreamCheck — проверка готовности, опрашивающая /livez внутреннего сервиса.
+// Проверяется живость, а не готовность, чтобы сбой одной зависимости сервиса
+// не выводил из балансировки всю цепочку.
+func downstreamCheck(client *http.Client, baseURL func() string) server.Check {
+	return func(ctx context.Context) error {
+		return server.HTTPCheck(client, baseURL()+"/livez")(ctx)
+	}
+}
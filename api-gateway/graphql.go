@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"pkg/httpx"
+)
+
+// graphqlSDL — схема GraphQL API шлюза
+const graphqlSDL = `
+schema {
+  query: Query
+  mutation: Mutation
+}
+
+scalar Time
+
+type Query {
+  "Список новостей с поиском и пагинацией"
+  news(page: Int = 1, pageSize: Int, search: String): [News!]!
+  "Новость по ID; null, если новости нет"
+  newsItem(id: ID!): News
+}
+
+type Mutation {
+  "Создание комментария после проверки в Censor Service"
+  createComment(input: CommentInput!): Comment!
+}
+
+type News {
+  id: ID!
+  title: String!
+  content: String!
+  date: Time!
+  source: String!
+  category: String!
+  tags: [String!]!
+  comments: [Comment!]!
+  "Авторы комментариев к новости без повторов"
+  authors: [Author!]!
+}
+
+type Comment {
+  id: ID!
+  newsId: ID!
+  parentId: ID
+  text: String!
+  "Текст, размеченный безопасным подмножеством Markdown"
+  html: String!
+  author: Author
+  "published или pending — комментарий ждет модерации"
+  status: String
+  createdAt: Time!
+}
+
+type Author {
+  name: String!
+}
+
+input CommentInput {
+  newsId: ID!
+  parentId: ID
+  author: String
+  text: String!
+  language: String
+}
+`
+
+// Коды ошибок запроса GraphQL: query_too_complex — превышена graphql.max_complexity,
+// invalid_query — запрос не разобран или не прошел проверку схемой
+const (
+	CodeQueryTooComplex = "query_too_complex"
+	CodeInvalidQuery    = "invalid_query"
+)
+
+// initGraphQL — разбирает схему GraphQL и связывает ее с резолверами
+func (a *App) initGraphQL() error {
+	// Резолверы новостей страницы выполняются параллельно, чтобы их комментарии попали в один пакет
+	schema, err := graphql.ParseSchema(graphqlSDL, &graphqlResolver{a},
+		graphql.MaxDepth(a.config.GraphQL.MaxDepth),
+		graphql.MaxParallelism(a.config.GraphQL.MaxBatch),
+	)
+	if err != nil {
+		return fmt.Errorf("graphql schema: %w", err)
+	}
+	// Та же схема для проверок до выполнения: graphql-go не дает доступа к разобранному запросу
+	// и не поддерживает собственные правила проверки, поэтому сложность запроса и тип операции
+	// определяются по документу gqlparser
+	astSchema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: graphqlSDL})
+	if err != nil {
+		return fmt.Errorf("graphql schema: %w", err)
+	}
+	a.graphqlSchema = schema
+	a.graphqlDocs = newGraphQLDocCache(astSchema)
+	return nil
+}
+
+// graphqlDocCacheSize — сколько разных запросов хранит graphqlDocCache; при переполнении кэш очищается
+const graphqlDocCacheSize = 1000
+
+// graphqlDocCache — проверенные схемой документы запросов. Клиенты повторяют одни и те же запросы
+// с разными переменными, поэтому gqlparser разбирает каждый запрос один раз, а не при каждом вызове.
+// Документ после проверки только читается и общий для всех обращений.
+type graphqlDocCache struct {
+	schema *ast.Schema
+	mu     sync.Mutex
+	docs   map[string]*ast.QueryDocument
+}
+
+func newGraphQLDocCache(schema *ast.Schema) *graphqlDocCache {
+	return &graphqlDocCache{schema: schema, docs: make(map[string]*ast.QueryDocument)}
+}
+
+// load — документ запроса или ошибки разбора и проверки схемой; запросы с ошибками не запоминаются
+func (c *graphqlDocCache) load(query string) (*ast.QueryDocument, gqlerror.List) {
+	c.mu.Lock()
+	doc, ok := c.docs[query]
+	c.mu.Unlock()
+	if ok {
+		return doc, nil
+	}
+	doc, errs := gqlparser.LoadQuery(c.schema, query)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.docs) >= graphqlDocCacheSize {
+		clear(c.docs)
+	}
+	c.docs[query] = doc
+	return doc, nil
+}
+
+// graphqlRequest — тело запроса GraphQL по HTTP
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQL — выполняет запрос GraphQL: POST с JSON-телом {"query", "operationName", "variables"}
+// или GET с теми же параметрами в строке запроса (только чтение).
+// Ответ — стандартный {"data", "errors"} без конверта Response.
+func (a *App) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				httpx.SendValidationError(w, r, httpx.FieldError{Field: "variables", Code: httpx.FieldInvalid, Message: "Variables must be a JSON object"})
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "query", Code: httpx.FieldRequired, Message: "Query is required"})
+		return
+	}
+
+	// Выполняется только запрос, который gqlparser разобрал и проверил: сложность остальных не оценить
+	op, errs := a.graphqlOperation(req)
+	if len(errs) > 0 {
+		writeGraphQL(w, &graphql.Response{Errors: errs})
+		return
+	}
+	if op.Operation == ast.Mutation && r.Method == http.MethodGet {
+		httpx.SendError(w, r, http.StatusMethodNotAllowed, httpx.CodeMethodNotAllowed, "Mutations require POST")
+		return
+	}
+	if complexity := a.queryComplexity(op.SelectionSet, req.Variables); complexity > a.config.GraphQL.MaxComplexity {
+		writeGraphQL(w, &graphql.Response{Errors: []*gqlerrors.QueryError{{
+			Message:    fmt.Sprintf("query complexity %d exceeds the limit of %d", complexity, a.config.GraphQL.MaxComplexity),
+			Extensions: map[string]interface{}{"code": CodeQueryTooComplex},
+		}}})
+		return
+	}
+
+	loader := newCommentLoader(r.Context(), a.comments, a.config.GraphQL.BatchWait, a.config.GraphQL.MaxBatch)
+	ctx := withCommentLoader(r.Context(), loader)
+	writeGraphQL(w, a.graphqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+func writeGraphQL(w http.ResponseWriter, resp *graphql.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// graphqlOperation — выполняемая операция запроса или ошибки с кодом invalid_query, если запрос
+// не проходит проверку схемой или в нем нет операции operationName
+func (a *App) graphqlOperation(req graphqlRequest) (*ast.OperationDefinition, []*gqlerrors.QueryError) {
+	doc, list := a.graphqlDocs.load(req.Query)
+	if len(list) > 0 {
+		errs := make([]*gqlerrors.QueryError, len(list))
+		for i, e := range list {
+			errs[i] = &gqlerrors.QueryError{Message: e.Message, Extensions: map[string]interface{}{"code": CodeInvalidQuery}}
+			for _, loc := range e.Locations {
+				errs[i].Locations = append(errs[i].Locations, gqlerrors.Location{Line: loc.Line, Column: loc.Column})
+			}
+		}
+		return nil, errs
+	}
+	op := doc.Operations.ForName(req.OperationName)
+	if op == nil {
+		message := fmt.Sprintf("no operation named %q", req.OperationName)
+		if req.OperationName == "" {
+			message = "operationName is required for a document with several operations"
+		}
+		return nil, []*gqlerrors.QueryError{{Message: message, Extensions: map[string]interface{}{"code": CodeInvalidQuery}}}
+	}
+	return op, nil
+}
+
+// queryComplexity — оценка сложности выборки: каждое поле стоит 1, а выборка внутри списка
+// умножается на его ожидаемую длину — pageSize для новостей и graphql.list_size для прочих списков
+func (a *App) queryComplexity(set ast.SelectionSet, vars map[string]interface{}) int {
+	total := 0
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			total += 1 + a.listLength(sel, vars)*a.queryComplexity(sel.SelectionSet, vars)
+		case *ast.InlineFragment:
+			total += a.queryComplexity(sel.SelectionSet, vars)
+		case *ast.FragmentSpread:
+			total += a.queryComplexity(sel.Definition.SelectionSet, vars)
+		}
+	}
+	return total
+}
+
+// listLength — ожидаемое число элементов, которое вернет поле
+func (a *App) listLength(field *ast.Field, vars map[string]interface{}) int {
+	if field.Definition == nil || field.Definition.Type.Elem == nil {
+		return 1
+	}
+	if field.Definition.Arguments.ForName("pageSize") == nil {
+		return a.config.GraphQL.ListSize
+	}
+	pageSize := 0
+	switch v := field.ArgumentMap(vars)["pageSize"].(type) {
+	case int64:
+		pageSize = int(v)
+	case float64:
+		pageSize = int(v)
+	}
+	return a.newsPageSize(pageSize)
+}
+
+// graphqlError — ошибка внутреннего сервиса в ответе GraphQL; код, статус и ошибки полей
+// передаются в extensions, как в problem+json
+type graphqlError struct {
+	problem *httpx.Problem
+}
+
+func (e graphqlError) Error() string {
+	return e.problem.Detail
+}
+
+func (e graphqlError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.problem.Code, "status": e.problem.Status}
+	if len(e.problem.Errors) > 0 {
+		ext["errors"] = e.problem.Errors
+	}
+	return ext
+}
+
+// validationError — ошибка валидации аргумента GraphQL
+func validationError(field, code, message string) error {
+	return graphqlError{httpx.ValidationProblem([]httpx.FieldError{{Field: field, Code: code, Message: message}})}
+}
+
+// parseID — положительный целый ID из аргумента GraphQL
+func parseID(id graphql.ID) (int, bool) {
+	n, err := strconv.Atoi(string(id))
+	return n, err == nil && n > 0
+}
+
+// graphqlResolver — корневой резолвер запросов и мутаций
+type graphqlResolver struct {
+	a *App
+}
+
+func (r *graphqlResolver) News(ctx context.Context, args struct {
+	Page     int32
+	PageSize *int32
+	Search   *string
+}) ([]*newsResolver, error) {
+	q := NewsQuery{Page: int(args.Page), PageSize: r.a.config.Limits.DefaultPageSize}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if args.PageSize != nil {
+		q.PageSize = r.a.newsPageSize(int(*args.PageSize))
+	}
+	if args.Search != nil {
+		q.Search = *args.Search
+		if fields := r.a.validateNewsFilter(url.Values{"search": {q.Search}}); len(fields) > 0 {
+			return nil, graphqlError{httpx.ValidationProblem(fields)}
+		}
+	}
+
+	news, _, problem := r.a.news.ListNews(ctx, q)
+	if problem != nil {
+		return nil, graphqlError{problem}
+	}
+	loader := commentLoaderFrom(ctx)
+	result := make([]*newsResolver, len(news))
+	for i, n := range news {
+		result[i] = &newsResolver{n, loader}
+	}
+	return result, nil
+}
+
+func (r *graphqlResolver) NewsItem(ctx context.Context, args struct{ ID graphql.ID }) (*newsResolver, error) {
+	id, ok := parseID(args.ID)
+	if !ok {
+		return nil, validationError("id", httpx.FieldInvalid, "Invalid news ID")
+	}
+	news, problem := r.a.news.GetNews(ctx, id)
+	if problem != nil {
+		if problem.Code == httpx.CodeNotFound {
+			return nil, nil
+		}
+		return nil, graphqlError{problem}
+	}
+	return &newsResolver{*news, commentLoaderFrom(ctx)}, nil
+}
+
+// commentInput — аргумент мутации createComment
+type commentInput struct {
+	NewsID   graphql.ID
+	ParentID *graphql.ID
+	Author   *string
+	Text     string
+	Language *string
+}
+
+func (r *graphqlResolver) CreateComment(ctx context.Context, args struct{ Input commentInput }) (*commentResolver, error) {
+	var comment Comment
+	var ok bool
+	if comment.NewsID, ok = parseID(args.Input.NewsID); !ok {
+		return nil, validationError("newsId", httpx.FieldInvalid, "Invalid news ID")
+	}
+	if args.Input.ParentID != nil {
+		parentID, ok := parseID(*args.Input.ParentID)
+		if !ok {
+			return nil, validationError("parentId", httpx.FieldInvalid, "Invalid parent comment ID")
+		}
+		comment.ParentID = &parentID
+	}
+	if args.Input.Author != nil {
+		comment.Author = *args.Input.Author
+	}
+	comment.Text = args.Input.Text
+	if args.Input.Language != nil {
+		comment.Language = *args.Input.Language
+	}
+
+	created, problem := r.a.createComment(ctx, comment)
+	if problem != nil {
+		return nil, graphqlError{problem}
+	}
+	return &commentResolver{*created}, nil
+}
+
+// newsResolver — новость; комментарии загружаются пакетами через загрузчик запроса
+type newsResolver struct {
+	news   News
+	loader *commentLoader
+}
+
+func (r *newsResolver) ID() graphql.ID     { return graphql.ID(strconv.Itoa(r.news.ID)) }
+func (r *newsResolver) Title() string      { return r.news.Title }
+func (r *newsResolver) Content() string    { return r.news.Content }
+func (r *newsResolver) Date() graphql.Time { return graphql.Time{Time: r.news.Date} }
+func (r *newsResolver) Source() string     { return r.news.Source }
+func (r *newsResolver) Category() string   { return r.news.Category }
+func (r *newsResolver) Tags() []string     { return append([]string{}, r.news.Tags...) }
+
+func (r *newsResolver) loadComments() ([]Comment, error) {
+	comments, problem := r.loader.Load(r.news.ID)
+	if problem != nil {
+		return nil, graphqlError{problem}
+	}
+	return comments, nil
+}
+
+func (r *newsResolver) Comments() ([]*commentResolver, error) {
+	comments, err := r.loadComments()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*commentResolver, len(comments))
+	for i, c := range comments {
+		result[i] = &commentResolver{c}
+	}
+	return result, nil
+}
+
+func (r *newsResolver) Authors() ([]*authorResolver, error) {
+	comments, err := r.loadComments()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	authors := []*authorResolver{}
+	for _, c := range comments {
+		if c.Author != "" && !seen[c.Author] {
+			seen[c.Author] = true
+			authors = append(authors, &authorResolver{c.Author})
+		}
+	}
+	return authors, nil
+}
+
+// commentResolver — комментарий
+type commentResolver struct {
+	comment Comment
+}
+
+func (r *commentResolver) ID() graphql.ID          { return graphql.ID(strconv.Itoa(r.comment.ID)) }
+func (r *commentResolver) NewsID() graphql.ID      { return graphql.ID(strconv.Itoa(r.comment.NewsID)) }
+func (r *commentResolver) Text() string            { return r.comment.Text }
+func (r *commentResolver) HTML() string            { return r.comment.HTML }
+func (r *commentResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.comment.CreatedAt} }
+
+func (r *commentResolver) Status() *string {
+	if r.comment.Status == "" {
+		return nil
+	}
+	return &r.comment.Status
+}
+
+func (r *commentResolver) ParentID() *graphql.ID {
+	if r.comment.ParentID == nil {
+		return nil
+	}
+	id := graphql.ID(strconv.Itoa(*r.comment.ParentID))
+	return &id
+}
+
+func (r *commentResolver) Author() *authorResolver {
+	if r.comment.Author == "" {
+		return nil
+	}
+	return &authorResolver{r.comment.Author}
+}
+
+// authorResolver — автор комментария
+type authorResolver struct {
+	name string
+}
+
+func (r *authorResolver) Name() string { return r.name }
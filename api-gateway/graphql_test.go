@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"pkg/httpx"
+)
+
+// fakeNews — News Aggregator в памяти
+type fakeNews struct {
+	news []News
+}
+
+func (f fakeNews) ListNews(_ context.Context, q NewsQuery) ([]News, int, *httpx.Problem) {
+	if len(f.news) > q.PageSize {
+		return f.news[:q.PageSize], len(f.news), nil
+	}
+	return f.news, len(f.news), nil
+}
+
+func (f fakeNews) GetNews(_ context.Context, id int) (*News, *httpx.Problem) {
+	for _, n := range f.news {
+		if n.ID == id {
+			return &n, nil
+		}
+	}
+	return nil, httpx.NewProblem(http.StatusNotFound, httpx.CodeNotFound, "News not found")
+}
+
+// fakeCensor — Censor Service, отклоняющий слово qwerty
+type fakeCensor struct{}
+
+func (fakeCensor) CheckText(_ context.Context, check CensorCheck) (*CensorVerdict, *httpx.Problem) {
+	if strings.Contains(check.Text, "qwerty") {
+		return nil, httpx.NewProblem(http.StatusBadRequest, httpx.CodeForbiddenWords, "Text contains forbidden words")
+	}
+	return &CensorVerdict{Verdict: VerdictAccept}, nil
+}
+
+func newGraphQLTestApp() (*App, *fakeComments) {
+	app := newTestApp()
+	date := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	app.news = fakeNews{news: []News{
+		{ID: 1, Title: "Новость 1", Date: date, Tags: []string{"elections"}},
+		{ID: 2, Title: "Новость 2", Date: date},
+		{ID: 3, Title: "Новость 3", Date: date},
+	}}
+	comments := &fakeComments{comments: []Comment{
+		{ID: 1, NewsID: 1, Author: "anna", Text: "Первый", CreatedAt: date},
+		{ID: 2, NewsID: 1, Author: "anna", Text: "Второй", CreatedAt: date},
+		{ID: 3, NewsID: 2, Author: "boris", Text: "Третий", CreatedAt: date},
+		{ID: 4, NewsID: 2, Text: "Аноним", CreatedAt: date},
+	}}
+	app.comments = comments
+	app.censor = fakeCensor{}
+	return app, comments
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, app *App, query string, variables map[string]any) graphqlResponse {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"query": query, "variables": variables})
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/graphql", strings.NewReader(string(body))))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp graphqlResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestGraphQLNewsWithComments(t *testing.T) {
+	app, comments := newGraphQLTestApp()
+
+	resp := postGraphQL(t, app, `{
+		news(pageSize: 3) {
+			id title tags
+			comments { id text author { name } }
+			authors { name }
+		}
+	}`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Неожиданные ошибки: %+v", resp.Errors)
+	}
+	var data struct {
+		News []struct {
+			ID       string   `json:"id"`
+			Tags     []string `json:"tags"`
+			Comments []struct {
+				Text   string `json:"text"`
+				Author *struct {
+					Name string `json:"name"`
+				} `json:"author"`
+			} `json:"comments"`
+			Authors []struct {
+				Name string `json:"name"`
+			} `json:"authors"`
+		} `json:"news"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.News) != 3 || len(data.News[0].Comments) != 2 || len(data.News[1].Comments) != 2 || len(data.News[2].Comments) != 0 {
+		t.Fatalf("Неверный ответ: %s", resp.Data)
+	}
+	if len(data.News[0].Authors) != 1 || data.News[0].Authors[0].Name != "anna" || data.News[1].Comments[1].Author != nil {
+		t.Errorf("Неверные авторы: %s", resp.Data)
+	}
+	if data.News[2].Tags == nil {
+		t.Error("Теги новости без тегов должны быть пустым списком")
+	}
+
+	// Комментарии всех новостей страницы запрашиваются одним вызовом
+	if calls := comments.batches(); len(calls) != 1 || len(calls[0]) != 3 {
+		t.Errorf("Ожидался один вызов Comment Service для трех новостей, получено %v", calls)
+	}
+}
+
+func TestGraphQLNewsItem(t *testing.T) {
+	app, _ := newGraphQLTestApp()
+
+	resp := postGraphQL(t, app, `query($id: ID!) { newsItem(id: $id) { title date comments { newsId parentId createdAt } } }`, map[string]any{"id": "2"})
+	var data struct {
+		NewsItem *struct {
+			Title    string `json:"title"`
+			Date     string `json:"date"`
+			Comments []struct {
+				NewsID    string  `json:"newsId"`
+				ParentID  *string `json:"parentId"`
+				CreatedAt string  `json:"createdAt"`
+			} `json:"comments"`
+		} `json:"newsItem"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || len(resp.Errors) > 0 {
+		t.Fatalf("Неверный ответ: %s %+v", resp.Data, resp.Errors)
+	}
+	if data.NewsItem == nil || data.NewsItem.Title != "Новость 2" || data.NewsItem.Date != "2023-01-01T09:00:00Z" ||
+		len(data.NewsItem.Comments) != 2 || data.NewsItem.Comments[0].NewsID != "2" || data.NewsItem.Comments[0].ParentID != nil {
+		t.Errorf("Неверная новость: %s", resp.Data)
+	}
+
+	resp = postGraphQL(t, app, `{ newsItem(id: "42") { title } }`, nil)
+	if string(resp.Data) != `{"newsItem":null}` || len(resp.Errors) > 0 {
+		t.Errorf("Для несуществующей новости ожидался null без ошибок: %s %+v", resp.Data, resp.Errors)
+	}
+
+	resp = postGraphQL(t, app, `{ newsItem(id: "abc") { title } }`, nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != httpx.CodeValidationFailed {
+		t.Errorf("Ожидалась ошибка валидации id: %+v", resp.Errors)
+	}
+}
+
+func TestGraphQLCreateComment(t *testing.T) {
+	app, _ := newGraphQLTestApp()
+	mutation := `mutation($input: CommentInput!) { createComment(input: $input) { id newsId parentId text author { name } } }`
+
+	resp := postGraphQL(t, app, mutation, map[string]any{"input": map[string]any{"newsId": "1", "parentId": "2", "author": "anna", "text": "Ответ"}})
+	var data struct {
+		CreateComment struct {
+			ID       string `json:"id"`
+			ParentID string `json:"parentId"`
+			Author   struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"createComment"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || len(resp.Errors) > 0 {
+		t.Fatalf("Неверный ответ: %s %+v", resp.Data, resp.Errors)
+	}
+	if data.CreateComment.ID != "100" || data.CreateComment.ParentID != "2" || data.CreateComment.Author.Name != "anna" {
+		t.Errorf("Неверный комментарий: %s", resp.Data)
+	}
+
+	resp = postGraphQL(t, app, mutation, map[string]any{"input": map[string]any{"newsId": "1", "text": "qwerty"}})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != httpx.CodeForbiddenWords || resp.Errors[0].Extensions["status"] != float64(http.StatusBadRequest) {
+		t.Errorf("Ожидалась ошибка forbidden_words: %+v", resp.Errors)
+	}
+
+	// Мутации через GET запрещены
+	rr := httptest.NewRecorder()
+	target := "/api/v1/graphql?query=" + url.QueryEscape(`mutation { createComment(input: {newsId: "1", text: "a"}) { id } }`)
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Ожидался статус %d, получен %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	app, comments := newGraphQLTestApp()
+	app.config.GraphQL.MaxDepth = 3
+	app.config.GraphQL.MaxComplexity = 100
+	if err := app.initGraphQL(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := postGraphQL(t, app, `{ news(pageSize: 1) { comments { author { name } } } }`, nil)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "depth") {
+		t.Errorf("Ожидалась ошибка глубины запроса: %+v", resp.Errors)
+	}
+
+	// 1 + 50 × (1 + 10 × 1) = 551 > 100
+	resp = postGraphQL(t, app, `query($size: Int) { news(pageSize: $size) { comments { text } } }`, map[string]any{"size": 50})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != CodeQueryTooComplex {
+		t.Errorf("Ожидалась ошибка сложности запроса: %+v", resp.Errors)
+	}
+	if len(comments.batches()) != 0 {
+		t.Error("Слишком сложный запрос не должен выполняться")
+	}
+
+	// Запрос, который не прошел проверку схемой, отклоняется целиком, а не выполняется без оценки сложности
+	for _, query := range []string{
+		`{ news(pageSize: 50) { comments { text } } unknown }`,
+		`{ news(pageSize: 50) { comments { text } }`,
+		`query a { news { id } } query b { news(pageSize: 50) { comments { text } } }`,
+	} {
+		resp = postGraphQL(t, app, query, nil)
+		if len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != CodeInvalidQuery || string(resp.Data) != "" {
+			t.Errorf("%s: ожидалась ошибка %s, получено %s %+v", query, CodeInvalidQuery, resp.Data, resp.Errors)
+		}
+	}
+	if len(comments.batches()) != 0 {
+		t.Error("Некорректный запрос не должен выполняться")
+	}
+
+	// 1 + 3 × (1 + 10 × 1) = 34
+	resp = postGraphQL(t, app, `fragment c on News { comments { text } } { news(pageSize: 3) { ...c } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Errorf("Запрос в пределах лимитов отклонен: %+v", resp.Errors)
+	}
+}
+
+func TestGraphQLBadRequest(t *testing.T) {
+	app, _ := newGraphQLTestApp()
+
+	tests := []struct {
+		method, target, body string
+		code                 string
+	}{
+		{http.MethodPost, "/api/v1/graphql", `{`, httpx.CodeInvalidBody},
+		{http.MethodPost, "/api/v1/graphql", `{"query":"  "}`, httpx.CodeValidationFailed},
+		{http.MethodGet, "/api/v1/graphql?query=%7Bnews%7Bid%7D%7D&variables=%5B", "", httpx.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+		var p httpx.Problem
+		json.Unmarshal(rr.Body.Bytes(), &p)
+		if rr.Code != http.StatusBadRequest || p.Code != tt.code {
+			t.Errorf("%s %s: ожидалась ошибка %s, получено %d %s", tt.method, tt.target, tt.code, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/graphql?query="+url.QueryEscape(`{ news { title } }`), nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Новость 1") {
+		t.Errorf("Запрос через GET не выполнен: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestGraphQLLegacyRoute(t *testing.T) {
+	app, _ := newGraphQLTestApp()
+
+	query := `{ news(pageSize: 1) { title } }`
+	for _, target := range []string{"/api/v1/graphql", "/graphql"} {
+		body, _ := json.Marshal(map[string]any{"query": query})
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, target, strings.NewReader(string(body))))
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Новость 1") {
+			t.Fatalf("%s: запрос не выполнен: %d %s", target, rr.Code, rr.Body.String())
+		}
+		if deprecated := rr.Header().Get("Deprecation") != ""; deprecated != (target == "/graphql") {
+			t.Errorf("%s: неверный заголовок Deprecation: %q", target, rr.Header().Get("Deprecation"))
+		}
+	}
+	if len(app.graphqlDocs.docs) != 1 {
+		t.Errorf("Повторный запрос должен браться из кэша документов, в кэше %d", len(app.graphqlDocs.docs))
+	}
+}
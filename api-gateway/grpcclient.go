@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/pb/censorpb"
+	"pkg/pb/commentpb"
+	"pkg/pb/newspb"
+)
+
+// errBreakerOpen — вызов отклонен разомкнутым предохранителем без обращения к сервису
+var errBreakerOpen = errors.New("circuit breaker is open")
+
+// dialService — создает соединение gRPC с внутренним сервисом. Каждый вызов проходит
+// через предохранитель сервиса, ограничен services.call_timeout и передает request ID
+// и ключ идемпотентности. Соединение устанавливается лениво, при первом вызове.
+func (a *App) dialService(service, addr string) (*grpc.ClientConn, error) {
+	return grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			grpcx.ClientRequestIDInterceptor,
+			idempotency.UnaryClientInterceptor,
+			breakerInterceptor(a.breakers[service]),
+			deadlineInterceptor(a.config.Services.CallTimeout),
+		),
+	)
+}
+
+// breakerInterceptor — учитывает результат вызова в предохранителе так же, как callService:
+// сбоем считаются недоступность, таймаут и внутренние ошибки сервиса, но не ошибки запроса
+func breakerInterceptor(breaker *CircuitBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if breaker == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !breaker.Allow() {
+			return errBreakerOpen
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			// Отмена запроса клиентом не говорит о состоянии сервиса
+			breaker.Abort()
+		case isServiceFailure(status.Code(err)):
+			breaker.Failure()
+		default:
+			breaker.Success()
+		}
+		return err
+	}
+}
+
+// deadlineInterceptor — ограничивает время одного вызова
+func deadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// isServiceFailure — код gRPC, означающий сбой сервиса, а не ошибку в запросе
+func isServiceFailure(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// grpcProblem — переводит ошибку вызова gRPC в ошибку для клиента по тем же правилам,
+// что и downstreamProblem: ошибки запроса передаются как есть, сбои сервиса — как 502/503/504
+func (a *App) grpcProblem(service string, err error) *httpx.Problem {
+	if errors.Is(err, errBreakerOpen) {
+		return breakerOpenProblem(service)
+	}
+	if p, ok := grpcx.Problem(err); ok {
+		if p.Status >= 500 {
+			return httpx.NewProblem(http.StatusBadGateway, httpx.CodeUpstreamError, fmt.Sprintf("%s responded with status %d", service, p.Status))
+		}
+		return p
+	}
+
+	code := status.Code(err)
+	switch code {
+	case codes.DeadlineExceeded:
+		a.logger.Error().Err(err).Str("service", service).Msg("downstream request failed")
+		return timeoutProblem(service)
+	case codes.Unavailable, codes.Canceled:
+		a.logger.Error().Err(err).Str("service", service).Msg("downstream request failed")
+		return unavailableProblem(service)
+	}
+	if httpStatus := grpcx.HTTPStatus(code); httpStatus < 500 {
+		errCode := httpx.CodeUpstreamError
+		if httpStatus == http.StatusNotFound {
+			errCode = httpx.CodeNotFound
+		}
+		return httpx.NewProblem(httpStatus, errCode, service+" rejected the request")
+	}
+	return httpx.NewProblem(http.StatusBadGateway, httpx.CodeUpstreamError, fmt.Sprintf("%s responded with %s", service, code))
+}
+
+// grpcNews — News Aggregator по gRPC
+type grpcNews struct {
+	a      *App
+	client newspb.NewsServiceClient
+}
+
+func (b grpcNews) ListNews(ctx context.Context, q NewsQuery) ([]News, int, *httpx.Problem) {
+	resp, err := b.client.ListNews(ctx, &newspb.ListNewsRequest{
+		Page:     int32(q.Page),
+		PageSize: int32(q.PageSize),
+		Search:   q.Search,
+		From:     q.From,
+		To:       q.To,
+		Source:   q.Source,
+		Category: q.Category,
+		Sort:     q.Sort,
+	})
+	if err != nil {
+		return nil, 0, b.a.grpcProblem(ServiceNewsAggregator, err)
+	}
+	news := make([]News, 0, len(resp.GetNews()))
+	for _, n := range resp.GetNews() {
+		news = append(news, newsFromProto(n))
+	}
+	return news, int(resp.GetTotal()), nil
+}
+
+func (b grpcNews) GetNews(ctx context.Context, id int) (*News, *httpx.Problem) {
+	resp, err := b.client.GetNews(ctx, &newspb.GetNewsRequest{Id: int64(id)})
+	if err != nil {
+		return nil, b.a.grpcProblem(ServiceNewsAggregator, err)
+	}
+	news := newsFromProto(resp)
+	return &news, nil
+}
+
+// grpcComments — Comment Service по gRPC
+type grpcComments struct {
+	a      *App
+	client commentpb.CommentServiceClient
+}
+
+func (b grpcComments) ListComments(ctx context.Context, newsIDs ...int) ([]Comment, *httpx.Problem) {
+	req := &commentpb.ListCommentsRequest{}
+	for _, id := range newsIDs {
+		req.NewsIds = append(req.NewsIds, int64(id))
+	}
+	resp, err := b.client.ListComments(ctx, req)
+	if err != nil {
+		return nil, b.a.grpcProblem(ServiceComments, err)
+	}
+	return commentsFromProto(resp.GetComments()), nil
+}
+
+func (b grpcComments) CountComments(ctx context.Context, newsIDs ...int) (map[int]int, *httpx.Problem) {
+	req := &commentpb.CountCommentsRequest{}
+	for _, id := range newsIDs {
+		req.NewsIds = append(req.NewsIds, int64(id))
+	}
+	resp, err := b.client.CountComments(ctx, req)
+	if err != nil {
+		return nil, b.a.grpcProblem(ServiceComments, err)
+	}
+	counts := make(map[int]int, len(resp.GetCounts()))
+	for id, n := range resp.GetCounts() {
+		counts[int(id)] = int(n)
+	}
+	return counts, nil
+}
+
+func (b grpcComments) CreateComment(ctx context.Context, comment Comment) (*Comment, *httpx.Problem) {
+	req := &commentpb.CreateCommentRequest{
+		NewsId:       int64(comment.NewsID),
+		Author:       comment.Author,
+		Text:         comment.Text,
+		Status:       comment.Status,
+		OriginalText: comment.OriginalText,
+		Language:     comment.Language,
+		Policy:       comment.Policy,
+	}
+	if comment.ParentID != nil {
+		parentID := int64(*comment.ParentID)
+		req.ParentId = &parentID
+	}
+	resp, err := b.client.CreateComment(ctx, req)
+	if err != nil {
+		return nil, b.a.grpcProblem(ServiceComments, err)
+	}
+	created := commentFromProto(resp)
+	return &created, nil
+}
+
+func (b grpcComments) SearchComments(ctx context.Context, q CommentSearch) ([]Comment, *httpx.Problem) {
+	resp, err := b.client.SearchComments(ctx, &commentpb.SearchCommentsRequest{
+		Q:        q.Q,
+		NewsId:   int64(q.NewsID),
+		Author:   q.Author,
+		Status:   q.Status,
+		From:     q.From,
+		To:       q.To,
+		Page:     int32(q.Page),
+		PageSize: int32(q.PageSize),
+	})
+	if err != nil {
+		return nil, b.a.grpcProblem(ServiceComments, err)
+	}
+	return commentsFromProto(resp.GetComments()), nil
+}
+
+func (b grpcComments) SetCommentStatus(ctx context.Context, id int, status string) (*Comment, string, *httpx.Problem) {
+	resp, err := b.client.SetCommentStatus(ctx, &commentpb.SetCommentStatusRequest{Id: int64(id), Status: status})
+	if err != nil {
+		return nil, "", b.a.grpcProblem(ServiceComments, err)
+	}
+	comment := commentFromProto(resp)
+	return &comment, resp.GetPreviousStatus(), nil
+}
+
+// grpcCensor — Censor Service по gRPC
+type grpcCensor struct {
+	a      *App
+	client censorpb.CensorServiceClient
+}
+
+func (b grpcCensor) CheckText(ctx context.Context, check CensorCheck) (*CensorVerdict, *httpx.Problem) {
+	resp, err := b.client.CheckText(ctx, &censorpb.CheckTextRequest{Text: check.Text, Author: check.Author, Language: check.Language, Policy: check.Policy})
+	if err != nil {
+		return nil, b.a.grpcProblem(ServiceCensor, err)
+	}
+	verdict := &CensorVerdict{Verdict: resp.GetVerdict(), SpamScore: resp.GetSpamScore(), Language: resp.GetLanguage(), Policy: resp.GetPolicy()}
+	for _, s := range resp.GetSignals() {
+		verdict.Signals = append(verdict.Signals, SpamSignal{Rule: s.GetRule(), Score: s.GetScore(), Detail: s.GetDetail()})
+	}
+	for _, sp := range resp.GetMask() {
+		verdict.Mask = append(verdict.Mask, TextSpan{Start: int(sp.GetStart()), End: int(sp.GetEnd())})
+	}
+	return verdict, nil
+}
+
+func newsFromProto(n *newspb.News) News {
+	news := News{
+		ID:       int(n.GetId()),
+		Title:    n.GetTitle(),
+		Content:  n.GetContent(),
+		Date:     timeFromProto(n.GetDate()),
+		Source:   n.GetSource(),
+		Category: n.GetCategory(),
+		Tags:     n.GetTags(),
+		Score:    n.GetScore(),
+	}
+	if h := n.GetHighlight(); h != nil {
+		news.Highlight = &Highlight{Title: h.GetTitle(), Content: h.GetContent()}
+	}
+	return news
+}
+
+func commentFromProto(c *commentpb.Comment) Comment {
+	comment := Comment{
+		ID:           int(c.GetId()),
+		NewsID:       int(c.GetNewsId()),
+		Author:       c.GetAuthor(),
+		Text:         c.GetText(),
+		Status:       c.GetStatus(),
+		CreatedAt:    timeFromProto(c.GetCreatedAt()),
+		HTML:         c.GetHtml(),
+		OriginalText: c.GetOriginalText(),
+	}
+	if c.ParentId != nil {
+		parentID := int(c.GetParentId())
+		comment.ParentID = &parentID
+	}
+	return comment
+}
+
+// commentsFromProto — пустой список остается nil, как и в ответе Comment Service по HTTP
+func commentsFromProto(list []*commentpb.Comment) []Comment {
+	var comments []Comment
+	for _, c := range list {
+		comments = append(comments, commentFromProto(c))
+	}
+	return comments
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/pb/censorpb"
+	"pkg/pb/commentpb"
+	"pkg/pb/newspb"
+)
+
+type fakeNewsServer struct {
+	newspb.UnimplementedNewsServiceServer
+	lastList *newspb.ListNewsRequest
+}
+
+func (s *fakeNewsServer) ListNews(ctx context.Context, req *newspb.ListNewsRequest) (*newspb.ListNewsResponse, error) {
+	s.lastList = req
+	return &newspb.ListNewsResponse{Total: 7}, nil
+}
+
+func (s *fakeNewsServer) GetNews(ctx context.Context, req *newspb.GetNewsRequest) (*newspb.News, error) {
+	if req.GetId() != 1 {
+		return nil, grpcx.Error(httpx.NewProblem(http.StatusNotFound, httpx.CodeNotFound, "News not found"))
+	}
+	return &newspb.News{
+		Id:        1,
+		Title:     "Новость 1",
+		Date:      timestamppb.New(time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)),
+		Category:  "politics",
+		Tags:      []string{"elections"},
+		Highlight: &newspb.Highlight{Title: "<mark>Новость</mark> 1"},
+	}, nil
+}
+
+type fakeCommentServer struct {
+	commentpb.UnimplementedCommentServiceServer
+	delay   time.Duration
+	lastKey string
+	// lastScope — язык и политика проверки последнего созданного комментария
+	lastScope [2]string
+}
+
+func (s *fakeCommentServer) ListComments(ctx context.Context, req *commentpb.ListCommentsRequest) (*commentpb.ListCommentsResponse, error) {
+	return &commentpb.ListCommentsResponse{Comments: []*commentpb.Comment{{Id: 5, NewsId: req.GetNewsIds()[0], Text: "Комментарий"}}}, nil
+}
+
+func (s *fakeCommentServer) CountComments(ctx context.Context, req *commentpb.CountCommentsRequest) (*commentpb.CountCommentsResponse, error) {
+	counts := make(map[int64]int32)
+	for _, id := range req.GetNewsIds() {
+		counts[id] = int32(id)
+	}
+	return &commentpb.CountCommentsResponse{Counts: counts}, nil
+}
+
+func (s *fakeCommentServer) CreateComment(ctx context.Context, req *commentpb.CreateCommentRequest) (*commentpb.Comment, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(idempotency.Metadata)) > 0 {
+		s.lastKey = md.Get(idempotency.Metadata)[0]
+	}
+	s.lastScope = [2]string{req.GetLanguage(), req.GetPolicy()}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if len(req.GetText()) > 10 {
+		return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{{Field: "text", Code: httpx.FieldTooLong, Message: "Text too long"}}))
+	}
+	return &commentpb.Comment{Id: 7, NewsId: req.GetNewsId(), ParentId: req.ParentId, Text: req.GetText(), CreatedAt: timestamppb.Now()}, nil
+}
+
+type fakeCensorServer struct {
+	censorpb.UnimplementedCensorServiceServer
+}
+
+func (s *fakeCensorServer) CheckText(ctx context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error) {
+	if strings.Contains(req.GetText(), "qwerty") {
+		return nil, grpcx.Error(httpx.NewProblem(http.StatusBadRequest, httpx.CodeForbiddenWords, "Text contains forbidden words"))
+	}
+	// Язык и политика проверки возвращаются такими, какими их передал шлюз
+	return &censorpb.CheckTextResponse{Language: req.GetLanguage(), Policy: req.GetPolicy()}, nil
+}
+
+// serveGRPC — запускает gRPC-сервер на свободном порту и возвращает его адрес
+func serveGRPC(t *testing.T, register func(*grpc.Server)) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpcx.NewServer(zerolog.Nop())
+	register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+// newGRPCTestApp — шлюз, вызывающий заглушки внутренних сервисов по gRPC
+func newGRPCTestApp(t *testing.T, news *fakeNewsServer, comments *fakeCommentServer) *App {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Services.Transport = TransportGRPC
+	cfg.Services.CallTimeout = 200 * time.Millisecond
+	cfg.Services.NewsAggregatorGRPC = serveGRPC(t, func(s *grpc.Server) { newspb.RegisterNewsServiceServer(s, news) })
+	cfg.Services.CommentServiceGRPC = serveGRPC(t, func(s *grpc.Server) { commentpb.RegisterCommentServiceServer(s, comments) })
+	cfg.Services.CensorServiceGRPC = serveGRPC(t, func(s *grpc.Server) { censorpb.RegisterCensorServiceServer(s, &fakeCensorServer{}) })
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	app := NewApp(cfg)
+	t.Cleanup(func() { app.closeBackends() })
+	return app
+}
+
+func TestGRPCTransport(t *testing.T) {
+	news := &fakeNewsServer{}
+	app := newGRPCTestApp(t, news, &fakeCommentServer{})
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news/1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data struct {
+			News     News      `json:"news"`
+			Comments []Comment `json:"comments"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.News.Title != "Новость 1" || resp.Data.News.Highlight == nil || len(resp.Data.Comments) != 1 || resp.Data.Comments[0].NewsID != 1 {
+		t.Errorf("Неверный ответ: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news?search=выборы&source=ria&page_size=5", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"data":[]`) {
+		t.Errorf("Пустой список новостей должен отдаваться как [], получено %d %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `"total":7,"page_count":2`) {
+		t.Errorf("Пагинация должна строиться по total из News Aggregator: %s", rr.Body.String())
+	}
+	if news.lastList.GetSearch() != "выборы" || news.lastList.GetSource() != "ria" || news.lastList.GetPageSize() != 5 || news.lastList.GetPage() != 1 {
+		t.Errorf("Неверные параметры вызова ListNews: %v", news.lastList)
+	}
+
+	counts, problem := app.comments.CountComments(context.Background(), 1, 2)
+	if problem != nil || len(counts) != 2 || counts[2] != 2 {
+		t.Errorf("Неверный результат CountComments: %v %v", counts, problem)
+	}
+}
+
+func TestGRPCTransportErrors(t *testing.T) {
+	comments := &fakeCommentServer{}
+	app := newGRPCTestApp(t, &fakeNewsServer{}, comments)
+
+	cases := []struct {
+		method, path, body string
+		wantStatus         int
+		wantCode           string
+		wantFields         int
+	}{
+		{"GET", "/api/v1/news/2", "", http.StatusNotFound, httpx.CodeNotFound, 0},
+		{"POST", "/api/v1/comment", `{"news_id":1,"text":"qwerty"}`, http.StatusBadRequest, httpx.CodeForbiddenWords, 0},
+		{"POST", "/api/v1/comment", `{"news_id":1,"text":"слишком длинный"}`, http.StatusBadRequest, httpx.CodeValidationFailed, 1},
+	}
+	for _, c := range cases {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+		p := decodeProblem(t, rr)
+		if rr.Code != c.wantStatus || p.Code != c.wantCode || len(p.Errors) != c.wantFields {
+			t.Errorf("%s %s: получено %d %+v", c.method, c.path, rr.Code, p)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"ок"}`))
+	req.Header.Set(idempotency.Header, "key-1")
+	app.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if comments.lastKey != "key-1" {
+		t.Errorf("Ключ идемпотентности должен передаваться в метаданных, получено %q", comments.lastKey)
+	}
+	if state := app.breakers[ServiceComments].Status().State; state != BreakerClosed {
+		t.Errorf("Ошибки запроса не должны размыкать предохранитель, состояние %s", state)
+	}
+}
+
+func TestGRPCTransportDeadline(t *testing.T) {
+	app := newGRPCTestApp(t, &fakeNewsServer{}, &fakeCommentServer{delay: time.Second})
+	threshold := app.config.Breaker.FailureThreshold
+
+	for i := 0; i < threshold+1; i++ {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"ок"}`)))
+		p := decodeProblem(t, rr)
+		want, wantCode := http.StatusGatewayTimeout, httpx.CodeUpstreamTimeout
+		if i >= threshold {
+			want, wantCode = http.StatusServiceUnavailable, httpx.CodeUpstreamUnavailable
+		}
+		if rr.Code != want || p.Code != wantCode {
+			t.Errorf("Запрос %d: ожидалось %d %s, получено %d %s", i+1, want, wantCode, rr.Code, p.Code)
+		}
+	}
+}
+
+func TestGRPCCensorScope(t *testing.T) {
+	comments := &fakeCommentServer{}
+	app := newGRPCTestApp(t, &fakeNewsServer{}, comments)
+	app.config.CensorPolicies = map[string]string{"politics": "strict"}
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"ок","language":"en"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if comments.lastScope != [2]string{"en", "strict"} {
+		t.Errorf("Censor Service должен проверять комментарий на языке клиента по политике раздела новости, получено %v", comments.lastScope)
+	}
+}
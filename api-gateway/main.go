@@ -3,66 +3,83 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/graph-gophers/graphql-go"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/hlog"
+	"google.golang.org/grpc"
+
+	"pkg/config"
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/pb/censorpb"
+	"pkg/pb/commentpb"
+	"pkg/pb/newspb"
+	"pkg/server"
+	"pkg/textx"
 )
 
-// NewsAggregatorURL — URL внешнего сервиса новостей
-var NewsAggregatorURL = getEnv("NEWS_AGGREGATOR_URL", "http://news-aggregator:8083")
-
-// CommentServiceURL — URL сервиса комментариев
-var CommentServiceURL = getEnv("COMMENT_SERVICE_URL", "http://comment-service:8081")
-
-// CensorServiceURL — URL сервиса цензуры
-var CensorServiceURL = getEnv("CENSOR_SERVICE_URL", "http://censor-service:8082")
-
-// Config — конфигурация приложения
-type Config struct {
-	Port string
-}
-
 // App — структура приложения
 type App struct {
-	config Config
-	logger zerolog.Logger
-	router chi.Router
+	config   Config
+	logger   zerolog.Logger
+	router   chi.Router
+	health   *server.Health
+	breakers map[string]*CircuitBreaker
+	webhooks *WebhookDispatcher
+	// HTTP-клиент вызовов и проверок внутренних сервисов
+	client   *http.Client
+	versions []APIVersion
+
+	// Ответы на POST /comment с Idempotency-Key; Comment Service дополнительно хранит свои
+	idempotency idempotency.Store
+	// Новости, к которым можно оставлять комментарии
+	newsCache *newsCache
+
+	// Клиенты внутренних сервисов; протокол выбирается параметром services.transport
+	news     NewsBackend
+	comments CommentBackend
+	censor   CensorBackend
+	conns    []*grpc.ClientConn
+
+	// Схема /api/v1/graphql и проверенные документы запросов для оценки их сложности
+	graphqlSchema *graphql.Schema
+	graphqlDocs   *graphqlDocCache
 }
 
-// Response — универсальная структура ответа
-type Response struct {
-	Status     string      `json:"status"`
-	Data       interface{} `json:"data,omitempty"`
-	Error      string      `json:"error,omitempty"`
-	Pagination *Pagination `json:"pagination,omitempty"`
+// News — структура новости
+type News struct {
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Date      time.Time  `json:"date"`
+	Source    string     `json:"source"`
+	Category  string     `json:"category"`
+	Tags      []string   `json:"tags,omitempty"`
+	Score     float64    `json:"score,omitempty"`
+	Highlight *Highlight `json:"highlight,omitempty"`
+	// CommentCount — заполняется только по запросу include=comment_count
+	CommentCount *int `json:"comment_count,omitempty"`
 }
 
-// Pagination — структура пагинации
-type Pagination struct {
-	Page      int `json:"page"`
-	PageSize  int `json:"page_size"`
-	Total     int `json:"total"`
-	PageCount int `json:"page_count"`
-}
+// Статусы комментария
+const (
+	CommentPublished = "published"
+	CommentPending   = "pending"
+)
 
-// News — структура новости
-type News struct {
-	ID      int    `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Date    string `json:"date"`
+// Highlight — фрагменты новости с выделенными совпадениями поиска
+type Highlight struct {
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
 }
 
 // Comment — структура комментария
@@ -70,31 +87,20 @@ type Comment struct {
 	ID       int    `json:"id"`
 	NewsID   int    `json:"news_id"`
 	ParentID *int   `json:"parent_id,omitempty"`
+	Author   string `json:"author,omitempty"`
 	Text     string `json:"text"`
+	// Status — published или pending (ожидает модерации); клиент его не задает
+	Status    string    `json:"status,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-}
-
-// RequestIDMiddleware — мидлвар для генерации/пропуска request_id
-func RequestIDMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-		ctx := context.WithValue(r.Context(), "request_id", requestID)
-		w.Header().Set("X-Request-ID", requestID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
-
-// generateRequestID — генерирует уникальный request_id
-func generateRequestID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
-}
-
-// LoggerMiddleware — мидлвар для логирования запросов
-func LoggerMiddleware(logger *zerolog.Logger) func(http.Handler) http.Handler {
-	return hlog.NewHandler(*logger)
+	// HTML — текст, размеченный безопасным подмножеством Markdown (строит Comment Service)
+	HTML string `json:"html,omitempty"`
+	// OriginalText — текст до маскировки запрещенных слов; возвращается только модераторам
+	OriginalText string `json:"original_text,omitempty"`
+	// Language и Policy — язык и политика проверки в Censor Service; передаются в Comment Service
+	// для повторной проверки комментария и клиентам не возвращаются. Язык может задать клиент
+	// при создании комментария, политика выбирается по разделу новости.
+	Language string `json:"-"`
+	Policy   string `json:"-"`
 }
 
 // TimeoutMiddleware — мидлвар для установки таймаута
@@ -108,46 +114,76 @@ func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
 	}
 }
 
-// getEnv — получает значение переменной окружения или возвращает значение по умолчанию
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// internalHeaders — заголовки, которым доверяют внутренние сервисы: idempotency.HashHeader — хеш исходного
+// запроса, по которому сравниваются повторы. Клиенты шлюза их не задают.
+var internalHeaders = []string{idempotency.HashHeader}
+
+// StripInternalHeaders — удаляет из запросов клиентов заголовки внутренних сервисов, чтобы они не дошли
+// до обработчиков и внутренних сервисов. Внутренние API (например, /rules Censor Service) шлюз не публикует.
+func StripInternalHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range internalHeaders {
+			r.Header.Del(name)
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // NewApp — создает новое приложение
 func NewApp(config Config) *App {
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-
-	r := chi.NewRouter()
-
-	// Middleware
-	r.Use(middleware.Recoverer)
-	r.Use(RequestIDMiddleware)
-	r.Use(LoggerMiddleware(&logger))
-	r.Use(TimeoutMiddleware(30 * time.Second))
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
-		MaxAge:           300,
-	}))
+	logger := server.NewLogger("api-gateway")
+
+	health := server.NewHealth()
+	r := server.NewRouter(logger, health,
+		TimeoutMiddleware(config.RequestTimeout),
+		StripInternalHeaders,
+		cors.Handler(cors.Options{
+			AllowedOrigins:   []string{"https://*", "http://*"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID", idempotency.Header},
+			ExposedHeaders:   []string{"Link", "Deprecation", "Sunset", idempotency.ReplayedHeader},
+			AllowCredentials: false,
+			MaxAge:           300,
+		}),
+	)
 
 	app := &App{
-		config: config,
-		logger: logger,
-		router: r,
+		config:   config,
+		logger:   logger,
+		router:   r,
+		health:   health,
+		breakers: newBreakers(config.Breaker),
+		webhooks: NewWebhookDispatcher(logger),
+		client:   newServiceClient(config.Services.CallTimeout),
+
+		idempotency: idempotency.NewMemoryStore(config.IdempotencyTTL),
+		newsCache:   newNewsCache(config.NewsCacheTTL),
+	}
+	if err := app.initBackends(); err != nil {
+		log.Fatal(err)
+	}
+	if err := app.initGraphQL(); err != nil {
+		log.Fatal(err)
 	}
 
 	// Routes
 	r.Get("/", app.Home)
-	r.Get("/health", app.HealthCheck)
-	r.Get("/news", app.GetNews)
-	r.Get("/news/{id}", app.GetNewsByID)
-	r.Post("/comment", app.CreateComment)
+	r.Get("/openapi.json", app.OpenAPISpec)
+	r.Get("/docs", app.SwaggerUI)
+	// Сводка раскрывает адреса внутренних сервисов и тексты их ошибок, поэтому она только для модераторов
+	r.With(app.ModeratorOnly).Get("/health/deps", app.HealthDeps)
+	r.With(app.ModeratorOnly).Get("/health/status", app.StatusPage)
+
+	// Готовность шлюза зависит от доступности внутренних сервисов
+	health.AddCheck(ServiceNewsAggregator, downstreamCheck(app.client, func() string { return app.config.Services.NewsAggregatorURL }))
+	health.AddCheck(ServiceComments, downstreamCheck(app.client, func() string { return app.config.Services.CommentServiceURL }))
+	health.AddCheck(ServiceCensor, downstreamCheck(app.client, func() string { return app.config.Services.CensorServiceURL }))
+
+	// Версии API
+	app.versions = []APIVersion{
+		{Name: "v1", Routes: app.routesV1},
+	}
+	app.mountVersions(r)
 
 	return app
 }
@@ -159,12 +195,6 @@ func (a *App) Home(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "API Gateway OK")
 }
 
-// HealthCheck — проверка состояния сервиса
-func (a *App) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{Status: "ok"})
-}
-
 // GetNews — получение списка новостей с пагинацией и поиском
 func (a *App) GetNews(w http.ResponseWriter, r *http.Request) {
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
@@ -172,61 +202,111 @@ func (a *App) GetNews(w http.ResponseWriter, r *http.Request) {
 		page = 1
 	}
 	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
-	}
-	search := r.URL.Query().Get("search")
+	pageSize = a.newsPageSize(pageSize)
+	q := r.URL.Query()
 
 	// Валидация параметров
-	if len(search) > 100 {
-		a.sendError(w, http.StatusBadRequest, "Search query too long")
+	if fields := a.validateNewsFilter(q); len(fields) > 0 {
+		httpx.SendValidationError(w, r, fields...)
 		return
 	}
 
-	// Формирование URL для запроса к News Aggregator
-	u, err := url.Parse(NewsAggregatorURL + "/news")
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to parse news aggregator URL")
+	news, total, problem := a.news.ListNews(r.Context(), NewsQuery{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   q.Get("search"),
+		From:     q.Get("from"),
+		To:       q.Get("to"),
+		Source:   q.Get("source"),
+		Category: q.Get("category"),
+		Sort:     q.Get("sort"),
+	})
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
 		return
 	}
-	q := u.Query()
-	q.Set("page", strconv.Itoa(page))
-	q.Set("page_size", strconv.Itoa(pageSize))
-	if search != "" {
-		q.Set("search", search)
+	if q.Get("include") == IncludeCommentCount {
+		if problem := a.addCommentCounts(r.Context(), news); problem != nil {
+			httpx.SendProblem(w, r, problem)
+			return
+		}
 	}
-	u.RawQuery = q.Encode()
 
-	resp, err := http.Get(u.String())
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to fetch news")
-		return
-	}
-	defer resp.Body.Close()
+	httpx.SendPage(w, http.StatusOK, news, httpx.NewPagination(page, pageSize, total))
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to read news response")
-		return
-	}
+// IncludeCommentCount — значение параметра include, добавляющее к новостям число комментариев
+const IncludeCommentCount = "comment_count"
 
-	if resp.StatusCode != http.StatusOK {
-		a.sendError(w, resp.StatusCode, string(body))
-		return
+// addCommentCounts — заполняет CommentCount новостей одним вызовом Comment Service
+func (a *App) addCommentCounts(ctx context.Context, news []News) *httpx.Problem {
+	if len(news) == 0 {
+		return nil
+	}
+	ids := make([]int, len(news))
+	for i, n := range news {
+		ids[i] = n.ID
 	}
+	counts, problem := a.comments.CountComments(ctx, ids...)
+	if problem != nil {
+		return problem
+	}
+	for i := range news {
+		count := counts[news[i].ID]
+		news[i].CommentCount = &count
+	}
+	return nil
+}
 
-	var newsResponse Response
-	if err := json.Unmarshal(body, &newsResponse); err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to parse news response")
-		return
+// newsPageSize — размер страницы новостей; значения вне допустимого диапазона заменяются размером по умолчанию
+func (a *App) newsPageSize(pageSize int) int {
+	if pageSize < 1 || pageSize > a.config.Limits.MaxPageSize {
+		return a.config.Limits.DefaultPageSize
 	}
+	return pageSize
+}
 
-	a.sendResponse(w, http.StatusOK, newsResponse.Data, &Pagination{
-		Page:     page,
-		PageSize: pageSize,
-		Total:    100, // В реальном приложении это должно приходить из News Aggregator
-		PageCount: 10, // В реальном приложении это должно приходить из News Aggregator
-	})
+// validateNewsFilter — проверяет параметры поиска и фильтрации списка новостей
+func (a *App) validateNewsFilter(q url.Values) []httpx.FieldError {
+	var fields []httpx.FieldError
+	if textx.Length(q.Get("search")) > a.config.Limits.MaxSearchLength {
+		fields = append(fields, httpx.FieldError{Field: "search", Code: httpx.FieldTooLong, Message: "Search query too long"})
+	}
+	var from, to time.Time
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = httpx.ParseDate(v, false); err != nil {
+			fields = append(fields, httpx.FieldError{Field: "from", Code: httpx.FieldInvalid, Message: "Expected RFC3339 or YYYY-MM-DD"})
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = httpx.ParseDate(v, true); err != nil {
+			fields = append(fields, httpx.FieldError{Field: "to", Code: httpx.FieldInvalid, Message: "Expected RFC3339 or YYYY-MM-DD"})
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		fields = append(fields, httpx.FieldError{Field: "from", Code: httpx.FieldInvalid, Message: "from must not be after to"})
+	}
+	for _, name := range []string{"source", "category"} {
+		if textx.Length(q.Get(name)) > a.config.Limits.MaxSearchLength {
+			fields = append(fields, httpx.FieldError{Field: name, Code: httpx.FieldTooLong, Message: "Filter value too long"})
+		}
+	}
+	switch q.Get("include") {
+	case "", IncludeCommentCount:
+	default:
+		fields = append(fields, httpx.FieldError{Field: "include", Code: httpx.FieldUnknown, Message: "Expected comment_count"})
+	}
+	switch q.Get("sort") {
+	case "", "date_desc", "date_asc":
+	case "relevance":
+		if q.Get("search") == "" {
+			fields = append(fields, httpx.FieldError{Field: "sort", Code: httpx.FieldInvalid, Message: "sort=relevance requires search"})
+		}
+	default:
+		fields = append(fields, httpx.FieldError{Field: "sort", Code: httpx.FieldUnknown, Message: "Expected date_desc, date_asc or relevance"})
+	}
+	return fields
 }
 
 // GetNewsByID — получение новости по ID с комментариями
@@ -234,170 +314,178 @@ func (a *App) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	newsID, err := strconv.Atoi(id)
 	if err != nil || newsID < 1 {
-		a.sendError(w, http.StatusBadRequest, "Invalid news ID")
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid news ID"})
 		return
 	}
 
 	// Запрос деталей новости
-	newsURL := fmt.Sprintf("%s/news/%d", NewsAggregatorURL, newsID)
-	newsResp, err := http.Get(newsURL)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to fetch news details")
-		return
-	}
-	defer newsResp.Body.Close()
-
-	newsBody, err := io.ReadAll(newsResp.Body)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to read news details")
-		return
-	}
-
-	if newsResp.StatusCode != http.StatusOK {
-		a.sendError(w, newsResp.StatusCode, string(newsBody))
-		return
-	}
-
-	var newsResponse Response
-	if err := json.Unmarshal(newsBody, &newsResponse); err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to parse news details")
+	news, problem := a.news.GetNews(r.Context(), newsID)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
 		return
 	}
 
 	// Запрос комментариев
-	commentsURL := fmt.Sprintf("%s/comments?news_id=%d", CommentServiceURL, newsID)
-	commentsResp, err := http.Get(commentsURL)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to fetch comments")
-		return
-	}
-	defer commentsResp.Body.Close()
-
-	commentsBody, err := io.ReadAll(commentsResp.Body)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to read comments")
-		return
-	}
-
-	if commentsResp.StatusCode != http.StatusOK {
-		a.sendError(w, commentsResp.StatusCode, string(commentsBody))
-		return
-	}
-
-	var commentsResponse Response
-	if err := json.Unmarshal(commentsBody, &commentsResponse); err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to parse comments")
+	comments, problem := a.comments.ListComments(r.Context(), newsID)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
 		return
 	}
 
 	// Агрегация результатов
 	result := map[string]interface{}{
-		"news":      newsResponse.Data,
-		"comments":  commentsResponse.Data,
+		"news":     news,
+		"comments": comments,
 	}
 
-	a.sendResponse(w, http.StatusOK, result, nil)
+	httpx.SendResponse(w, http.StatusOK, result)
 }
 
 // CreateComment — создание комментария
 func (a *App) CreateComment(w http.ResponseWriter, r *http.Request) {
-	var comment Comment
-	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-		a.sendError(w, http.StatusBadRequest, "Invalid request body")
-		return
+	var req struct {
+		Comment
+		// Language — язык комментария, если его знает клиент
+		Language string `json:"language"`
 	}
-
-	// Проверка текста на наличие запрещённых слов
-	censorURL := CensorServiceURL + "/check"
-	censorPayload := map[string]string{"text": comment.Text}
-	censorBody, err := json.Marshal(censorPayload)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to marshal censor request")
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
 		return
 	}
+	comment := req.Comment
+	comment.Language = req.Language
 
-	resp, err := http.Post(censorURL, "application/json", strings.NewReader(string(censorBody)))
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to check comment for censorship")
+	created, problem := a.createComment(r.Context(), comment)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
 		return
 	}
-	resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		a.sendError(w, http.StatusBadRequest, "Comment contains forbidden words")
-		return
-	}
+	httpx.SendResponse(w, http.StatusOK, created)
+}
 
-	// Отправка комментария в Comment Service
-	commentsURL := CommentServiceURL + "/comments"
-	commentsBody, err := json.Marshal(comment)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to marshal comment")
-		return
+// createComment — проверяет существование новости и текст в Censor Service, сохраняет комментарий и оповещает вебхуки;
+// общая часть REST и GraphQL API. По оценке спама комментарий публикуется, отправляется на модерацию
+// или отклоняется. Слова, совпавшие с правилами словаря с действием mask, скрываются, а исходный текст
+// сохраняется для модераторов. Текст нормализуется до проверки, чтобы управляющие символы, символы нулевой
+// ширины или разложенные буквы (NFD) внутри запрещенного слова не скрыли его от Censor Service.
+// Текст проверяется на языке comment.Language (пустой определяет Censor Service) по политике раздела
+// новости из censor_policies.
+func (a *App) createComment(ctx context.Context, comment Comment) (*Comment, *httpx.Problem) {
+	comment.Text = textx.Normalize(comment.Text)
+	comment.Author = textx.Normalize(comment.Author)
+	comment.OriginalText = ""
+	category, problem := a.checkNewsExists(ctx, comment.NewsID)
+	if problem != nil {
+		return nil, problem
+	}
+
+	// Проверка текста на запрещённые слова и спам
+	verdict, problem := a.censor.CheckText(ctx, CensorCheck{
+		Text:     comment.Text,
+		Author:   comment.Author,
+		Language: comment.Language,
+		Policy:   a.config.CensorPolicies[category],
+	})
+	if problem != nil {
+		if problem.Code == httpx.CodeForbiddenWords {
+			a.webhooks.Dispatch(EventCommentRejected, comment.NewsID, rejectedComment(comment, problem.Code))
+		}
+		return nil, problem
 	}
-
-	resp, err = http.Post(commentsURL, "application/json", strings.NewReader(string(commentsBody)))
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to create comment")
-		return
+	switch verdict.Verdict {
+	case VerdictReject:
+		a.webhooks.Dispatch(EventCommentRejected, comment.NewsID, rejectedComment(comment, httpx.CodeSpamDetected))
+		return nil, httpx.NewProblem(http.StatusBadRequest, httpx.CodeSpamDetected, "Comment looks like spam")
+	case VerdictQueue:
+		comment.Status = CommentPending
+	default:
+		comment.Status = CommentPublished
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to read comment response")
-		return
+	if len(verdict.Mask) > 0 {
+		comment.OriginalText = comment.Text
+		comment.Text = maskText(comment.Text, verdict.Mask)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		a.sendError(w, resp.StatusCode, string(body))
-		return
+	// Отправка комментария в Comment Service
+	comment.Language, comment.Policy = verdict.Language, verdict.Policy
+	created, problem := a.comments.CreateComment(ctx, comment)
+	if problem != nil {
+		return nil, problem
 	}
+	created = publicComment(created)
 
-	var commentResponse Response
-	if err := json.Unmarshal(body, &commentResponse); err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to parse comment response")
-		return
+	// Комментарий на модерации оповещает вебхуки после публикации модератором
+	if created.Status != CommentPending {
+		a.webhooks.Dispatch(EventCommentCreated, comment.NewsID, created)
 	}
-
-	a.sendResponse(w, http.StatusOK, commentResponse.Data, nil)
+	return created, nil
 }
 
-// sendResponse — отправляет успешный JSON-ответ
-func (a *App) sendResponse(w http.ResponseWriter, statusCode int, data interface{}, pagination *Pagination) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(Response{
-		Status:     "success",
-		Data:       data,
-		Pagination: pagination,
-	})
+// Run — запускает HTTP-сервер
+func (a *App) Run() error {
+	// При остановке сначала дожидаются доставки вебхуков от обработанных запросов, затем закрываются клиенты
+	srv := &server.Server{
+		Addr:            ":" + a.config.Port,
+		Handler:         a.router,
+		Logger:          a.logger,
+		Health:          a.health,
+		DrainDelay:      a.config.Shutdown.DrainDelay,
+		ShutdownTimeout: a.config.Shutdown.Timeout,
+		OnShutdown:      []func() error{a.webhooks.Close, a.closeBackends},
+	}
+	return srv.Run()
 }
 
-// sendError — отправляет JSON-ответ с ошибкой
-func (a *App) sendError(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(Response{
-		Status: "error",
-		Error:  message,
-	})
+// initBackends — создает клиенты внутренних сервисов для выбранного протокола
+func (a *App) initBackends() error {
+	if a.config.Services.Transport != TransportGRPC {
+		a.news = httpNews{a}
+		a.comments = httpComments{a}
+		a.censor = httpCensor{a}
+		return nil
+	}
+
+	services := a.config.Services
+	newsConn, err := a.dialService(ServiceNewsAggregator, services.NewsAggregatorGRPC)
+	if err != nil {
+		return err
+	}
+	commentConn, err := a.dialService(ServiceComments, services.CommentServiceGRPC)
+	if err != nil {
+		newsConn.Close()
+		return err
+	}
+	censorConn, err := a.dialService(ServiceCensor, services.CensorServiceGRPC)
+	if err != nil {
+		newsConn.Close()
+		commentConn.Close()
+		return err
+	}
+	a.conns = []*grpc.ClientConn{newsConn, commentConn, censorConn}
+	a.news = grpcNews{a, newspb.NewNewsServiceClient(newsConn)}
+	a.comments = grpcComments{a, commentpb.NewCommentServiceClient(commentConn)}
+	a.censor = grpcCensor{a, censorpb.NewCensorServiceClient(censorConn)}
+	return nil
 }
 
-// Run — запускает HTTP-сервер
-func (a *App) Run() error {
-	return http.ListenAndServe(":"+a.config.Port, a.router)
+// closeBackends — закрывает соединения gRPC с внутренними сервисами
+func (a *App) closeBackends() error {
+	var errs []error
+	for _, conn := range a.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
 }
 
 func main() {
-	config := Config{
-		Port: getEnv("PORT", "8080"),
-	}
+	cfg := DefaultConfig()
+	config.MustLoad("api-gateway", &cfg)
 
-	app := NewApp(config)
+	app := NewApp(cfg)
 
-	log.Printf("API Gateway запущен на порту %s", config.Port)
+	log.Printf("API Gateway запущен на порту %s", cfg.Port)
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maskRune — символ, которым заменяются скрытые слова. Это не символ разметки Markdown: звездочки
+// из скрытого слова Comment Service превратил бы в выделение («****» — в пустой <strong>).
+const maskRune = '•'
+
+// TextSpan — фрагмент текста [Start, End) в байтах UTF-8
+type TextSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// maskText — заменяет каждый символ фрагментов spans на maskRune, сохраняя длину текста в символах.
+// Фрагменты приходят из Censor Service упорядоченными и непересекающимися; фрагменты за пределами текста,
+// пересекающие предыдущий или разрезающие символ UTF-8 пропускаются.
+func maskText(text string, spans []TextSpan) string {
+	var sb strings.Builder
+	sb.Grow(len(text))
+	pos := 0
+	for _, sp := range spans {
+		if sp.Start < pos || sp.End <= sp.Start || sp.End > len(text) || !runeBoundary(text, sp.Start) || !runeBoundary(text, sp.End) {
+			continue
+		}
+		sb.WriteString(text[pos:sp.Start])
+		for range text[sp.Start:sp.End] {
+			sb.WriteRune(maskRune)
+		}
+		pos = sp.End
+	}
+	sb.WriteString(text[pos:])
+	return sb.String()
+}
+
+func runeBoundary(text string, i int) bool {
+	return i == len(text) || utf8.RuneStart(text[i])
+}
+
+// publicComment — комментарий без исходного текста: он доступен только модераторам
+func publicComment(c *Comment) *Comment {
+	public := *c
+	public.OriginalText = ""
+	return &public
+}
+
+// rejectedComment — данные вебхука об отклоненном комментарии, без его текста
+func rejectedComment(c Comment, reason string) RejectedComment {
+	return RejectedComment{NewsID: c.NewsID, ParentID: c.ParentID, Author: c.Author, Reason: reason}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pkg/httpx"
+	"pkg/textx"
+)
+
+func TestMaskText(t *testing.T) {
+	text := "Купите СПАМ тут 😀 и спам"
+	tests := []struct {
+		name  string
+		spans []TextSpan
+		want  string
+	}{
+		{"без фрагментов", nil, text},
+		{"кириллица", []TextSpan{{13, 21}}, "Купите •••• тут 😀 и спам"},
+		{"несколько фрагментов", []TextSpan{{13, 21}, {37, 45}}, "Купите •••• тут 😀 и ••••"},
+		{"эмодзи", []TextSpan{{29, 33}}, "Купите СПАМ тут • и спам"},
+		{"за пределами текста", []TextSpan{{37, 100}}, text},
+		{"разрезает символ", []TextSpan{{14, 21}}, text},
+		{"пересекается с предыдущим", []TextSpan{{13, 21}, {15, 25}}, "Купите •••• тут 😀 и спам"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskText(text, tt.spans); got != tt.want {
+				t.Errorf("Ожидалось %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMaskedTextRendersMarkdown(t *testing.T) {
+	tests := []struct {
+		text  string
+		spans []TextSpan
+		want  string
+	}{
+		{"Это спам", []TextSpan{{7, 15}}, "<p>Это ••••</p>"},
+		{"да ну", []TextSpan{{0, 4}}, "<p>•• ну</p>"},
+		{"Это **спам** и *ад*", []TextSpan{{9, 17}, {24, 28}}, "<p>Это <strong>••••</strong> и <em>••</em></p>"},
+	}
+	for _, tt := range tests {
+		if got := textx.RenderMarkdown(maskText(tt.text, tt.spans)); got != tt.want {
+			t.Errorf("%q: ожидалось %s, получено %s", tt.text, tt.want, got)
+		}
+	}
+}
+
+// maskingCensor — Censor Service с правилом mask для слова «спам»
+type maskingCensor struct{}
+
+func (maskingCensor) CheckText(_ context.Context, check CensorCheck) (*CensorVerdict, *httpx.Problem) {
+	verdict := &CensorVerdict{Verdict: VerdictAccept}
+	if i := strings.Index(check.Text, "спам"); i >= 0 {
+		verdict.Mask = []TextSpan{{i, i + len("спам")}}
+	}
+	return verdict, nil
+}
+
+// savingComments — Comment Service, запоминающий сохраненный комментарий
+type savingComments struct {
+	fakeComments
+	saved Comment
+}
+
+func (f *savingComments) CreateComment(_ context.Context, comment Comment) (*Comment, *httpx.Problem) {
+	f.saved = comment
+	comment.ID = 100
+	return &comment, nil
+}
+
+func TestCreateCommentMasksTerms(t *testing.T) {
+	app, _ := newGraphQLTestApp()
+	comments := &savingComments{}
+	app.comments = comments
+	app.censor = maskingCensor{}
+
+	created, problem := app.createComment(context.Background(), Comment{NewsID: 1, Text: "Это спам!", OriginalText: "подмена"})
+	if problem != nil {
+		t.Fatal(problem)
+	}
+	if comments.saved.Text != "Это ••••!" || comments.saved.OriginalText != "Это спам!" {
+		t.Errorf("Должен сохраняться скрытый текст и исходный для модераторов: %+v", comments.saved)
+	}
+	if created.Text != "Это ••••!" || created.OriginalText != "" {
+		t.Errorf("Ответ не должен содержать исходный текст: %+v", created)
+	}
+
+	if _, problem := app.createComment(context.Background(), Comment{NewsID: 1, Text: "обычный", OriginalText: "подмена"}); problem != nil {
+		t.Fatal(problem)
+	}
+	if comments.saved.OriginalText != "" {
+		t.Errorf("Клиент не может задать исходный текст: %+v", comments.saved)
+	}
+}
+
+// pendingComments — Comment Service с одним скрытым комментарием в очереди модерации
+type pendingComments struct {
+	fakeComments
+	comment Comment
+}
+
+func (f *pendingComments) SetCommentStatus(_ context.Context, id int, status string) (*Comment, string, *httpx.Problem) {
+	previous := f.comment.Status
+	f.comment.Status = status
+	c := f.comment
+	return &c, previous, nil
+}
+
+func TestApproveMaskedCommentWebhook(t *testing.T) {
+	rcv := &webhookReceiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	app := newTestApp()
+	app.comments = &pendingComments{comment: Comment{ID: 5, NewsID: 1, Text: "Это ••••!", OriginalText: "Это спам!", Status: CommentPending}}
+	registerWebhook(t, app, fmt.Sprintf(`{"url":%q,"events":["comment.created"]}`, srv.URL))
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest(http.MethodPost, "/api/v1/comments/5/approve", ""))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Это спам!") {
+		t.Errorf("Модератор должен получить исходный текст: %d %s", rr.Code, rr.Body.String())
+	}
+	// Комментарий уже опубликован: повторное одобрение не создает второе событие
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest(http.MethodPost, "/api/v1/comments/5/approve", ""))
+	app.webhooks.Wait()
+	if rr.Code != http.StatusOK {
+		t.Errorf("Повторное одобрение: ожидался статус %d, получен %d", http.StatusOK, rr.Code)
+	}
+
+	if len(rcv.bodies) != 1 {
+		t.Fatalf("Ожидалась одна доставка, получено %d", len(rcv.bodies))
+	}
+	var event struct {
+		Data Comment `json:"data"`
+	}
+	if err := json.Unmarshal(rcv.bodies[0], &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Data.Text != "Это ••••!" || event.Data.OriginalText != "" || strings.Contains(string(rcv.bodies[0]), "спам") {
+		t.Errorf("Вебхук не должен получать исходный текст: %s", rcv.bodies[0])
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"pkg/httpx"
+	"pkg/textx"
+)
+
+// ModeratorOnly — мидлвар, пропускающий только запросы с токеном модератора
+func (a *App) ModeratorOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.config.ModeratorToken == "" {
+			httpx.SendError(w, r, http.StatusForbidden, httpx.CodeForbidden, "Moderator access is not configured")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.config.ModeratorToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			httpx.SendError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized, "Moderator token required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SearchComments — полнотекстовый поиск комментариев для модераторов
+func (a *App) SearchComments(w http.ResponseWriter, r *http.Request) {
+	if textx.Length(r.URL.Query().Get("q")) > a.config.Limits.MaxCommentSearchLength {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "q", Code: httpx.FieldTooLong, Message: "Search query too long"})
+		return
+	}
+
+	q := r.URL.Query()
+	search := CommentSearch{
+		Q:      q.Get("q"),
+		Author: q.Get("author"),
+		Status: q.Get("status"),
+		From:   q.Get("from"),
+		To:     q.Get("to"),
+	}
+	if v := q.Get("news_id"); v != "" {
+		newsID, err := strconv.Atoi(v)
+		if err != nil || newsID < 1 {
+			httpx.SendValidationError(w, r, httpx.FieldError{Field: "news_id", Code: httpx.FieldInvalid, Message: "Invalid news_id"})
+			return
+		}
+		search.NewsID = newsID
+	}
+	search.Page, _ = strconv.Atoi(q.Get("page"))
+	search.PageSize, _ = strconv.Atoi(q.Get("page_size"))
+
+	comments, problem := a.comments.SearchComments(r.Context(), search)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+
+	httpx.SendResponse(w, http.StatusOK, comments)
+}
+
+// ApproveComment — публикация комментария из очереди модерации. Вебхук comment.created отправляется,
+// только если комментарий действительно был в очереди: повторное одобрение его не дублирует.
+func (a *App) ApproveComment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid comment ID"})
+		return
+	}
+
+	comment, previous, problem := a.comments.SetCommentStatus(r.Context(), id, CommentPublished)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+
+	// Исходный текст до маскировки видит только модератор, но не получатели вебхуков
+	if previous == CommentPending {
+		a.webhooks.Dispatch(EventCommentCreated, comment.NewsID, publicComment(comment))
+	}
+	httpx.SendResponse(w, http.StatusOK, comment)
+}
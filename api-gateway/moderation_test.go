@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pkg/httpx"
+)
+
+func TestSearchCommentsRequiresModerator(t *testing.T) {
+	var gotQuery string
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.Write([]byte(`{"status":"success","data":[{"id":1,"news_id":1,"author":"anna","text":"test"}]}`))
+	}))
+	defer commentService.Close()
+
+	cfg := DefaultConfig()
+	cfg.ModeratorToken = "secret"
+	cfg.Services.CommentServiceURL = commentService.URL
+	app := NewApp(cfg)
+
+	cases := []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/comments/search?q=test&author=anna&unknown=1", nil)
+		if c.auth != "" {
+			req.Header.Set("Authorization", c.auth)
+		}
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		if rr.Code != c.want {
+			t.Errorf("%q: ожидался статус %d, получен %d", c.auth, c.want, rr.Code)
+		}
+	}
+
+	if gotQuery != "author=anna&q=test" {
+		t.Errorf("Неверные параметры запроса к Comment Service: %s", gotQuery)
+	}
+
+	// Без настроенного токена доступ закрыт
+	app = NewApp(DefaultConfig())
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/comments/search?q=test", nil))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Ожидался статус %d, получен %d", http.StatusForbidden, rr.Code)
+	}
+}
+
+func TestCreateCommentSpamVerdicts(t *testing.T) {
+	var verdict string
+	var gotAuthor string
+	censorService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		gotAuthor = req["author"]
+		fmt.Fprintf(w, `{"status":"success","data":{"verdict":%q,"spam_score":0.7,"signals":[{"rule":"links","score":0.7}]}}`, verdict)
+	}))
+	defer censorService.Close()
+	var created []Comment
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			published := map[string]any{"id": 5, "news_id": 1, "text": "test", "status": CommentPublished, "previous_status": CommentPending}
+			json.NewEncoder(w).Encode(httpx.Response{Status: "success", Data: published})
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var c Comment
+		json.Unmarshal(body, &c)
+		c.ID = len(created) + 1
+		created = append(created, c)
+		json.NewEncoder(w).Encode(httpx.Response{Status: "success", Data: c})
+	}))
+	defer commentService.Close()
+
+	rcv := &webhookReceiver{}
+	hookServer := httptest.NewServer(rcv)
+	defer hookServer.Close()
+
+	app := newTestApp()
+	fakeBackends(t, app)
+	app.config.ModeratorToken = "secret"
+	app.config.Services.CensorServiceURL = censorService.URL
+	app.config.Services.CommentServiceURL = commentService.URL
+	registerWebhook(t, app, fmt.Sprintf(`{"url":%q,"events":["comment.created","comment.rejected"]}`, hookServer.URL))
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(body)))
+		app.webhooks.Wait()
+		return rr
+	}
+
+	// Клиент не может сам выбрать статус комментария
+	verdict = VerdictAccept
+	if rr := post(`{"news_id":1,"author":"anna","text":"обычный","status":"pending"}`); rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if gotAuthor != "anna" || len(created) != 1 || created[0].Status != CommentPublished {
+		t.Errorf("Принятый комментарий должен публиковаться: автор %q, %+v", gotAuthor, created)
+	}
+
+	verdict = VerdictQueue
+	rr := post(`{"news_id":1,"text":"подозрительный"}`)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"status":"pending"`) || created[1].Status != CommentPending {
+		t.Errorf("Подозрительный комментарий должен ждать модерации: %d %s", rr.Code, rr.Body.String())
+	}
+
+	verdict = VerdictReject
+	rr = post(`{"news_id":1,"text":"спам"}`)
+	if p := decodeProblem(t, rr); rr.Code != http.StatusBadRequest || p.Code != httpx.CodeSpamDetected || len(created) != 2 {
+		t.Errorf("Спам должен отклоняться без сохранения: %d %+v", rr.Code, p)
+	}
+
+	// Одобрение модератором публикует комментарий и оповещает вебхуки
+	req := httptest.NewRequest("POST", "/api/v1/comments/5/approve", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, req)
+	app.webhooks.Wait()
+	if rr.Code != http.StatusOK {
+		t.Errorf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var events []string
+	for _, h := range rcv.headers {
+		events = append(events, h.Get("X-Webhook-Event"))
+	}
+	want := []string{EventCommentCreated, EventCommentRejected, EventCommentCreated}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Fatalf("Ожидались события %v, получено %v", want, events)
+	}
+
+	// Текст отклоненного комментария получателям не передается
+	var rejected struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rcv.bodies[1], &rejected); err != nil {
+		t.Fatal(err)
+	}
+	if rejected.Data["reason"] != httpx.CodeSpamDetected || rejected.Data["news_id"] != float64(1) || strings.Contains(string(rcv.bodies[1]), "спам") {
+		t.Errorf("Событие comment.rejected должно содержать причину без текста: %s", rcv.bodies[1])
+	}
+}
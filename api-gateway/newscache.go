@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"pkg/httpx"
+)
+
+// newsCache — новости, существование которых уже подтвердил News Aggregator.
+// Запоминаются только найденные новости: новость, которой еще нет, может появиться
+// при следующем опросе источников.
+type newsCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	known map[int]knownNews
+	now   func() time.Time
+}
+
+// knownNews — когда подтверждено существование новости и ее раздел
+type knownNews struct {
+	seen     time.Time
+	category string
+}
+
+func newNewsCache(ttl time.Duration) *newsCache {
+	return &newsCache{ttl: ttl, known: make(map[int]knownNews), now: time.Now}
+}
+
+// get — раздел новости, если ее существование подтверждено не раньше, чем ttl назад
+func (c *newsCache) get(id int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	news, ok := c.known[id]
+	if ok && c.now().Sub(news.seen) >= c.ttl {
+		delete(c.known, id)
+		return "", false
+	}
+	return news.category, ok
+}
+
+// add — запоминает существующую новость и ее раздел
+func (c *newsCache) add(id int, category string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	// Устаревшие записи вычищаются при росте кэша, чтобы он не рос без ограничений
+	if len(c.known) >= newsCacheSweepSize {
+		for k, news := range c.known {
+			if now.Sub(news.seen) >= c.ttl {
+				delete(c.known, k)
+			}
+		}
+	}
+	c.known[id] = knownNews{seen: now, category: category}
+}
+
+// newsCacheSweepSize — размер кэша, после которого при добавлении удаляются устаревшие записи
+const newsCacheSweepSize = 10000
+
+// checkNewsExists — проверяет, что новость, к которой создается комментарий, существует.
+// Отсутствующая новость — ошибка поля news_id; сбои News Aggregator передаются как есть.
+// Некорректный id проверяет Comment Service вместе с остальными полями.
+// Возвращает раздел (категорию) новости: по нему выбирается политика Censor Service.
+func (a *App) checkNewsExists(ctx context.Context, id int) (string, *httpx.Problem) {
+	if id < 1 {
+		return "", nil
+	}
+	if category, ok := a.newsCache.get(id); ok {
+		return category, nil
+	}
+	news, problem := a.news.GetNews(ctx, id)
+	if problem != nil {
+		if problem.Status == http.StatusNotFound {
+			return "", httpx.ValidationProblem([]httpx.FieldError{{Field: "news_id", Code: httpx.FieldNotFound, Message: "News does not exist"}})
+		}
+		return "", problem
+	}
+	a.newsCache.add(id, news.Category)
+	return news.Category, nil
+}
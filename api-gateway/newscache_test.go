@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pkg/httpx"
+)
+
+func TestCreateCommentChecksNews(t *testing.T) {
+	var newsCalls, createCalls int
+	newsService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		newsCalls++
+		if r.URL.Path != "/news/1" {
+			w.Header().Set("Content-Type", httpx.ProblemContentType)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"/problems/not_found","title":"Not Found","status":404,"code":"not_found","detail":"News not found"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"id":1,"title":"Новость 1"}}`))
+	}))
+	defer newsService.Close()
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		createCalls++
+		w.Write([]byte(`{"status":"success","data":{"id":5,"news_id":1,"text":"test"}}`))
+	}))
+	defer commentService.Close()
+
+	app := newTestApp()
+	fakeBackends(t, app)
+	app.config.Services.NewsAggregatorURL = newsService.URL
+	app.config.Services.CommentServiceURL = commentService.URL
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := post(`{"news_id":2,"text":"test"}`)
+	p := decodeProblem(t, rr)
+	if rr.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "news_id" || p.Errors[0].Code != httpx.FieldNotFound {
+		t.Errorf("Комментарий к несуществующей новости должен отклоняться: %d %+v", rr.Code, p)
+	}
+	if createCalls != 0 {
+		t.Errorf("Comment Service не должен вызываться для несуществующей новости")
+	}
+
+	// Существование новости запоминается: повторные комментарии не обращаются к News Aggregator
+	for i := 0; i < 2; i++ {
+		if rr := post(`{"news_id":1,"text":"test"}`); rr.Code != http.StatusOK {
+			t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+	if newsCalls != 2 || createCalls != 2 {
+		t.Errorf("Ожидалось 2 вызова News Aggregator и 2 вызова Comment Service, получено %d и %d", newsCalls, createCalls)
+	}
+}
+
+func TestCreateCommentNewsUnavailable(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+	down := httptest.NewServer(http.NotFoundHandler())
+	app.config.Services.NewsAggregatorURL = down.URL
+	down.Close()
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"test"}`)))
+	if p := decodeProblem(t, rr); rr.Code != http.StatusServiceUnavailable || p.Code != httpx.CodeUpstreamUnavailable {
+		t.Errorf("Ожидалась ошибка upstream_unavailable, получено %d %+v", rr.Code, p)
+	}
+}
+
+func TestNewsCacheExpires(t *testing.T) {
+	c := newNewsCache(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.add(1, "sport")
+	if category, ok := c.get(1); !ok || category != "sport" {
+		t.Fatalf("Кэш должен помнить добавленную новость и ее раздел, получено %q %v", category, ok)
+	}
+	if _, ok := c.get(2); ok {
+		t.Fatal("Кэш должен помнить только добавленные новости")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.get(1); ok {
+		t.Error("Запись должна устаревать через ttl")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec — спецификация OpenAPI 3.1 публичного API, поддерживается вручную
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUIPage — страница Swagger UI, загружающая спецификацию с /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>API Gateway — документация</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+// OpenAPISpec — отдает спецификацию OpenAPI
+func (a *App) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
+
+// SwaggerUI — страница с интерактивной документацией
+func (a *App) SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(swaggerUIPage))
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"pkg/httpx"
+)
+
+const specURL = "file:///openapi.json"
+
+// contractValidator — проверяет запросы и ответы на соответствие openapi.json
+type contractValidator struct {
+	t        *testing.T
+	doc      map[string]any
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+func newContractValidator(t *testing.T) *contractValidator {
+	t.Helper()
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(openAPISpec))
+	if err != nil {
+		t.Fatalf("openapi.json не является корректным JSON: %v", err)
+	}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+	if err := c.AddResource(specURL, doc); err != nil {
+		t.Fatal(err)
+	}
+	return &contractValidator{t: t, doc: doc.(map[string]any), compiler: c, schemas: make(map[string]*jsonschema.Schema)}
+}
+
+// escapePointer — экранирует сегмент JSON Pointer
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// lookup — возвращает узел документа по JSON Pointer
+func (v *contractValidator) lookup(ptr string) any {
+	var node any = v.doc
+	for _, part := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]any:
+			node = n[part]
+		case []any:
+			i, _ := strconv.Atoi(part)
+			node = n[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// resolve — следует по $ref и возвращает указатель на итоговый узел
+func (v *contractValidator) resolve(ptr string) string {
+	if node, ok := v.lookup(ptr).(map[string]any); ok {
+		if ref, ok := node["$ref"].(string); ok {
+			return v.resolve(strings.TrimPrefix(ref, "#"))
+		}
+	}
+	return ptr
+}
+
+func (v *contractValidator) validate(ptr string, value any) error {
+	sch, ok := v.schemas[ptr]
+	if !ok {
+		loc := specURL + "#" + strings.NewReplacer("{", "%7B", "}", "%7D").Replace(ptr)
+		var err error
+		if sch, err = v.compiler.Compile(loc); err != nil {
+			v.t.Fatalf("Не удалось скомпилировать схему %s: %v", ptr, err)
+		}
+		v.schemas[ptr] = sch
+	}
+	return sch.Validate(value)
+}
+
+// operation — находит операцию спецификации и значения параметров пути
+func (v *contractValidator) operation(method, path string) (string, map[string]string) {
+	paths := v.doc["paths"].(map[string]any)
+	for template := range paths {
+		names := regexp.MustCompile(`\{([^}]+)\}`).FindAllStringSubmatch(template, -1)
+		re := regexp.MustCompile("^" + regexp.MustCompile(`\\\{[^}]+\\\}`).ReplaceAllString(regexp.QuoteMeta(template), `([^/]+)`) + "$")
+		m := re.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+		params := make(map[string]string)
+		for i, name := range names {
+			params[name[1]] = m[i+1]
+		}
+		ptr := "/paths/" + escapePointer(template)
+		if _, ok := paths[template].(map[string]any)[strings.ToLower(method)]; !ok {
+			return "", nil
+		}
+		return ptr, params
+	}
+	return "", nil
+}
+
+// checkParam — проверяет строковое значение параметра, приводя его к числу при необходимости
+func (v *contractValidator) checkParam(ptr, raw string) error {
+	err := v.validate(ptr, raw)
+	if err == nil {
+		return nil
+	}
+	if n, convErr := strconv.ParseFloat(raw, 64); convErr == nil {
+		return v.validate(ptr, json.Number(strconv.FormatFloat(n, 'f', -1, 64)))
+	}
+	return err
+}
+
+// validateRequest — проверяет параметры и тело запроса
+func (v *contractValidator) validateRequest(req *http.Request, body []byte) error {
+	pathPtr, pathParams := v.operation(req.Method, req.URL.Path)
+	if pathPtr == "" {
+		return fmt.Errorf("операция %s %s не описана", req.Method, req.URL.Path)
+	}
+	opPtr := pathPtr + "/" + strings.ToLower(req.Method)
+
+	var paramPtrs []string
+	for _, base := range []string{pathPtr, opPtr} {
+		params, _ := v.lookup(base + "/parameters").([]any)
+		for i := range params {
+			paramPtrs = append(paramPtrs, v.resolve(fmt.Sprintf("%s/parameters/%d", base, i)))
+		}
+	}
+	for _, ptr := range paramPtrs {
+		param := v.lookup(ptr).(map[string]any)
+		name := param["name"].(string)
+		var raw string
+		var present bool
+		switch param["in"] {
+		case "path":
+			raw, present = pathParams[name]
+		case "query":
+			present = req.URL.Query().Has(name)
+			raw = req.URL.Query().Get(name)
+		}
+		if !present {
+			if required, _ := param["required"].(bool); required {
+				return fmt.Errorf("отсутствует обязательный параметр %s", name)
+			}
+			continue
+		}
+		if err := v.checkParam(ptr+"/schema", raw); err != nil {
+			return fmt.Errorf("параметр %s: %v", name, err)
+		}
+	}
+
+	if v.lookup(opPtr+"/requestBody") != nil {
+		value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("тело запроса не JSON: %v", err)
+		}
+		if err := v.validate(v.resolve(opPtr+"/requestBody")+"/content/application~1json/schema", value); err != nil {
+			return fmt.Errorf("тело запроса: %v", err)
+		}
+	}
+	return nil
+}
+
+// validateResponse — проверяет статус, тип содержимого и тело ответа
+func (v *contractValidator) validateResponse(req *http.Request, rr *httptest.ResponseRecorder) error {
+	pathPtr, _ := v.operation(req.Method, req.URL.Path)
+	opPtr := pathPtr + "/" + strings.ToLower(req.Method)
+
+	respPtr := opPtr + "/responses/" + strconv.Itoa(rr.Code)
+	if v.lookup(respPtr) == nil {
+		respPtr = opPtr + "/responses/default"
+		if v.lookup(respPtr) == nil {
+			return fmt.Errorf("статус %d не описан", rr.Code)
+		}
+	}
+	respPtr = v.resolve(respPtr)
+
+	content, _ := v.lookup(respPtr + "/content").(map[string]any)
+	if len(content) == 0 {
+		return nil
+	}
+	ct := rr.Header().Get("Content-Type")
+	for mediaType := range content {
+		if !strings.HasPrefix(ct, mediaType) {
+			continue
+		}
+		value, err := jsonschema.UnmarshalJSON(bytes.NewReader(rr.Body.Bytes()))
+		if err != nil {
+			return fmt.Errorf("тело ответа не JSON: %v", err)
+		}
+		if err := v.validate(respPtr+"/content/"+escapePointer(mediaType)+"/schema", value); err != nil {
+			return fmt.Errorf("тело ответа: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("неверный Content-Type %q", ct)
+}
+
+// fakeBackends — поднимает заглушки News Aggregator, Comment Service и Censor Service
+// и направляет на них запросы приложения
+func fakeBackends(t *testing.T, app *App) {
+	t.Helper()
+	news := `{"id":1,"title":"Новость 1","content":"Текст","date":"2023-01-01T09:00:00Z","source":"ria","category":"politics","tags":["elections"]}`
+	comment := `{"id":5,"news_id":1,"author":"anna","text":"Комментарий","created_at":"2023-01-01T10:00:00Z"}`
+
+	newsService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/livez":
+			w.Write([]byte(`{"status":"ok","version":"test"}`))
+		case "/news":
+			fmt.Fprintf(w, `{"status":"success","data":[%s],"pagination":{"page":1,"page_size":10,"total":1,"page_count":1}}`, news)
+		case "/news/1":
+			fmt.Fprintf(w, `{"status":"success","data":%s}`, news)
+		default:
+			w.Header().Set("Content-Type", httpx.ProblemContentType)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"/problems/not_found","title":"Not Found","status":404,"code":"not_found","detail":"News not found"}`))
+		}
+	}))
+	commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost || r.Method == http.MethodPut:
+			fmt.Fprintf(w, `{"status":"success","data":%s}`, comment)
+		case r.URL.Path == "/comments/counts":
+			w.Write([]byte(`{"status":"success","data":{"1":3}}`))
+		default:
+			fmt.Fprintf(w, `{"status":"success","data":[%s]}`, comment)
+		}
+	}))
+	censorService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("qwerty")) {
+			w.Header().Set("Content-Type", httpx.ProblemContentType)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"type":"/problems/forbidden_words","title":"Bad Request","status":400,"code":"forbidden_words","detail":"Text contains forbidden words"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+
+	app.config.Services.NewsAggregatorURL = newsService.URL
+	app.config.Services.CommentServiceURL = commentService.URL
+	app.config.Services.CensorServiceURL = censorService.URL
+	t.Cleanup(func() {
+		newsService.Close()
+		commentService.Close()
+		censorService.Close()
+	})
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	v := newContractValidator(t)
+	app := newTestApp()
+	fakeBackends(t, app)
+	app.config.ModeratorToken = "secret"
+
+	cases := []struct {
+		method, path, body string
+		moderator          bool
+		status             int
+		validRequest       bool
+	}{
+		{"GET", "/health", "", false, 200, true},
+		{"GET", "/livez", "", false, 200, true},
+		{"GET", "/readyz", "", false, 200, true},
+		{"GET", "/health/deps", "", true, 200, true},
+		{"GET", "/health/deps", "", false, 401, true},
+		{"GET", "/api/v1/news", "", false, 200, true},
+		{"GET", "/api/v1/news?page=2&page_size=5&search=новость&from=2023-01-01&to=2023-01-02T00:00:00Z&source=ria&category=sport&sort=relevance", "", false, 200, true},
+		{"GET", "/api/v1/news?include=comment_count", "", false, 200, true},
+		{"GET", "/api/v1/news?include=authors", "", false, 400, false},
+		{"GET", "/api/v1/news?sort=random", "", false, 400, false},
+		{"GET", "/api/v1/news?from=yesterday", "", false, 400, false},
+		{"GET", "/api/v1/news?search=" + strings.Repeat("a", 101), "", false, 400, false},
+		{"GET", "/api/v1/news?search=" + strings.Repeat("ж", 100), "", false, 200, true},
+		{"GET", "/api/v1/news?search=" + strings.Repeat("ж", 101), "", false, 400, false},
+		{"GET", "/api/v1/news/1", "", false, 200, true},
+		{"GET", "/api/v1/news/2", "", false, 404, true},
+		{"GET", "/api/v1/news/abc", "", false, 400, false},
+		{"POST", "/api/v1/comment", `{"news_id":1,"author":"anna","text":"Комментарий"}`, false, 200, true},
+		{"POST", "/api/v1/comment", `{"news_id":1,"text":"qwerty"}`, false, 400, true},
+		{"GET", "/api/v1/comments/search?q=комментарий&news_id=1", "", true, 200, true},
+		{"GET", "/api/v1/comments/search?q=комментарий", "", false, 401, true},
+		{"GET", "/api/v1/comments/search?status=pending", "", true, 200, true},
+		{"POST", "/api/v1/comments/5/approve", "", true, 200, true},
+		{"POST", "/api/v1/comments/5/approve", "", false, 401, true},
+		{"POST", "/api/v1/comments/abc/approve", "", true, 400, false},
+		{"POST", "/api/v1/webhooks", `{"url":"http://127.0.0.1:1/hook","events":["comment.created"],"active":false}`, true, 201, true},
+		{"POST", "/api/v1/webhooks", `{"url":"http://127.0.0.1:1/hook","events":["unknown"]}`, true, 400, false},
+		{"GET", "/api/v1/webhooks", "", true, 200, true},
+		{"GET", "/api/v1/webhooks/1", "", true, 200, true},
+		{"GET", "/api/v1/webhooks/99", "", true, 404, true},
+		{"PUT", "/api/v1/webhooks/1", `{"url":"https://example.com/hook","events":["comment.rejected"],"active":false}`, true, 200, true},
+		{"GET", "/api/v1/webhooks/1/deliveries", "", true, 200, true},
+		{"POST", "/api/v1/webhooks/1/deliveries/1/replay", "", true, 404, true},
+		{"DELETE", "/api/v1/webhooks/1", "", true, 200, true},
+		{"GET", "/api/v1/webhooks", "", false, 401, true},
+	}
+	for _, c := range cases {
+		name := c.method + " " + c.path
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		if c.moderator {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+
+		err := v.validateRequest(req, []byte(c.body))
+		if c.validRequest && err != nil {
+			t.Errorf("%s: запрос не соответствует спецификации: %v", name, err)
+		}
+		if !c.validRequest && err == nil {
+			t.Errorf("%s: спецификация должна отвергать этот запрос", name)
+		}
+
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		if rr.Code != c.status {
+			t.Errorf("%s: ожидался статус %d, получен %d: %s", name, c.status, rr.Code, rr.Body.String())
+			continue
+		}
+		if err := v.validateResponse(req, rr); err != nil {
+			t.Errorf("%s: ответ не соответствует спецификации: %v", name, err)
+		}
+	}
+}
+
+func TestOpenAPIDescribesAllRoutes(t *testing.T) {
+	v := newContractValidator(t)
+	app := newTestApp()
+
+	// Служебные маршруты без конверта Response в спецификацию не входят
+	undocumented := map[string]bool{"/": true, "/openapi.json": true, "/docs": true, "/health/status": true}
+	probes := map[string]bool{"/health": true, "/livez": true, "/readyz": true, "/health/deps": true}
+
+	routed := make(map[string]bool)
+	chi.Walk(app.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		// Маршруты без версии — устаревшие псевдонимы /api/v1, они проверяются в versions_test.go
+		if !undocumented[route] && (probes[route] || strings.HasPrefix(route, APIPrefix+"/")) {
+			routed[method+" "+route] = true
+		}
+		return nil
+	})
+
+	documented := make(map[string]bool)
+	for path, item := range v.doc["paths"].(map[string]any) {
+		for method := range item.(map[string]any) {
+			if method != "parameters" {
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	var missing, stale []string
+	for op := range routed {
+		if !documented[op] {
+			missing = append(missing, op)
+		}
+	}
+	for op := range documented {
+		if !routed[op] {
+			stale = append(stale, op)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("Маршруты не описаны в openapi.json: %v", missing)
+	}
+	if len(stale) > 0 {
+		t.Errorf("В openapi.json описаны несуществующие маршруты: %v", stale)
+	}
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	app := newTestApp()
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+	}
+	if rr.Code != http.StatusOK || json.Unmarshal(rr.Body.Bytes(), &spec) != nil || spec.OpenAPI != "3.1.0" {
+		t.Errorf("Неверный ответ /openapi.json: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/docs", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "/openapi.json") {
+		t.Errorf("Неверный ответ /docs: %d", rr.Code)
+	}
+}
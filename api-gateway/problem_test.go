@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pkg/httpx"
+)
+
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) httpx.Problem {
+	t.Helper()
+	if ct := rr.Header().Get("Content-Type"); ct != httpx.ProblemContentType {
+		t.Fatalf("Ожидался Content-Type %s, получен %q", httpx.ProblemContentType, ct)
+	}
+	var p httpx.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != rr.Code {
+		t.Errorf("Поле status (%d) не совпадает с кодом ответа (%d)", p.Status, rr.Code)
+	}
+	return p
+}
+
+func TestValidationProblem(t *testing.T) {
+	app := newTestApp()
+
+	req := httptest.NewRequest("GET", "/api/v1/news?sort=random&from=yesterday", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, req)
+
+	p := decodeProblem(t, rr)
+	if rr.Code != http.StatusBadRequest || p.Code != httpx.CodeValidationFailed || p.Type != "/problems/validation_failed" {
+		t.Errorf("Неверная ошибка валидации: %d %+v", rr.Code, p)
+	}
+	if p.Instance != "/api/v1/news" || p.RequestID != "req-1" {
+		t.Errorf("Неверные instance/request_id: %+v", p)
+	}
+	fields := map[string]string{}
+	for _, f := range p.Errors {
+		fields[f.Field] = f.Code
+	}
+	if fields["sort"] != httpx.FieldUnknown || fields["from"] != httpx.FieldInvalid {
+		t.Errorf("Неверные ошибки полей: %+v", p.Errors)
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/unknown", nil))
+	if p := decodeProblem(t, rr); rr.Code != http.StatusNotFound || p.Code != httpx.CodeNotFound {
+		t.Errorf("Неизвестный маршрут должен возвращать not_found: %d %+v", rr.Code, p)
+	}
+}
+
+func TestDownstreamErrorMapping(t *testing.T) {
+	cases := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantFields  int
+	}{
+		{
+			name:        "ошибка валидации передается клиенту",
+			status:      http.StatusBadRequest,
+			contentType: httpx.ProblemContentType,
+			body:        `{"type":"/problems/validation_failed","title":"Bad Request","status":400,"code":"validation_failed","detail":"Request validation failed","errors":[{"field":"text","code":"too_long","message":"Text too long"}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    httpx.CodeValidationFailed,
+			wantFields:  1,
+		},
+		{
+			name:        "404 без problem+json",
+			status:      http.StatusNotFound,
+			contentType: "text/plain",
+			body:        "404 page not found",
+			wantStatus:  http.StatusNotFound,
+			wantCode:    httpx.CodeNotFound,
+		},
+		{
+			name:        "сбой сервиса скрывается за 502",
+			status:      http.StatusInternalServerError,
+			contentType: httpx.ProblemContentType,
+			body:        `{"type":"/problems/internal_error","title":"Internal Server Error","status":500,"code":"internal_error","detail":"Database error"}`,
+			wantStatus:  http.StatusBadGateway,
+			wantCode:    httpx.CodeUpstreamError,
+		},
+	}
+
+	for _, c := range cases {
+		commentService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", c.contentType)
+			w.WriteHeader(c.status)
+			w.Write([]byte(c.body))
+		}))
+		app := newTestApp()
+		fakeBackends(t, app)
+		app.config.Services.CommentServiceURL = commentService.URL
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/comment", strings.NewReader(`{"news_id":1,"text":"test"}`)))
+		commentService.Close()
+
+		p := decodeProblem(t, rr)
+		if rr.Code != c.wantStatus || p.Code != c.wantCode || len(p.Errors) != c.wantFields {
+			t.Errorf("%s: получено %d %+v", c.name, rr.Code, p)
+		}
+		if strings.Contains(p.Detail, "{") {
+			t.Errorf("%s: тело внутреннего сервиса не должно попадать в detail: %q", c.name, p.Detail)
+		}
+	}
+}
+
+func TestDownstreamUnavailable(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+	down := httptest.NewServer(http.NotFoundHandler())
+	app.config.Services.NewsAggregatorURL = down.URL
+	down.Close()
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news/1", nil))
+	if p := decodeProblem(t, rr); rr.Code != http.StatusServiceUnavailable || p.Code != httpx.CodeUpstreamUnavailable {
+		t.Errorf("Ожидалась ошибка upstream_unavailable, получено %d %+v", rr.Code, p)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errInternalHost — адрес получателя вебхука указывает во внутреннюю сеть
+var errInternalHost = errors.New("webhook receiver must be a public host")
+
+// internalHostSuffixes — доменные зоны, которые разрешаются только внутри сети или кластера
+var internalHostSuffixes = []string{".localhost", ".local", ".internal", ".intranet", ".lan", ".home.arpa", ".svc", ".cluster.local"}
+
+// sharedAddressSpace — 100.64.0.0/10 (RFC 6598), которую часто занимают адреса подов и сервисов кластера
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// checkWebhookHost — проверяет имя или IP-адрес получателя по URL вебхука. Имена без точки
+// (comment-service, localhost) и внутренние зоны разрешаются во внутренние адреса, поэтому отклоняются сразу;
+// адрес, в который на самом деле разрешится публичное имя, проверяется еще раз при подключении.
+func checkWebhookHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return checkWebhookIP(ip)
+	}
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return errInternalHost
+	}
+	for _, suffix := range internalHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return errInternalHost
+		}
+	}
+	return nil
+}
+
+// checkWebhookIP — отклоняет loopback, частные, link-local (в том числе 169.254.169.254 — метаданные облака),
+// multicast, неуказанные адреса и адреса кластера
+func checkWebhookIP(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || sharedAddressSpace.Contains(ip) {
+		return errInternalHost
+	}
+	return nil
+}
+
+// newWebhookClient — HTTP-клиент доставки вебхуков. Адрес проверяется в момент подключения, после разрешения
+// имени, поэтому получатель не попадет во внутреннюю сеть ни через DNS, ни через перенаправление.
+// Прокси из окружения не используется: он выполнил бы подключение вместо шлюза.
+func newWebhookClient(allowInternal func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if allowInternal() {
+				return nil
+			}
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			return checkWebhookIP(addr.Addr())
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
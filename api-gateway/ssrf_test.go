@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestCheckWebhookHost(t *testing.T) {
+	tests := []struct {
+		host   string
+		public bool
+	}{
+		{"example.com", true},
+		{"hooks.example.com.", true},
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"localhost", false},
+		{"LOCALHOST.", false},
+		{"comment-service", false},
+		{"api.localhost", false},
+		{"censor.default.svc.cluster.local", false},
+		{"metadata.google.internal", false},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.10", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if err := checkWebhookHost(tt.host); (err == nil) != tt.public {
+			t.Errorf("%s: ожидалось public=%v, получено %v", tt.host, tt.public, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"pkg/idempotency"
+)
+
+// APIPrefix — общий префикс версионированного API
+const APIPrefix = "/api"
+
+// Даты вывода из эксплуатации маршрутов без версии
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// APIVersion — версия публичного API, монтируемая под /api/<Name>.
+// Новая версия добавляется в App.versions рядом с существующими.
+type APIVersion struct {
+	Name         string
+	Routes       func(r chi.Router)
+	DeprecatedAt time.Time // нулевое значение — версия не устарела
+	SunsetAt     time.Time // дата отключения устаревшей версии
+	Successor    string    // имя версии, на которую следует перейти
+}
+
+// Prefix — путь, под которым смонтирована версия
+func (v APIVersion) Prefix() string {
+	return APIPrefix + "/" + v.Name
+}
+
+// mountVersions — монтирует все версии API и устаревшие маршруты без версии
+func (a *App) mountVersions(r chi.Router) {
+	for _, v := range a.versions {
+		r.Route(v.Prefix(), func(r chi.Router) {
+			if !v.DeprecatedAt.IsZero() {
+				r.Use(DeprecationMiddleware(v.DeprecatedAt, v.SunsetAt, APIPrefix+"/"+v.Successor, v.Prefix()))
+			}
+			v.Routes(r)
+		})
+	}
+
+	// Маршруты без версии — псевдонимы v1 для существующих клиентов
+	r.Group(func(r chi.Router) {
+		r.Use(DeprecationMiddleware(legacyDeprecatedAt, legacySunsetAt, APIPrefix+"/v1", ""))
+		a.routesV1(r)
+	})
+}
+
+// DeprecationMiddleware — добавляет заголовки Deprecation (RFC 9745), Sunset (RFC 8594)
+// и ссылку на тот же ресурс в версии-преемнике
+func DeprecationMiddleware(deprecatedAt, sunsetAt time.Time, successor, prefix string) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunset := sunsetAt.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if !sunsetAt.IsZero() {
+				w.Header().Set("Sunset", sunset)
+			}
+			path := r.URL.Path[len(prefix):]
+			w.Header().Add("Link", "<"+successor+path+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routesV1 — маршруты первой версии API
+func (a *App) routesV1(r chi.Router) {
+	r.Get("/news", a.GetNews)
+	r.Get("/news/{id}", a.GetNewsByID)
+	r.With(idempotency.Middleware(a.idempotency)).Post("/comment", a.CreateComment)
+	r.Get("/graphql", a.GraphQL)
+	r.Post("/graphql", a.GraphQL)
+
+	// Модерация
+	r.With(a.ModeratorOnly).Get("/comments/search", a.SearchComments)
+	r.With(a.ModeratorOnly).Post("/comments/{id}/approve", a.ApproveComment)
+
+	// Вебхуки: журнал доставок содержит тексты комментариев, а адрес получателя задает администратор
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(a.ModeratorOnly)
+		r.Post("/", a.CreateWebhook)
+		r.Get("/", a.ListWebhooks)
+		r.Get("/{id}", a.GetWebhook)
+		r.Put("/{id}", a.UpdateWebhook)
+		r.Delete("/{id}", a.DeleteWebhook)
+		r.Get("/{id}/deliveries", a.ListDeliveries)
+		r.Post("/{id}/deliveries/{deliveryID}/replay", a.ReplayDelivery)
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"pkg/httpx"
+)
+
+func TestLegacyRoutesAreDeprecatedAliases(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news/1", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Deprecation") != "" {
+		t.Errorf("/api/v1 не должен быть помечен устаревшим: %d %v", rr.Code, rr.Header())
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/news/1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get("Deprecation"); got != "@1792281600" {
+		t.Errorf("Неверный заголовок Deprecation: %q", got)
+	}
+	if got := rr.Header().Get("Sunset"); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+		t.Errorf("Неверный заголовок Sunset: %q", got)
+	}
+	if got := rr.Header().Get("Link"); got != `</api/v1/news/1>; rel="successor-version"` {
+		t.Errorf("Неверный заголовок Link: %q", got)
+	}
+
+	// У служебных маршрутов нет версии, и они не устаревают
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("GET", "/health", nil))
+	if rr.Header().Get("Deprecation") != "" {
+		t.Errorf("/health не должен быть помечен устаревшим")
+	}
+}
+
+func TestVersionsSideBySide(t *testing.T) {
+	app := newTestApp()
+	fakeBackends(t, app)
+
+	// Новая версия регистрируется рядом с v1, а v1 объявляется устаревшей
+	app.versions[0].DeprecatedAt = time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	app.versions[0].Successor = "v2"
+	app.versions = append(app.versions, APIVersion{Name: "v2", Routes: func(r chi.Router) {
+		r.Get("/news", func(w http.ResponseWriter, r *http.Request) {
+			httpx.SendResponse(w, http.StatusOK, "v2")
+		})
+	}})
+	r := chi.NewRouter()
+	app.mountVersions(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v2/news", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Deprecation") != "" {
+		t.Errorf("v2 должна отвечать без Deprecation: %d %v", rr.Code, rr.Header())
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/news", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Link") != `</api/v2/news>; rel="successor-version"` {
+		t.Errorf("Устаревшая v1 должна ссылаться на v2: %d %v", rr.Code, rr.Header())
+	}
+}
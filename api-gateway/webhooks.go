@@ -0,0 +1,591 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"pkg/httpx"
+)
+
+// События, на которые можно подписать вебхук
+const (
+	EventCommentCreated  = "comment.created"
+	EventCommentRejected = "comment.rejected"
+)
+
+// knownEvents — список поддерживаемых событий
+var knownEvents = map[string]bool{
+	EventCommentCreated:  true,
+	EventCommentRejected: true,
+}
+
+// RejectedComment — данные события comment.rejected. Текст отклоненного комментария получателям
+// не передается: он не публиковался и может содержать запрещенные слова или спам.
+type RejectedComment struct {
+	NewsID   int    `json:"news_id"`
+	ParentID *int   `json:"parent_id,omitempty"`
+	Author   string `json:"author,omitempty"`
+	// Reason — причина отказа: forbidden_words или spam_detected
+	Reason string `json:"reason"`
+}
+
+// maxDeliveriesPerWebhook — сколько последних доставок хранится в журнале для одного вебхука
+const maxDeliveriesPerWebhook = 100
+
+// Webhook — зарегистрированный получатель событий
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	NewsID    *int      `json:"news_id,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// subscribed — проверяет, нужно ли доставлять событие этому вебхуку
+func (h *Webhook) subscribed(event string, newsID int) bool {
+	if !h.Active {
+		return false
+	}
+	if h.NewsID != nil && *h.NewsID != newsID {
+		return false
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookRequest — тело запроса на создание или изменение вебхука
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	NewsID *int     `json:"news_id,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// validate — проверяет корректность параметров вебхука; получатель во внутренней сети допускается,
+// только если allowInternal
+func (req *WebhookRequest) validate(allowInternal bool) []httpx.FieldError {
+	var fields []httpx.FieldError
+	u, err := url.Parse(req.URL)
+	switch {
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "":
+		fields = append(fields, httpx.FieldError{Field: "url", Code: httpx.FieldInvalid, Message: "Expected absolute http or https URL"})
+	case !allowInternal && checkWebhookHost(u.Hostname()) != nil:
+		fields = append(fields, httpx.FieldError{Field: "url", Code: httpx.FieldInvalid, Message: "Webhook receiver must be a public host"})
+	}
+	if len(req.Events) == 0 {
+		fields = append(fields, httpx.FieldError{Field: "events", Code: httpx.FieldRequired, Message: "At least one event is required"})
+	}
+	for _, e := range req.Events {
+		if !knownEvents[e] {
+			fields = append(fields, httpx.FieldError{Field: "events", Code: httpx.FieldUnknown, Message: "Unknown event: " + e})
+		}
+	}
+	if req.NewsID != nil && *req.NewsID < 1 {
+		fields = append(fields, httpx.FieldError{Field: "news_id", Code: httpx.FieldInvalid, Message: "Invalid news_id"})
+	}
+	return fields
+}
+
+// WebhookEvent — полезная нагрузка, отправляемая получателю
+type WebhookEvent struct {
+	Event     string      `json:"event"`
+	NewsID    int         `json:"news_id,omitempty"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Delivery — запись журнала доставки события
+type Delivery struct {
+	ID          int             `json:"id"`
+	WebhookID   int             `json:"webhook_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	StatusCode  int             `json:"status_code,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Success     bool            `json:"success"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DeliveredAt *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// WebhookDispatcher — реестр вебхуков и доставщик событий с повторами.
+// Реестр и журнал доставок хранятся в памяти процесса: после перезапуска шлюза они пусты, а каждая реплика
+// знает только вебхуки, зарегистрированные через нее, — поэтому шлюз с вебхуками запускается в одном экземпляре.
+type WebhookDispatcher struct {
+	mu         sync.RWMutex
+	hooks      map[int]*Webhook
+	deliveries map[int][]*Delivery
+	nextHookID int
+	nextDelID  int
+
+	client      *http.Client
+	logger      zerolog.Logger
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	wg          sync.WaitGroup
+	// stop — отменяется при остановке шлюза и прерывает ожидание повторов
+	stop       context.Context
+	stopCancel context.CancelFunc
+
+	// allowInternalHosts — разрешить получателей во внутренней сети; только для тестов
+	allowInternalHosts bool
+}
+
+// NewWebhookDispatcher — создает реестр вебхуков
+func NewWebhookDispatcher(logger zerolog.Logger) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		hooks:       make(map[int]*Webhook),
+		deliveries:  make(map[int][]*Delivery),
+		logger:      logger,
+		maxAttempts: 5,
+		baseBackoff: time.Second,
+		maxBackoff:  time.Minute,
+	}
+	d.stop, d.stopCancel = context.WithCancel(context.Background())
+	d.client = newWebhookClient(func() bool { return d.allowInternalHosts })
+	return d
+}
+
+// Create — регистрирует новый вебхук
+func (d *WebhookDispatcher) Create(req WebhookRequest) *Webhook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nextHookID++
+	hook := &Webhook{
+		ID:        d.nextHookID,
+		URL:       req.URL,
+		Events:    req.Events,
+		NewsID:    req.NewsID,
+		Secret:    req.Secret,
+		Active:    req.Active == nil || *req.Active,
+		CreatedAt: time.Now(),
+	}
+	if hook.Secret == "" {
+		hook.Secret = generateSecret()
+	}
+	d.hooks[hook.ID] = hook
+
+	created := *hook
+	return &created
+}
+
+// List — возвращает все вебхуки без секретов
+func (d *WebhookDispatcher) List() []Webhook {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	hooks := make([]Webhook, 0, len(d.hooks))
+	for _, h := range d.hooks {
+		hook := *h
+		hook.Secret = ""
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+	return hooks
+}
+
+// Get — возвращает вебхук без секрета
+func (d *WebhookDispatcher) Get(id int) (*Webhook, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	h, ok := d.hooks[id]
+	if !ok {
+		return nil, false
+	}
+	hook := *h
+	hook.Secret = ""
+	return &hook, true
+}
+
+// Update — изменяет параметры вебхука
+func (d *WebhookDispatcher) Update(id int, req WebhookRequest) (*Webhook, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.hooks[id]
+	if !ok {
+		return nil, false
+	}
+	h.URL = req.URL
+	h.Events = req.Events
+	h.NewsID = req.NewsID
+	if req.Secret != "" {
+		h.Secret = req.Secret
+	}
+	if req.Active != nil {
+		h.Active = *req.Active
+	}
+
+	hook := *h
+	hook.Secret = ""
+	return &hook, true
+}
+
+// Delete — удаляет вебхук вместе с журналом доставок
+func (d *WebhookDispatcher) Delete(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.hooks[id]; !ok {
+		return false
+	}
+	delete(d.hooks, id)
+	delete(d.deliveries, id)
+	return true
+}
+
+// Deliveries — возвращает журнал доставок вебхука, начиная с последних
+func (d *WebhookDispatcher) Deliveries(id int) ([]Delivery, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if _, ok := d.hooks[id]; !ok {
+		return nil, false
+	}
+	list := d.deliveries[id]
+	result := make([]Delivery, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		result = append(result, *list[i])
+	}
+	return result, true
+}
+
+// Dispatch — асинхронно рассылает событие всем подписанным вебхукам
+func (d *WebhookDispatcher) Dispatch(event string, newsID int, data interface{}) {
+	payload, err := json.Marshal(WebhookEvent{
+		Event:     event,
+		NewsID:    newsID,
+		Data:      data,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		d.logger.Error().Err(err).Str("event", event).Msg("failed to marshal webhook event")
+		return
+	}
+
+	d.mu.Lock()
+	var pending []*Delivery
+	for _, h := range d.hooks {
+		if h.subscribed(event, newsID) {
+			pending = append(pending, d.newDelivery(h.ID, event, payload))
+		}
+	}
+	d.mu.Unlock()
+
+	for _, del := range pending {
+		d.start(del)
+	}
+}
+
+// Replay — повторно отправляет сохраненную доставку
+func (d *WebhookDispatcher) Replay(hookID, deliveryID int) (*Delivery, bool) {
+	d.mu.Lock()
+	if _, ok := d.hooks[hookID]; !ok {
+		d.mu.Unlock()
+		return nil, false
+	}
+	var original *Delivery
+	for _, del := range d.deliveries[hookID] {
+		if del.ID == deliveryID {
+			original = del
+			break
+		}
+	}
+	if original == nil {
+		d.mu.Unlock()
+		return nil, false
+	}
+	del := d.newDelivery(hookID, original.Event, original.Payload)
+	replayed := *del
+	d.mu.Unlock()
+
+	d.start(del)
+	return &replayed, true
+}
+
+// Wait — дожидается завершения всех запущенных доставок
+func (d *WebhookDispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Close — останавливает доставку при остановке шлюза: текущие попытки дожидаются ответа получателя
+// (не дольше таймаута клиента), а доставки, ожидающие повтора, прекращаются с последней ошибкой в журнале
+func (d *WebhookDispatcher) Close() error {
+	d.stopCancel()
+	d.wg.Wait()
+	return nil
+}
+
+// newDelivery — добавляет запись в журнал; вызывается под блокировкой
+func (d *WebhookDispatcher) newDelivery(hookID int, event string, payload []byte) *Delivery {
+	d.nextDelID++
+	del := &Delivery{
+		ID:        d.nextDelID,
+		WebhookID: hookID,
+		Event:     event,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+	list := append(d.deliveries[hookID], del)
+	if len(list) > maxDeliveriesPerWebhook {
+		list = list[len(list)-maxDeliveriesPerWebhook:]
+	}
+	d.deliveries[hookID] = list
+	return del
+}
+
+// start — запускает доставку в отдельной горутине
+func (d *WebhookDispatcher) start(del *Delivery) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(del)
+	}()
+}
+
+// deliver — отправляет событие с повторами и экспоненциальной задержкой
+func (d *WebhookDispatcher) deliver(del *Delivery) {
+	backoff := d.baseBackoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		d.mu.RLock()
+		h, ok := d.hooks[del.WebhookID]
+		var target, secret string
+		if ok {
+			target, secret = h.URL, h.Secret
+		}
+		d.mu.RUnlock()
+		if !ok {
+			return
+		}
+
+		statusCode, err := d.send(target, secret, del)
+
+		d.mu.Lock()
+		del.Attempts = attempt
+		del.StatusCode = statusCode
+		if err == nil {
+			now := time.Now()
+			del.Success = true
+			del.Error = ""
+			del.DeliveredAt = &now
+		} else {
+			del.Error = err.Error()
+		}
+		d.mu.Unlock()
+
+		if err == nil {
+			return
+		}
+		d.logger.Warn().Err(err).
+			Int("webhook_id", del.WebhookID).
+			Int("delivery_id", del.ID).
+			Int("attempt", attempt).
+			Msg("webhook delivery failed")
+
+		if attempt < d.maxAttempts {
+			if !d.sleep(backoff) {
+				d.logger.Warn().Int("webhook_id", del.WebhookID).Int("delivery_id", del.ID).Msg("webhook retries cancelled by shutdown")
+				return
+			}
+			backoff *= 2
+			if backoff > d.maxBackoff {
+				backoff = d.maxBackoff
+			}
+		}
+	}
+}
+
+// sleep — ждет перед повтором; false — шлюз останавливается и повторов не будет
+func (d *WebhookDispatcher) sleep(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.stop.Done():
+		return false
+	}
+}
+
+// send — выполняет одну попытку доставки
+func (d *WebhookDispatcher) send(target, secret string, del *Delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", del.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.Itoa(del.ID))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(secret, timestamp, del.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload — вычисляет HMAC-SHA256 подпись от "timestamp.payload"
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateSecret — генерирует случайный секрет для подписи
+func generateSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// webhookID — извлекает и проверяет ID вебхука из URL
+func webhookID(r *http.Request, param string) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, param))
+	return id, err == nil && id >= 1
+}
+
+// CreateWebhook — регистрация вебхука
+func (a *App) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if fields := req.validate(a.webhooks.allowInternalHosts); len(fields) > 0 {
+		httpx.SendValidationError(w, r, fields...)
+		return
+	}
+
+	// Секрет возвращается только при создании
+	httpx.SendResponse(w, http.StatusCreated, a.webhooks.Create(req))
+}
+
+// ListWebhooks — список зарегистрированных вебхуков
+func (a *App) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	httpx.SendResponse(w, http.StatusOK, a.webhooks.List())
+}
+
+// GetWebhook — получение вебхука по ID
+func (a *App) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(r, "id")
+	if !ok {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid webhook ID"})
+		return
+	}
+	hook, ok := a.webhooks.Get(id)
+	if !ok {
+		httpx.SendError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Webhook not found")
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, hook)
+}
+
+// UpdateWebhook — изменение вебхука
+func (a *App) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(r, "id")
+	if !ok {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid webhook ID"})
+		return
+	}
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if fields := req.validate(a.webhooks.allowInternalHosts); len(fields) > 0 {
+		httpx.SendValidationError(w, r, fields...)
+		return
+	}
+	hook, ok := a.webhooks.Update(id, req)
+	if !ok {
+		httpx.SendError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Webhook not found")
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, hook)
+}
+
+// DeleteWebhook — удаление вебхука
+func (a *App) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(r, "id")
+	if !ok {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid webhook ID"})
+		return
+	}
+	if !a.webhooks.Delete(id) {
+		httpx.SendError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Webhook not found")
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, "Webhook deleted")
+}
+
+// ListDeliveries — журнал доставок вебхука
+func (a *App) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(r, "id")
+	if !ok {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid webhook ID"})
+		return
+	}
+	deliveries, ok := a.webhooks.Deliveries(id)
+	if !ok {
+		httpx.SendError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Webhook not found")
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, deliveries)
+}
+
+// ReplayDelivery — повторная отправка доставки из журнала
+func (a *App) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(r, "id")
+	if !ok {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid webhook ID"})
+		return
+	}
+	deliveryID, ok := webhookID(r, "deliveryID")
+	if !ok {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "deliveryID", Code: httpx.FieldInvalid, Message: "Invalid delivery ID"})
+		return
+	}
+	del, ok := a.webhooks.Replay(id, deliveryID)
+	if !ok {
+		httpx.SendError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Delivery not found")
+		return
+	}
+	httpx.SendResponse(w, http.StatusAccepted, del)
+}
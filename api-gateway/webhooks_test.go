@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookReceiver — тестовый получатель, который отвечает ошибкой первые failures раз
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.calls++
+	rcv.bodies = append(rcv.bodies, body)
+	rcv.headers = append(rcv.headers, r.Header.Clone())
+	if rcv.calls <= rcv.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// testModeratorToken — токен модератора в приложении из newTestApp
+const testModeratorToken = "secret"
+
+func newTestApp() *App {
+	cfg := DefaultConfig()
+	cfg.ModeratorToken = testModeratorToken
+	app := NewApp(cfg)
+	app.webhooks.baseBackoff = time.Millisecond
+	app.webhooks.maxBackoff = 5 * time.Millisecond
+	// Тестовые получатели слушают 127.0.0.1
+	app.webhooks.allowInternalHosts = true
+	return app
+}
+
+// moderatorRequest — запрос с токеном модератора
+func moderatorRequest(method, path, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testModeratorToken)
+	return req
+}
+
+func registerWebhook(t *testing.T, app *App, body string) Webhook {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest(http.MethodPost, "/api/v1/webhooks", body))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data Webhook `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Data
+}
+
+func TestWebhookSignedDeliveryWithRetries(t *testing.T) {
+	rcv := &webhookReceiver{failures: 2}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	app := newTestApp()
+	hook := registerWebhook(t, app, fmt.Sprintf(`{"url":%q,"events":["comment.created"],"secret":"s3cret"}`, srv.URL))
+	if hook.Secret != "s3cret" {
+		t.Errorf("Секрет должен возвращаться при создании")
+	}
+
+	app.webhooks.Dispatch(EventCommentCreated, 1, map[string]int{"id": 7})
+	app.webhooks.Dispatch(EventCommentRejected, 1, map[string]int{"id": 8})
+	app.webhooks.Wait()
+
+	if rcv.calls != 3 {
+		t.Fatalf("Ожидалось 3 попытки доставки, получено %d", rcv.calls)
+	}
+
+	last := rcv.headers[2]
+	if last.Get("X-Webhook-Event") != EventCommentCreated {
+		t.Errorf("Неверное событие: %s", last.Get("X-Webhook-Event"))
+	}
+	want := "sha256=" + SignWebhookPayload("s3cret", last.Get("X-Webhook-Timestamp"), rcv.bodies[2])
+	if last.Get("X-Webhook-Signature") != want {
+		t.Errorf("Неверная подпись: %s", last.Get("X-Webhook-Signature"))
+	}
+
+	deliveries, _ := app.webhooks.Deliveries(hook.ID)
+	if len(deliveries) != 1 || !deliveries[0].Success || deliveries[0].Attempts != 3 {
+		t.Errorf("Неверный журнал доставок: %+v", deliveries)
+	}
+}
+
+func TestWebhookNewsFilterAndReplay(t *testing.T) {
+	rcv := &webhookReceiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	app := newTestApp()
+	hook := registerWebhook(t, app, fmt.Sprintf(`{"url":%q,"events":["comment.created"],"news_id":2}`, srv.URL))
+
+	app.webhooks.Dispatch(EventCommentCreated, 1, nil)
+	app.webhooks.Dispatch(EventCommentCreated, 2, nil)
+	app.webhooks.Wait()
+	if rcv.calls != 1 {
+		t.Fatalf("Ожидалась 1 доставка, получено %d", rcv.calls)
+	}
+
+	deliveries, _ := app.webhooks.Deliveries(hook.ID)
+	path := fmt.Sprintf("/api/v1/webhooks/%d/deliveries/%d/replay", hook.ID, deliveries[0].ID)
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, moderatorRequest(http.MethodPost, path, ""))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusAccepted, rr.Code)
+	}
+	app.webhooks.Wait()
+
+	if rcv.calls != 2 || string(rcv.bodies[0]) != string(rcv.bodies[1]) {
+		t.Errorf("Повторная доставка должна отправить ту же полезную нагрузку")
+	}
+}
+
+func TestWebhookValidation(t *testing.T) {
+	app := newTestApp()
+	app.webhooks.allowInternalHosts = false
+	for _, body := range []string{
+		`{"url":"ftp://example.com","events":["comment.created"]}`,
+		`{"url":"http://example.com","events":[]}`,
+		`{"url":"http://example.com","events":["news.deleted"]}`,
+		`{"url":"http://comment-service:8081/comments","events":["comment.created"]}`,
+		`{"url":"http://169.254.169.254/latest/meta-data","events":["comment.created"]}`,
+		`{"url":"http://[::1]:8080/","events":["comment.created"]}`,
+		`{"url":"https://10.0.0.5/hook","events":["comment.created"]}`,
+	} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, moderatorRequest(http.MethodPost, "/api/v1/webhooks", body))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: ожидался статус %d, получен %d", body, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestWebhooksRequireModerator(t *testing.T) {
+	app := newTestApp()
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url":"https://example.com","events":["comment.created"]}`)),
+		httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/1/deliveries", nil),
+		httptest.NewRequest(http.MethodPost, "/webhooks/1/deliveries/1/replay", nil),
+	} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: ожидался статус %d, получен %d", req.Method, req.URL.Path, http.StatusUnauthorized, rr.Code)
+		}
+	}
+	if hooks := app.webhooks.List(); len(hooks) != 0 {
+		t.Errorf("Вебхук не должен регистрироваться без токена модератора: %+v", hooks)
+	}
+}
+
+func TestWebhookDeliveryRejectsInternalAddress(t *testing.T) {
+	rcv := &webhookReceiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	app := newTestApp()
+	app.webhooks.maxAttempts = 1
+	// Вебхук с внутренним адресом мог появиться до проверки при регистрации или через DNS публичного имени
+	hook := app.webhooks.Create(WebhookRequest{URL: srv.URL, Events: []string{EventCommentCreated}})
+	app.webhooks.allowInternalHosts = false
+
+	app.webhooks.Dispatch(EventCommentCreated, 1, nil)
+	app.webhooks.Wait()
+
+	deliveries, _ := app.webhooks.Deliveries(hook.ID)
+	if rcv.calls != 0 || len(deliveries) != 1 || deliveries[0].Success || !strings.Contains(deliveries[0].Error, errInternalHost.Error()) {
+		t.Errorf("Доставка во внутреннюю сеть должна блокироваться при подключении: %d вызовов, %+v", rcv.calls, deliveries)
+	}
+}
+
+func TestWebhookCloseWaitsForDeliveryAndCancelsRetries(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer slow.Close()
+	failing := &webhookReceiver{failures: 100}
+	failingSrv := httptest.NewServer(failing)
+	defer failingSrv.Close()
+
+	app := newTestApp()
+	app.webhooks.baseBackoff = time.Hour
+	app.webhooks.maxBackoff = time.Hour
+	inFlight := app.webhooks.Create(WebhookRequest{URL: slow.URL, Events: []string{EventCommentCreated}})
+	retrying := app.webhooks.Create(WebhookRequest{URL: failingSrv.URL, Events: []string{EventCommentCreated}})
+	app.webhooks.Dispatch(EventCommentCreated, 1, nil)
+
+	// Первая попытка к failingSrv сделана, доставка ждет повтора через час
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if deliveries, _ := app.webhooks.Deliveries(retrying.ID); deliveries[0].Attempts == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Первая попытка доставки не выполнена")
+		}
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		app.webhooks.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close должен дождаться текущей попытки доставки")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close не должен ждать повторов доставки")
+	}
+
+	if deliveries, _ := app.webhooks.Deliveries(inFlight.ID); !deliveries[0].Success {
+		t.Errorf("Текущая доставка должна завершиться при остановке: %+v", deliveries[0])
+	}
+	if deliveries, _ := app.webhooks.Deliveries(retrying.ID); deliveries[0].Success || deliveries[0].Attempts != 1 || failing.calls != 1 {
+		t.Errorf("Повторы должны прекращаться при остановке: %+v, вызовов %d", deliveries[0], failing.calls)
+	}
+}
+
+func TestWebhookRegistryIsPerInstance(t *testing.T) {
+	// Вебхуки хранятся в памяти реплики: другая реплика (или шлюз после перезапуска) их не знает
+	first, second := newTestApp(), newTestApp()
+	registerWebhook(t, first, `{"url":"https://example.com/hook","events":["comment.created"]}`)
+	if len(first.webhooks.List()) != 1 || len(second.webhooks.List()) != 0 {
+		t.Errorf("Реестр вебхуков должен быть у каждой реплики свой: %+v / %+v", first.webhooks.List(), second.webhooks.List())
+	}
+}
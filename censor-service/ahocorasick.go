@@ -0,0 +1,163 @@
+package main
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ahoCorasick — автомат Ахо — Корасик по байтам UTF-8 для поиска всех терминов словаря за один
+// проход по тексту: время проверки зависит от длины текста и числа совпадений, но не от размера словаря.
+// Термины и текст приводятся к нижнему регистру посимвольно, поэтому позиции совпадений
+// в приведенном тексте соответствуют позициям в исходном.
+type ahoCorasick struct {
+	nodes []acNode
+	// root — переходы из корня; корень ветвится сильнее всего, поэтому хранится таблицей
+	root [256]int32
+	// patternRunes — длина каждого термина в символах
+	patternRunes []int
+}
+
+type acNode struct {
+	edges []acEdge // отсортированы по байту
+	fail  int32    // самый длинный собственный суффикс, который есть в автомате
+	// pattern — термин, заканчивающийся в этом узле, или -1;
+	// output — ближайший по ссылкам fail узел, где заканчивается термин, или -1
+	pattern int32
+	output  int32
+}
+
+type acEdge struct {
+	b    byte
+	next int32
+}
+
+// foldRune — приведение символа к нижнему регистру для поиска без учета регистра
+func foldRune(r rune) rune {
+	return unicode.ToLower(r)
+}
+
+func foldString(s string) string {
+	buf := make([]byte, 0, len(s))
+	for _, r := range s {
+		buf = utf8.AppendRune(buf, foldRune(r))
+	}
+	return string(buf)
+}
+
+// newAhoCorasick — строит автомат; номер термина — его индекс в patterns. Пустые термины пропускаются.
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	ac := &ahoCorasick{nodes: []acNode{{pattern: -1, output: -1}}, patternRunes: make([]int, len(patterns))}
+	for i, p := range patterns {
+		p = foldString(p)
+		ac.patternRunes[i] = utf8.RuneCountInString(p)
+		if p == "" {
+			continue
+		}
+		node := int32(0)
+		for j := 0; j < len(p); j++ {
+			node = ac.addEdge(node, p[j])
+		}
+		ac.nodes[node].pattern = int32(i)
+	}
+	for b := range ac.root {
+		ac.root[b] = max(ac.child(0, byte(b)), 0)
+	}
+
+	// Ссылки fail строятся обходом в ширину: у узла на глубине d ссылка ведет на меньшую глубину
+	queue := make([]int32, 0, len(ac.nodes))
+	for _, e := range ac.nodes[0].edges {
+		queue = append(queue, e.next)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, e := range ac.nodes[node].edges {
+			fail := ac.nodes[node].fail
+			for fail != 0 && ac.child(fail, e.b) == -1 {
+				fail = ac.nodes[fail].fail
+			}
+			if next := ac.child(fail, e.b); next != -1 {
+				ac.nodes[e.next].fail = next
+			}
+			f := ac.nodes[e.next].fail
+			if ac.nodes[f].pattern != -1 {
+				ac.nodes[e.next].output = f
+			} else {
+				ac.nodes[e.next].output = ac.nodes[f].output
+			}
+			queue = append(queue, e.next)
+		}
+	}
+	return ac
+}
+
+func (ac *ahoCorasick) addEdge(node int32, b byte) int32 {
+	if next := ac.child(node, b); next != -1 {
+		return next
+	}
+	next := int32(len(ac.nodes))
+	ac.nodes = append(ac.nodes, acNode{pattern: -1, output: -1})
+	edges := ac.nodes[node].edges
+	i := sort.Search(len(edges), func(i int) bool { return edges[i].b >= b })
+	edges = append(edges, acEdge{})
+	copy(edges[i+1:], edges[i:])
+	edges[i] = acEdge{b: b, next: next}
+	ac.nodes[node].edges = edges
+	return next
+}
+
+// child — переход по байту без учета ссылок fail; -1, если перехода нет
+func (ac *ahoCorasick) child(node int32, b byte) int32 {
+	edges := ac.nodes[node].edges
+	// У большинства узлов один-два перехода: линейный поиск для них быстрее двоичного
+	if len(edges) <= 8 {
+		for _, e := range edges {
+			if e.b == b {
+				return e.next
+			}
+		}
+		return -1
+	}
+	i := sort.Search(len(edges), func(i int) bool { return edges[i].b >= b })
+	if i < len(edges) && edges[i].b == b {
+		return edges[i].next
+	}
+	return -1
+}
+
+func (ac *ahoCorasick) step(node int32, b byte) int32 {
+	for node != 0 {
+		if next := ac.child(node, b); next != -1 {
+			return next
+		}
+		node = ac.nodes[node].fail
+	}
+	return ac.root[b]
+}
+
+// scan — вызывает found для каждого вхождения термина: номер термина и границы совпадения
+// в байтах исходного текста. Вхождения сообщаются в порядке их окончания.
+func (ac *ahoCorasick) scan(text string, found func(pattern, start, end int)) {
+	if len(ac.nodes) == 1 {
+		return
+	}
+	// starts — начала последних символов текста, чтобы по длине термина найти начало совпадения
+	var starts []int
+	node := int32(0)
+	var buf [utf8.UTFMax]byte
+	for pos := 0; pos < len(text); {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		starts = append(starts, pos)
+		n := utf8.EncodeRune(buf[:], foldRune(r))
+		for _, b := range buf[:n] {
+			node = ac.step(node, b)
+		}
+		pos += size
+		for out := node; out != -1; out = ac.nodes[out].output {
+			if p := ac.nodes[out].pattern; p != -1 {
+				found(int(p), starts[len(starts)-ac.patternRunes[p]], pos)
+			}
+		}
+	}
+}
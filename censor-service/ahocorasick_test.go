@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type acMatch struct {
+	pattern    int
+	start, end int
+}
+
+func scanAll(ac *ahoCorasick, text string) []acMatch {
+	var found []acMatch
+	ac.scan(text, func(pattern, start, end int) {
+		found = append(found, acMatch{pattern, start, end})
+	})
+	return found
+}
+
+func TestAhoCorasickOverlapping(t *testing.T) {
+	ac := newAhoCorasick([]string{"he", "she", "his", "hers", ""})
+	found := scanAll(ac, "ushers")
+	want := []acMatch{{1, 1, 4}, {0, 2, 4}, {3, 2, 6}}
+	if len(found) != len(want) {
+		t.Fatalf("Ожидались совпадения %v, получено %v", want, found)
+	}
+	for i := range want {
+		if found[i] != want[i] {
+			t.Errorf("Ожидались совпадения %v, получено %v", want, found)
+			break
+		}
+	}
+}
+
+func TestAhoCorasickCaseAndPositions(t *testing.T) {
+	ac := newAhoCorasick([]string{"Спам", "ёж"})
+	text := "Это СПАМ, а не Ёж"
+	found := scanAll(ac, text)
+	if len(found) != 2 {
+		t.Fatalf("Ожидалось 2 совпадения без учета регистра, получено %v", found)
+	}
+	if got := text[found[0].start:found[0].end]; got != "СПАМ" {
+		t.Errorf("Границы совпадения должны указывать на исходный текст, получено %q", got)
+	}
+	if got := text[found[1].start:found[1].end]; got != "Ёж" {
+		t.Errorf("Границы совпадения должны указывать на исходный текст, получено %q", got)
+	}
+	if found := scanAll(ac, "спа\xffм"); len(found) != 0 {
+		t.Errorf("Некорректный UTF-8 не должен давать ложных совпадений: %v", found)
+	}
+}
+
+// TestAhoCorasickMatchesNaive — сверка автомата с простым поиском подстрок на случайных словарях
+func TestAhoCorasickMatchesNaive(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	alphabet := []rune("абвАБВab")
+	randomString := func(n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteRune(alphabet[rnd.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+
+	for iter := 0; iter < 200; iter++ {
+		patterns := make([]string, 1+rnd.Intn(20))
+		for i := range patterns {
+			patterns[i] = randomString(1 + rnd.Intn(4))
+		}
+		text := randomString(rnd.Intn(60))
+		ac := newAhoCorasick(patterns)
+
+		var got []acMatch
+		for _, m := range scanAll(ac, text) {
+			got = append(got, acMatch{pattern: m.pattern, start: m.start})
+		}
+		// Одинаковые термины автомат сообщает один раз, под номером последнего из них
+		last := make(map[string]int)
+		for i, p := range patterns {
+			last[foldString(p)] = i
+		}
+		var want []acMatch
+		lower := foldString(text)
+		for i, p := range patterns {
+			p = foldString(p)
+			if last[p] != i {
+				continue
+			}
+			for start := 0; start+len(p) <= len(lower); start++ {
+				if lower[start:start+len(p)] == p {
+					want = append(want, acMatch{pattern: i, start: start})
+				}
+			}
+		}
+		sortMatches := func(ms []acMatch) {
+			sort.Slice(ms, func(i, j int) bool {
+				if ms[i].start != ms[j].start {
+					return ms[i].start < ms[j].start
+				}
+				return ms[i].pattern < ms[j].pattern
+			})
+		}
+		sortMatches(got)
+		sortMatches(want)
+		if len(got) != len(want) {
+			t.Fatalf("%q в %q: ожидалось %v, получено %v", patterns, text, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%q в %q: ожидалось %v, получено %v", patterns, text, want, got)
+			}
+		}
+	}
+}
+
+// benchmarkDictionary — словарь из n случайных терминов по 4–10 букв и комментарий из 1000 символов
+func benchmarkDictionary(n int) ([]Rule, string) {
+	rnd := rand.New(rand.NewSource(42))
+	letters := []rune("абвгдеёжзийклмнопрстуфхцчшщъыьэюяabcdefghijklmnopqrstuvwxyz")
+	word := func(length int) string {
+		var sb strings.Builder
+		for i := 0; i < length; i++ {
+			sb.WriteRune(letters[rnd.Intn(len(letters))])
+		}
+		return sb.String()
+	}
+
+	rules := make([]Rule, n)
+	for i := range rules {
+		rules[i] = Rule{ID: i + 1, Pattern: word(4 + rnd.Intn(7)), Kind: RuleTerm, Action: ActionBlock, Enabled: true}
+	}
+	var sb strings.Builder
+	for sb.Len() == 0 || len([]rune(sb.String())) < 1000 {
+		sb.WriteString(word(2+rnd.Intn(8)) + " ")
+	}
+	text := string([]rune(sb.String())[:1000])
+	return rules, text
+}
+
+func BenchmarkNewMatcher50k(b *testing.B) {
+	rules, _ := benchmarkDictionary(50000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewMatcher(1, rules); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMatcher50kTerms1000Chars(b *testing.B) {
+	rules, text := benchmarkDictionary(50000)
+	m, err := NewMatcher(1, rules)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Match(text, Scope{})
+	}
+}
+
+// BenchmarkContains50kTerms1000Chars — прежний поиск strings.Contains по каждому термину для сравнения
+func BenchmarkContains50kTerms1000Chars(b *testing.B) {
+	rules, text := benchmarkDictionary(50000)
+	terms := make([]string, len(rules))
+	for i, r := range rules {
+		terms[i] = strings.ToLower(r.Pattern)
+	}
+	b.SetBytes(int64(len(text)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lower := strings.ToLower(text)
+		for _, term := range terms {
+			strings.Contains(lower, term)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"pkg/httpx"
+)
+
+// ndjsonContentType — поток JSON-объектов, по одному на строку
+const ndjsonContentType = "application/x-ndjson"
+
+// versionHeader — версия словаря, по которой проверен пакет
+const versionHeader = "X-Dictionary-Version"
+
+// maxNDJSONLine — ограничение длины одной строки потока NDJSON
+const maxNDJSONLine = 1 << 20
+
+// BatchItem — текст в пакетной проверке; ID — идентификатор вызывающей стороны, например комментария.
+// Language и Policy — как в CheckRequest.
+type BatchItem struct {
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	Author   string `json:"author,omitempty"`
+	Language string `json:"language,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+}
+
+// BatchResult — решение по одному тексту пакета. Пакетная проверка применяет только словарь:
+// правила спама учитывают историю отправок, и повторная проверка старых текстов исказила бы ее.
+type BatchResult struct {
+	ID      int         `json:"id"`
+	Verdict string      `json:"verdict,omitempty"`
+	Matches []RuleMatch `json:"matches,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// checkBatchItem — решение по правилам словаря для языка и политики текста: block отклоняет текст,
+// queue отправляет на модерацию. Для неизвестной политики возвращается ошибка invalid_item.
+func (a *App) checkBatchItem(m *Matcher, item BatchItem) BatchResult {
+	scope, problem := a.scope(item.Text, item.Language, item.Policy)
+	if problem != nil {
+		return BatchResult{ID: item.ID, Error: "invalid_item"}
+	}
+	result := BatchResult{ID: item.ID, Verdict: VerdictAccept, Matches: a.match(m, item.Text, scope)}
+	for _, match := range result.Matches {
+		switch match.Action {
+		case ActionBlock:
+			result.Verdict = VerdictReject
+		case ActionQueue:
+			if result.Verdict == VerdictAccept {
+				result.Verdict = VerdictQueue
+			}
+		}
+	}
+	return result
+}
+
+// CheckBatch — пакетная проверка по текущей версии словаря: POST /check/batch.
+// Тело — JSON-массив BatchItem (не больше batch_max_items) или поток NDJSON; ответ приходит в том же формате,
+// в порядке элементов запроса. Весь пакет проверяется одной версией словаря, она возвращается в X-Dictionary-Version.
+func (a *App) CheckBatch(w http.ResponseWriter, r *http.Request) {
+	matcher := a.matcher.Load()
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == ndjsonContentType {
+		a.checkBatchStream(w, r, matcher)
+		return
+	}
+
+	var items []BatchItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body, expected a JSON array of {id, text}")
+		return
+	}
+	if len(items) > a.config.BatchMaxItems {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "items", Code: httpx.FieldTooLong, Message: "Batch must contain at most " + strconv.Itoa(a.config.BatchMaxItems) + " items, use NDJSON for larger batches"})
+		return
+	}
+	results := make([]BatchResult, len(items))
+	for i, item := range items {
+		results[i] = a.checkBatchItem(matcher, item)
+	}
+	w.Header().Set(versionHeader, strconv.FormatInt(matcher.Version, 10))
+	httpx.SendResponse(w, http.StatusOK, results)
+}
+
+// checkBatchStream — NDJSON: элементы проверяются по мере чтения, и ответ отправляется строками без накопления.
+// Некорректная строка не прерывает поток: для нее возвращается результат с ошибкой invalid_item.
+// Ответ пишется до конца чтения тела, поэтому нужен полнодуплексный режим: без него HTTP/1.1-сервер
+// после первого Flush перестает читать запрос и поток обрывается.
+func (a *App) checkBatchStream(w http.ResponseWriter, r *http.Request, matcher *Matcher) {
+	rc := http.NewResponseController(w)
+	if err := rc.EnableFullDuplex(); err != nil {
+		httpx.SendError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Streaming is not supported by the server")
+		return
+	}
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set(versionHeader, strconv.FormatInt(matcher.Version, 10))
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	for line := 0; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item BatchItem
+		result := BatchResult{}
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			result.Error = "invalid_item"
+		} else {
+			result = a.checkBatchItem(matcher, item)
+		}
+		if err := enc.Encode(result); err != nil {
+			return
+		}
+		// Клиент получает результаты частями, не дожидаясь конца потока
+		if line%100 == 99 {
+			rc.Flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		enc.Encode(BatchResult{Error: "invalid_stream"})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCheckBatchJSON(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"реклама","action":"queue"}`)
+
+	body := `[{"id":1,"text":"Хорошая новость"},{"id":2,"text":"Это QWERTY"},{"id":3,"text":"Реклама тут"}]`
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check/batch", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if v := rr.Header().Get(versionHeader); v != strconv.FormatInt(app.matcher.Load().Version, 10) {
+		t.Errorf("Ответ должен содержать версию словаря, получено %q", v)
+	}
+	results := decodeData[[]BatchResult](t, rr)
+	want := []string{VerdictAccept, VerdictReject, VerdictQueue}
+	if len(results) != len(want) {
+		t.Fatalf("Ожидалось %d результатов, получено %+v", len(want), results)
+	}
+	for i, verdict := range want {
+		if results[i].ID != i+1 || results[i].Verdict != verdict {
+			t.Errorf("Элемент %d: ожидалось решение %s, получено %+v", i+1, verdict, results[i])
+		}
+	}
+}
+
+func TestCheckBatchTooLarge(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.BatchMaxItems = 2
+	app := newTestApp(t, cfg)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check/batch", strings.NewReader(`[{"id":1},{"id":2},{"id":3}]`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Слишком большой пакет: ожидался статус %d, получен %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCheckBatchNDJSON(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.BatchMaxItems = 1
+	app := newTestApp(t, cfg)
+	// Настоящий сервер: ответ сбрасывается клиенту, пока тело запроса еще читается
+	srv := httptest.NewServer(app.router)
+	defer srv.Close()
+
+	const items = 5000
+	var body strings.Builder
+	body.WriteString("{\"id\":0,\"text\":\"йцукен\"}\n\nне json\n")
+	for i := 1; i <= items; i++ {
+		fmt.Fprintf(&body, "{\"id\":%d,\"text\":\"обычный текст %d\"}\n", i, i)
+	}
+	resp, err := http.Post(srv.URL+"/check/batch", ndjsonContentType, strings.NewReader(body.String()))
+	if err != nil {
+		t.Fatalf("Запрос не выполнен: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != ndjsonContentType {
+		t.Fatalf("Ожидался поток NDJSON, получено %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var results []BatchResult
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var res BatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			t.Fatalf("Строка ответа должна быть JSON: %q", scanner.Text())
+		}
+		results = append(results, res)
+	}
+	// Ограничение batch_max_items к потоку не применяется, пустые строки пропускаются
+	if len(results) != items+2 {
+		t.Fatalf("Ожидалось %d результатов, получено %d, последний %+v", items+2, len(results), results[len(results)-1])
+	}
+	if results[0].ID != 0 || results[0].Verdict != VerdictReject {
+		t.Errorf("Запрещенное слово должно отклоняться: %+v", results[0])
+	}
+	if results[1].Error != "invalid_item" {
+		t.Errorf("Некорректная строка должна давать ошибку, не прерывая поток: %+v", results[1])
+	}
+	for i, res := range results[2:] {
+		if res.ID != i+1 || res.Verdict != VerdictAccept || res.Error != "" {
+			t.Fatalf("Элемент %d: ожидалось решение %s, получено %+v", i+1, VerdictAccept, res)
+		}
+	}
+}
+
+func TestRulesVersion(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	before := decodeData[map[string]int64](t, doRulesRequest(t, app, http.MethodGet, "/rules/version", ""))["version"]
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"новое"}`)
+	after := decodeData[map[string]int64](t, doRulesRequest(t, app, http.MethodGet, "/rules/version", ""))["version"]
+	if before == 0 || after <= before {
+		t.Errorf("Версия словаря должна расти при изменениях: %d → %d", before, after)
+	}
+}
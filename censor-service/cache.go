@@ -0,0 +1,150 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// decisionCache — решения словаря по недавно проверенным текстам. Ключ — хэш версии словаря, области проверки
+// и нормализованного текста (без учета регистра и повторов пробелов), поэтому изменение правил делает все
+// записи недействительными: при первом обращении с новой версией кэш очищается.
+//
+// Сработавшие правила с фрагментами возвращаются только для точно такого же текста, у вариантов текста
+// смещения фрагментов другие. Если среди правил есть block и все они не зависят от регистра и пробелов
+// (Matcher.Invariant), для вариантов запрещенного текста возвращаются сработавшие правила без фрагментов.
+//
+// Оценка спама в кэш не попадает: правила повторов и частоты должны учитывать каждую отправку.
+type decisionCache struct {
+	mu      sync.Mutex
+	size    int
+	version int64
+	entries map[[sha256.Size]byte]*list.Element
+	lru     *list.List // от недавно использованных к давним
+}
+
+type cacheEntry struct {
+	key     [sha256.Size]byte
+	text    string
+	matches []RuleMatch
+	// blocked — сработавшие правила без фрагментов, если среди них есть правило block
+	// и решение не зависит от регистра и пробелов
+	blocked []RuleMatch
+}
+
+// newDecisionCache — кэш на size текстов; при size = 0 кэш выключен
+func newDecisionCache(size int) *decisionCache {
+	return &decisionCache{size: size, entries: make(map[[sha256.Size]byte]*list.Element), lru: list.New()}
+}
+
+// get — сработавшие правила для текста по версии словаря version; ok = false, если решения нет.
+// Возвращаемый срез общий для всех обращений и не должен изменяться.
+func (c *decisionCache) get(version int64, scope Scope, text string) (matches []RuleMatch, ok bool) {
+	if c.size == 0 {
+		return nil, false
+	}
+	key := cacheKey(version, scope, text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version != c.version {
+		c.reset(version)
+		return nil, false
+	}
+	el, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	switch {
+	case entry.text == text:
+		matches = entry.matches
+	case entry.blocked != nil:
+		matches = entry.blocked
+	default:
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return matches, true
+}
+
+// put — запоминает сработавшие правила; invariant — они будут теми же для вариантов текста
+// (см. Matcher.Invariant). Решения устаревшей версии словаря не сохраняются.
+func (c *decisionCache) put(version int64, scope Scope, text string, matches []RuleMatch, invariant bool) {
+	if c.size == 0 {
+		return
+	}
+	entry := &cacheEntry{key: cacheKey(version, scope, text), text: text, matches: matches}
+	if invariant && slices.ContainsFunc(matches, func(m RuleMatch) bool { return m.Action == ActionBlock }) {
+		entry.blocked = make([]RuleMatch, len(matches))
+		for i, m := range matches {
+			m.Spans = nil
+			entry.blocked[i] = m
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version < c.version {
+		return
+	}
+	if version > c.version {
+		c.reset(version)
+	}
+	if el, found := c.entries[entry.key]; found {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// reset — очищает кэш при смене версии словаря; вызывается под mu.
+// Проверка по старому словарю, начатая до перезагрузки, не вернет версию кэша назад.
+func (c *decisionCache) reset(version int64) {
+	if version < c.version {
+		return
+	}
+	c.version = version
+	clear(c.entries)
+	c.lru.Init()
+}
+
+// len — число запомненных текстов
+func (c *decisionCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func cacheKey(version int64, scope Scope, text string) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(version)))
+	h.Write([]byte(scope.Language + "\x00" + scope.Policy + "\x00"))
+	h.Write([]byte(normalizeText(text)))
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// normalizeText — текст без учета регистра, с пробелами, сжатыми до одного
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(foldString(text)), " ")
+}
+
+// match — сработавшие правила словаря m для текста: из кэша или поиском с сохранением в кэш
+func (a *App) match(m *Matcher, text string, scope Scope) []RuleMatch {
+	if matches, ok := a.cache.get(m.Version, scope, text); ok {
+		return matches
+	}
+	matches := m.Match(text, scope)
+	a.cache.put(m.Version, scope, text, matches, m.Invariant(matches))
+	return matches
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDecisionCache(t *testing.T) {
+	c := newDecisionCache(2)
+	scope := Scope{Language: LanguageRU}
+	masked := []RuleMatch{{RuleID: 1, Action: ActionMask, Spans: []Span{{0, 8}}}}
+	blocked := []RuleMatch{{RuleID: 2, Action: ActionBlock, Spans: []Span{{5, 13}}}}
+	c.put(1, scope, "спам тут", masked, true)
+	c.put(1, scope, "Это казино", blocked, true)
+
+	if got, ok := c.get(1, scope, "спам тут"); !ok || len(got) != 1 || len(got[0].Spans) != 1 {
+		t.Errorf("Точно такой же текст должен браться из кэша: %+v %v", got, ok)
+	}
+	if _, ok := c.get(1, scope, "СПАМ   тут"); ok {
+		t.Error("Для варианта текста без отказа фрагменты другие, кэш не должен срабатывать")
+	}
+	if got, ok := c.get(1, scope, "это  КАЗИНО "); !ok || got[0].Action != ActionBlock || got[0].Spans != nil {
+		t.Errorf("Вариант запрещенного текста должен отклоняться без фрагментов: %+v %v", got, ok)
+	}
+	// Регулярные выражения и термины с пробелами зависят от пробелов: вариант текста проверяется заново
+	c.put(1, scope, "Это рулетка", []RuleMatch{{RuleID: 3, Action: ActionBlock, Spans: []Span{{7, 21}}}}, false)
+	if _, ok := c.get(1, scope, "это  рулетка"); ok {
+		t.Error("Вариант текста с правилом, зависящим от пробелов, не должен браться из кэша")
+	}
+	if _, ok := c.get(1, Scope{Language: LanguageRU, Policy: "sport"}, "спам тут"); ok {
+		t.Error("Решение другой политики не должно браться из кэша")
+	}
+
+	// Вытесняется давно не использованный текст
+	c.put(1, scope, "третий", nil, true)
+	if _, ok := c.get(1, scope, "спам тут"); ok {
+		t.Error("Давно не использованный текст должен вытесняться")
+	}
+	if _, ok := c.get(1, scope, "третий"); !ok {
+		t.Error("Новый текст должен быть в кэше")
+	}
+
+	// Новая версия словаря очищает кэш, решения старой версии не сохраняются
+	if _, ok := c.get(2, scope, "третий"); ok || c.len() != 0 {
+		t.Errorf("Новая версия словаря должна очищать кэш, записей: %d", c.len())
+	}
+	c.put(1, scope, "третий", nil, true)
+	if c.len() != 0 {
+		t.Error("Решение по устаревшей версии словаря не должно сохраняться")
+	}
+}
+
+func TestDecisionCacheDisabled(t *testing.T) {
+	c := newDecisionCache(0)
+	c.put(1, Scope{}, "текст", nil, true)
+	if _, ok := c.get(1, Scope{}, "текст"); ok || c.len() != 0 {
+		t.Error("При нулевом размере кэш не должен хранить решения")
+	}
+}
+
+func TestCheckTextCacheInvalidatedByRules(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+
+	res := decodeData[CheckResult](t, postCheck(t, app, `{"text":"Лучшее казино нашего города, заходите"}`))
+	if res.Verdict != VerdictAccept || app.cache.len() != 1 {
+		t.Fatalf("Решение должно попасть в кэш: %+v, записей %d", res, app.cache.len())
+	}
+	// Повтор текста берется из кэша, но оценка спама учитывает каждую отправку
+	res = decodeData[CheckResult](t, postCheck(t, app, `{"text":"Лучшее казино нашего города, заходите"}`))
+	if res.SpamScore == 0 || app.cache.len() != 1 {
+		t.Errorf("Повтор должен повышать оценку спама: %+v", res)
+	}
+
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"казино"}`)
+	if rr := postCheck(t, app, `{"text":"Лучшее казино нашего города, заходите"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("После изменения правил кэш не должен возвращать прежнее решение: %d", rr.Code)
+	}
+}
+
+func TestCheckTextCacheVariantsOfWhitespaceRules(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"онлайн казино"}`)
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"ставки на спорт","kind":"regex"}`)
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"(?-i)СПАМ","kind":"regex"}`)
+
+	// Правила совпадают с текстом, но не с его вариантом с другими пробелами или регистром
+	for text, variant := range map[string]string{
+		"Лучшее онлайн казино":   "Лучшее онлайн  казино",
+		"Лучшие ставки на спорт": "Лучшие ставки  на спорт",
+		"Это СПАМ":               "Это спам",
+	} {
+		if rr := postCheck(t, app, `{"text":"`+text+`"}`); rr.Code != http.StatusBadRequest {
+			t.Fatalf("%q: ожидался отказ, получен статус %d", text, rr.Code)
+		}
+		if rr := postCheck(t, app, `{"text":"`+variant+`"}`); rr.Code != http.StatusOK {
+			t.Errorf("%q: вариант текста проверяется по словарю, а не берется из кэша, получен статус %d", variant, rr.Code)
+		}
+	}
+}
@@ -1,20 +1,61 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"pkg/httpx"
 )
 
+// testModeratorToken — токен модератора anna в тестовой конфигурации
+const testModeratorToken = "anna-token"
+
+// testConfig — конфигурация по умолчанию с базой правил во временном каталоге теста
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.DBPath = filepath.Join(t.TempDir(), "censor.db")
+	cfg.Moderators = map[string]string{"anna": testModeratorToken}
+	return cfg
+}
+
+func newTestApp(t *testing.T, cfg Config) *App {
+	t.Helper()
+	app := NewApp(cfg)
+	t.Cleanup(func() { app.db.Close() })
+	return app
+}
+
 func TestHealthCheck(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/health", nil)
 	rr := httptest.NewRecorder()
 
-	config := Config{Port: "8080"}
-	app := NewApp(config)
+	app := newTestApp(t, testConfig(t))
 	app.router.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
 		t.Errorf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestCheckTextForbiddenWordsProblem(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text":"Привет, ЙЦУКЕН"}`)))
+
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != httpx.ProblemContentType {
+		t.Fatalf("Ожидалась ошибка %d в формате problem+json, получено %d %s", http.StatusBadRequest, rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var p httpx.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Code != httpx.CodeForbiddenWords || p.Type != "/problems/forbidden_words" || p.Status != http.StatusBadRequest {
+		t.Errorf("Неверное описание ошибки: %+v", p)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"pkg/config"
+	"pkg/server"
+)
+
+type Config struct {
+	Port           string                  `yaml:"port" desc:"порт HTTP-сервера"`
+	GRPCPort       string                  `yaml:"grpc_port" desc:"порт внутреннего gRPC API"`
+	DBPath         string                  `yaml:"db_path" desc:"путь к файлу базы SQLite с правилами словаря"`
+	ForbiddenWords []string                `yaml:"forbidden_words" desc:"начальный словарь: запрещенные слова через запятую, переносятся в базу при первом запуске"`
+	BatchMaxItems  int                     `yaml:"batch_max_items" desc:"сколько текстов допустимо в JSON-массиве пакетной проверки"`
+	CacheSize      int                     `yaml:"cache_size" desc:"сколько решений словаря по недавним текстам хранить в кэше, 0 — без кэша"`
+	Spam           SpamConfig              `yaml:"spam"`
+	Policies       map[string]PolicyConfig `yaml:"policies" desc:"политики проверки для разделов и сайтов-партнеров (только в файле)"`
+	Moderators     map[string]string       `yaml:"moderators" secret:"true" desc:"модераторы, которым разрешено менять правила: имя → токен (только в файле)"`
+	Shutdown       server.ShutdownConfig   `yaml:"shutdown"`
+}
+
+// SpamConfig — правила оценки спама и пороги решений
+type SpamConfig struct {
+	QueueThreshold   float64       `yaml:"queue_threshold" desc:"оценка спама, с которой комментарий отправляется на модерацию"`
+	RejectThreshold  float64       `yaml:"reject_threshold" desc:"оценка спама, с которой комментарий отклоняется"`
+	MaxLinks         int           `yaml:"max_links" desc:"сколько ссылок допустимо в комментарии"`
+	BlockedDomains   []string      `yaml:"blocked_domains" desc:"домены, ссылки на которые считаются спамом, через запятую"`
+	DuplicateWindow  time.Duration `yaml:"duplicate_window" desc:"за какое время искать повторы текста"`
+	DuplicateHistory int           `yaml:"duplicate_history" desc:"сколько последних текстов хранить для поиска повторов"`
+	VelocityWindow   time.Duration `yaml:"velocity_window" desc:"окно подсчета комментариев одного автора"`
+	VelocityLimit    int           `yaml:"velocity_limit" desc:"сколько комментариев автора допустимо за окно"`
+}
+
+// PolicyConfig — пороги решений политики; нулевой порог берется из spam
+type PolicyConfig struct {
+	QueueThreshold  float64 `yaml:"queue_threshold"`
+	RejectThreshold float64 `yaml:"reject_threshold"`
+}
+
+// thresholds — пороги модерации и отказа политики name; пустое имя — политика по умолчанию
+func (c *Config) thresholds(name string) (queue, reject float64) {
+	queue, reject = c.Spam.QueueThreshold, c.Spam.RejectThreshold
+	if p, ok := c.Policies[name]; ok {
+		if p.QueueThreshold > 0 {
+			queue = p.QueueThreshold
+		}
+		if p.RejectThreshold > 0 {
+			reject = p.RejectThreshold
+		}
+	}
+	return queue, reject
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Port:           "8082",
+		GRPCPort:       "9082",
+		DBPath:         "./censor.db",
+		ForbiddenWords: []string{"qwerty", "йцукен", "zxvbnm"},
+		BatchMaxItems:  1000,
+		CacheSize:      10000,
+		Spam: SpamConfig{
+			QueueThreshold:   0.5,
+			RejectThreshold:  0.9,
+			MaxLinks:         2,
+			DuplicateWindow:  time.Hour,
+			DuplicateHistory: 1000,
+			VelocityWindow:   time.Minute,
+			VelocityLimit:    5,
+		},
+		Shutdown: server.DefaultShutdownConfig(),
+	}
+}
+
+func (c *Config) Validate() error {
+	var errs config.Errors
+	errs.Port("port", c.Port)
+	errs.Port("grpc_port", c.GRPCPort)
+	errs.Required("db_path", c.DBPath)
+	if len(c.ForbiddenWords) == 0 {
+		errs.Add("forbidden_words", "at least one word is required")
+	}
+	for _, word := range c.ForbiddenWords {
+		if strings.TrimSpace(word) == "" {
+			errs.Add("forbidden_words", "must not contain empty words")
+			break
+		}
+	}
+	errs.Positive("batch_max_items", c.BatchMaxItems)
+	if c.CacheSize < 0 {
+		errs.Add("cache_size", "must not be negative, got %d", c.CacheSize)
+	}
+	if c.Spam.QueueThreshold <= 0 || c.Spam.QueueThreshold > 1 {
+		errs.Add("spam.queue_threshold", "must be in (0, 1], got %v", c.Spam.QueueThreshold)
+	}
+	if c.Spam.RejectThreshold < c.Spam.QueueThreshold || c.Spam.RejectThreshold > 1 {
+		errs.Add("spam.reject_threshold", "must be between spam.queue_threshold and 1, got %v", c.Spam.RejectThreshold)
+	}
+	if c.Spam.MaxLinks < 0 {
+		errs.Add("spam.max_links", "must not be negative, got %d", c.Spam.MaxLinks)
+	}
+	errs.PositiveDuration("spam.duplicate_window", c.Spam.DuplicateWindow)
+	errs.Positive("spam.duplicate_history", c.Spam.DuplicateHistory)
+	errs.PositiveDuration("spam.velocity_window", c.Spam.VelocityWindow)
+	errs.Positive("spam.velocity_limit", c.Spam.VelocityLimit)
+	moderators := make([]string, 0, len(c.Moderators))
+	for name := range c.Moderators {
+		moderators = append(moderators, name)
+	}
+	sort.Strings(moderators)
+	tokens := make(map[string]bool, len(c.Moderators))
+	for _, name := range moderators {
+		token := c.Moderators[name]
+		switch {
+		case strings.TrimSpace(name) == "":
+			errs.Add("moderators", "moderator name must not be empty")
+		case token == "":
+			errs.Add("moderators."+name, "token must not be empty")
+		case tokens[token]:
+			errs.Add("moderators."+name, "token must be unique")
+		}
+		tokens[token] = true
+	}
+	names := make([]string, 0, len(c.Policies))
+	for name := range c.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := "policies." + name
+		if name != strings.ToLower(strings.TrimSpace(name)) || len(name) > maxPolicyLength {
+			errs.Add(key, "policy name must be lowercase and at most %d characters", maxPolicyLength)
+		}
+		p := c.Policies[name]
+		if p.QueueThreshold < 0 || p.QueueThreshold > 1 {
+			errs.Add(key+".queue_threshold", "must be in [0, 1], got %v", p.QueueThreshold)
+		}
+		if p.RejectThreshold < 0 || p.RejectThreshold > 1 {
+			errs.Add(key+".reject_threshold", "must be in [0, 1], got %v", p.RejectThreshold)
+		} else if queue, reject := c.thresholds(name); reject < queue {
+			errs.Add(key+".reject_threshold", "must not be below queue threshold %v, got %v", queue, reject)
+		}
+	}
+	c.Shutdown.Validate(&errs, "shutdown")
+	return errs.Err()
+}
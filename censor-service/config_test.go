@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Конфигурация по умолчанию должна быть корректной: %v", err)
+	}
+
+	cfg.Port = "0"
+	cfg.DBPath = ""
+	cfg.ForbiddenWords = nil
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "port:") || !strings.Contains(err.Error(), "db_path:") || !strings.Contains(err.Error(), "forbidden_words:") {
+		t.Errorf("Ожидались ошибки port, db_path и forbidden_words, получено: %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Spam.QueueThreshold = 0.8
+	cfg.Spam.RejectThreshold = 0.6
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "spam.reject_threshold:") {
+		t.Errorf("Порог отказа ниже порога модерации должен быть ошибкой, получено: %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Policies = map[string]PolicyConfig{
+		"sport":   {QueueThreshold: 0.3},
+		"Partner": {},
+		"strict":  {QueueThreshold: 0.95},
+	}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "policies.Partner:") || !strings.Contains(err.Error(), "policies.strict.reject_threshold:") || strings.Contains(err.Error(), "policies.sport") {
+		t.Errorf("Ожидались ошибки политик Partner и strict, получено: %v", err)
+	}
+}
+
+func TestForbiddenWordsFromConfig(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.ForbiddenWords = []string{"Спам"}
+	app := newTestApp(t, cfg)
+
+	for text, want := range map[string]int{"это спам": http.StatusBadRequest, "qwerty": http.StatusOK} {
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/check", strings.NewReader(`{"text":"`+text+`"}`)))
+		if rr.Code != want {
+			t.Errorf("%q: ожидался статус %d, получен %d", text, want, rr.Code)
+		}
+	}
+}
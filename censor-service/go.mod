@@ -4,14 +4,24 @@ go 1.24.0
 
 replace golang.org/x/time => golang.org/x/time v0.5.0
 
+replace pkg => ../pkg
+
 require (
 	github.com/go-chi/chi/v5 v5.2.3
+	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/rs/zerolog v1.34.0
+	google.golang.org/grpc v1.75.1
+	pkg v0.0.0
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/rs/xid v1.6.0 // indirect
+	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
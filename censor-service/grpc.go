@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"pkg/grpcx"
+	"pkg/idempotency"
+	"pkg/pb/censorpb"
+)
+
+// censorServer — внутренний gRPC API сервиса цензуры
+type censorServer struct {
+	censorpb.UnimplementedCensorServiceServer
+	app *App
+}
+
+func (s *censorServer) CheckText(ctx context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error) {
+	var key string
+	if values := metadata.ValueFromIncomingContext(ctx, idempotency.Metadata); len(values) > 0 {
+		key = values[0]
+	}
+	result, problem := s.app.check(CheckRequest{Text: req.GetText(), Author: req.GetAuthor(), Language: req.GetLanguage(), Policy: req.GetPolicy(), Key: key})
+	if problem != nil {
+		return nil, grpcx.Error(problem)
+	}
+	resp := &censorpb.CheckTextResponse{Verdict: result.Verdict, SpamScore: result.SpamScore, Language: result.Language, Policy: result.Policy}
+	for _, sig := range result.Signals {
+		resp.Signals = append(resp.Signals, &censorpb.SpamSignal{Rule: sig.Rule, Score: sig.Score, Detail: sig.Detail})
+	}
+	for _, sp := range result.Mask {
+		resp.Mask = append(resp.Mask, &censorpb.TextSpan{Start: int32(sp.Start), End: int32(sp.End)})
+	}
+	return resp, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/censorpb"
+)
+
+func dialGRPC(t *testing.T, app *App) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go app.grpc.Serve(lis)
+	t.Cleanup(app.grpc.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCCheckText(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"дурак","action":"mask"}`)
+	client := censorpb.NewCensorServiceClient(dialGRPC(t, app))
+
+	resp, err := client.CheckText(context.Background(), &censorpb.CheckTextRequest{Text: "Хороший комментарий"})
+	if err != nil || resp.GetVerdict() != VerdictAccept {
+		t.Errorf("Допустимый текст не должен отклоняться: %v %v", resp, err)
+	}
+
+	resp, err = client.CheckText(context.Background(), &censorpb.CheckTextRequest{Text: "КУПИТЕ!!!!!!!! http://a.example http://b.example http://c.example", Author: "bot"})
+	if err != nil || resp.GetVerdict() != VerdictReject || len(resp.GetSignals()) != 2 {
+		t.Errorf("Ожидался отказ по оценке спама, получено %v %v", resp, err)
+	}
+
+	resp, err = client.CheckText(context.Background(), &censorpb.CheckTextRequest{Text: "Сам дурак"})
+	if err != nil || len(resp.GetMask()) != 1 || resp.GetMask()[0].GetStart() != int32(len("Сам ")) {
+		t.Errorf("Ожидался фрагмент для маскировки, получено %v %v", resp, err)
+	}
+
+	_, err = client.CheckText(context.Background(), &censorpb.CheckTextRequest{Text: "Это QWERTY"})
+	p, ok := grpcx.Problem(err)
+	if !ok || p.Status != http.StatusBadRequest || p.Code != httpx.CodeForbiddenWords {
+		t.Errorf("Ожидалась ошибка forbidden_words, получено %v", err)
+	}
+}
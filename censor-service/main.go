@@ -2,91 +2,106 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
-	"os"
-	"strconv"
-	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/hlog"
+	"google.golang.org/grpc"
+
+	"pkg/config"
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/pb/censorpb"
+	"pkg/server"
 )
 
-var forbiddenWords = map[string]bool{
-	"qwerty": true,
-	"йцукен": true,
-	"zxvbnm": true,
-}
-
-type Config struct {
-	Port string
-}
-
 type App struct {
 	config Config
 	logger zerolog.Logger
 	router chi.Router
+	grpc   *grpc.Server
+	health *server.Health
+	db     *sql.DB
+	spam   *SpamPipeline
+	cache  *decisionCache
+
+	// matcher — текущая версия словаря; reloadMu упорядочивает перезагрузки после изменений правил
+	matcher  atomic.Pointer[Matcher]
+	reloadMu sync.Mutex
 }
 
+// CheckRequest — текст на проверку. Language не обязателен: если он не задан, язык определяется по тексту;
+// Policy выбирает политику из конфигурации, пустая — политика по умолчанию.
 type CheckRequest struct {
-	Text string `json:"text"`
-}
-
-type Response struct {
-	Status string      `json:"status"`
-	Data   interface{} `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	Text     string `json:"text"`
+	Author   string `json:"author,omitempty"`
+	Language string `json:"language,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+	// Key — ключ идемпотентности запроса клиента (заголовок Idempotency-Key): повтор того же запроса
+	// не учитывается правилами повторов и частоты как новый комментарий
+	Key string `json:"-"`
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func RequestIDMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-		ctx := context.WithValue(r.Context(), "request_id", requestID)
-		w.Header().Set("X-Request-ID", requestID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
-
-func generateRequestID() string {
-	return strconv.FormatInt(time.Now().UnixNano(), 10)
-}
-
-func LoggerMiddleware(logger *zerolog.Logger) func(http.Handler) http.Handler {
-	return hlog.NewHandler(*logger)
+// CheckResult — оценка спама, сработавшие правила словаря с действиями mask и queue,
+// фрагменты текста, которые нужно скрыть перед публикацией, и язык, по которому выбраны правила
+type CheckResult struct {
+	SpamResult
+	Matches  []RuleMatch `json:"matches,omitempty"`
+	Mask     []Span      `json:"mask,omitempty"`
+	Language string      `json:"language,omitempty"`
+	Policy   string      `json:"policy,omitempty"`
 }
 
 func NewApp(config Config) *App {
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-
-	r := chi.NewRouter()
-
-	r.Use(middleware.Recoverer)
-	r.Use(RequestIDMiddleware)
-	r.Use(LoggerMiddleware(&logger))
+	logger := server.NewLogger("censor-service")
+	health := server.NewHealth()
+	r := server.NewRouter(logger, health)
 
 	app := &App{
 		config: config,
 		logger: logger,
 		router: r,
+		grpc:   grpcx.NewServer(logger),
+		health: health,
+		spam:   NewSpamPipeline(config.Spam),
+		cache:  newDecisionCache(config.CacheSize),
+	}
+
+	var err error
+	app.db, err = openRulesDB(config.DBPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := app.seedRules(context.Background(), config.ForbiddenWords); err != nil {
+		log.Fatal(err)
+	}
+	if err := app.reloadRules(context.Background()); err != nil {
+		log.Fatal(err)
 	}
+	health.AddCheck("database", app.db.PingContext)
 
 	r.Get("/", app.Home)
-	r.Get("/health", app.HealthCheck)
 	r.Post("/check", app.CheckText)
+	r.Post("/check/batch", app.CheckBatch)
+	r.Get("/rules", app.ListRules)
+	r.Get("/rules/audit", app.ListRuleAudit)
+	r.Get("/rules/version", app.RulesVersion)
+	r.Get("/rules/{id}", app.GetRule)
+	r.Group(func(r chi.Router) {
+		r.Use(app.ModeratorOnly)
+		r.Post("/rules", app.CreateRule)
+		r.Put("/rules/{id}", app.UpdateRule)
+		r.Delete("/rules/{id}", app.DeleteRule)
+	})
+
+	censorpb.RegisterCensorServiceServer(app.grpc, &censorServer{app: app})
 
 	return app
 }
@@ -97,53 +112,81 @@ func (a *App) Home(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Censor Service OK"))
 }
 
-func (a *App) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{Status: "ok"})
-}
-
 func (a *App) CheckText(w http.ResponseWriter, r *http.Request) {
 	var req CheckRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		a.sendError(w, http.StatusBadRequest, "Invalid request body")
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
 		return
 	}
+	req.Key = r.Header.Get(idempotency.Header)
 
-	text := strings.ToLower(req.Text)
+	result, problem := a.check(req)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+
+	httpx.SendResponse(w, http.StatusOK, result)
+}
 
-	for word := range forbiddenWords {
-		if strings.Contains(text, strings.ToLower(word)) {
-			a.sendError(w, http.StatusBadRequest, "Text contains forbidden words")
-			return
+// check — применяет правила словаря для языка и политики запроса: правила с действием block отклоняют
+// текст сразу, остальное решает оценка спама по порогам политики; правило с действием queue отправляет
+// текст на модерацию независимо от оценки, совпадения правил с действием mask возвращаются в Mask
+// для скрытия при публикации
+func (a *App) check(req CheckRequest) (CheckResult, *httpx.Problem) {
+	scope, problem := a.scope(req.Text, req.Language, req.Policy)
+	if problem != nil {
+		return CheckResult{}, problem
+	}
+	matches := a.match(a.matcher.Load(), req.Text, scope)
+	for _, m := range matches {
+		if m.Action == ActionBlock {
+			return CheckResult{}, errForbiddenWords
 		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(Response{Status: "success"})
+	result := CheckResult{
+		SpamResult: a.spam.Evaluate(req.Text, req.Author, req.Key),
+		Matches:    matches,
+		Mask:       MaskSpans(matches),
+		Language:   scope.Language,
+		Policy:     scope.Policy,
+	}
+	queue, reject := a.config.thresholds(scope.Policy)
+	result.Verdict = spamVerdict(result.SpamScore, queue, reject)
+	for _, m := range matches {
+		if m.Action == ActionQueue && result.Verdict == VerdictAccept {
+			result.Verdict = VerdictQueue
+		}
+	}
+	return result, nil
 }
 
-func (a *App) sendError(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(Response{
-		Status: "error",
-		Error:  message,
-	})
-}
+var errForbiddenWords = httpx.NewProblem(http.StatusBadRequest, httpx.CodeForbiddenWords, "Text contains forbidden words")
 
 func (a *App) Run() error {
-	return http.ListenAndServe(":"+a.config.Port, a.router)
+	srv := &server.Server{
+		Addr:            ":" + a.config.Port,
+		Handler:         a.router,
+		Logger:          a.logger,
+		Health:          a.health,
+		GRPCAddr:        ":" + a.config.GRPCPort,
+		GRPC:            a.grpc,
+		DrainDelay:      a.config.Shutdown.DrainDelay,
+		ShutdownTimeout: a.config.Shutdown.Timeout,
+		OnShutdown:      []func() error{a.db.Close},
+	}
+	return srv.Run()
 }
 
 func main() {
-	config := Config{
-		Port: getEnv("PORT", "8082"),
-	}
+	cfg := DefaultConfig()
+	config.MustLoad("censor-service", &cfg)
 
-	app := NewApp(config)
+	app := NewApp(cfg)
 
-	log.Printf("Censor Service запущен на порту %s", config.Port)
+	log.Printf("Censor Service запущен на порту %s", cfg.Port)
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// RuleMatch — правило, сработавшее на тексте, и все его вхождения
+type RuleMatch struct {
+	RuleID   int    `json:"rule_id"`
+	Pattern  string `json:"pattern"`
+	Severity string `json:"severity"`
+	Action   string `json:"action"`
+	Spans    []Span `json:"spans"`
+}
+
+// Span — фрагмент текста [Start, End) в байтах UTF-8
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Matcher — неизменяемый набор включенных правил одной версии словаря.
+// При изменении правил строится новый Matcher и целиком заменяет старый,
+// поэтому проверка никогда не видит словарь в промежуточном состоянии.
+// Термины ищутся автоматом Ахо — Корасик, который строится один раз на версию словаря.
+type Matcher struct {
+	Version int64
+	terms   *ahoCorasick
+	// termRules — правила каждого термина автомата: один термин может быть в нескольких правилах
+	termRules [][]Rule
+	regexes   []matcherRegex
+	// invariant — правила, которые срабатывают одинаково на тексте с другим регистром и повторами пробелов:
+	// термины без пробелов. Регулярные выражения и термины с пробелами от них зависят.
+	invariant map[int]bool
+}
+
+type matcherRegex struct {
+	rule Rule
+	re   *regexp.Regexp
+}
+
+// NewMatcher — сборка словаря из правил; выключенные правила пропускаются
+func NewMatcher(version int64, rules []Rule) (*Matcher, error) {
+	m := &Matcher{Version: version, invariant: make(map[int]bool)}
+	var terms []string
+	termIndex := make(map[string]int)
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		switch rule.Kind {
+		case RuleRegex:
+			re, err := compileRulePattern(rule.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			m.regexes = append(m.regexes, matcherRegex{rule: rule, re: re})
+		default:
+			term := foldString(rule.Pattern)
+			m.invariant[rule.ID] = !strings.ContainsFunc(term, unicode.IsSpace)
+			i, ok := termIndex[term]
+			if !ok {
+				i = len(terms)
+				termIndex[term] = i
+				terms = append(terms, term)
+				m.termRules = append(m.termRules, nil)
+			}
+			m.termRules[i] = append(m.termRules[i], rule)
+		}
+	}
+	m.terms = newAhoCorasick(terms)
+	return m, nil
+}
+
+// compileRulePattern — регулярные выражения правил не зависят от регистра, как и термины
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// Invariant — сработавшие правила matches будут теми же для любого варианта текста, который отличается
+// только регистром и повторами пробелов
+func (m *Matcher) Invariant(matches []RuleMatch) bool {
+	for _, match := range matches {
+		if !m.invariant[match.RuleID] {
+			return false
+		}
+	}
+	return true
+}
+
+// Match — все правила области scope, сработавшие на тексте, в порядке их идентификаторов
+func (m *Matcher) Match(text string, scope Scope) []RuleMatch {
+	// Вхождения каждого термина в порядке их первого появления в тексте
+	var order []int
+	var spans map[int][]Span
+	m.terms.scan(text, func(term, start, end int) {
+		if spans == nil {
+			spans = make(map[int][]Span)
+		}
+		if _, ok := spans[term]; !ok {
+			order = append(order, term)
+		}
+		spans[term] = append(spans[term], Span{Start: start, End: end})
+	})
+
+	var matches []RuleMatch
+	for _, term := range order {
+		for _, rule := range m.termRules[term] {
+			if scope.applies(rule) {
+				matches = append(matches, rule.match(spans[term]))
+			}
+		}
+	}
+	for _, r := range m.regexes {
+		if !scope.applies(r.rule) {
+			continue
+		}
+		var found []Span
+		for _, loc := range r.re.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				found = append(found, Span{Start: loc[0], End: loc[1]})
+			}
+		}
+		if len(found) > 0 {
+			matches = append(matches, r.rule.match(found))
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].RuleID < matches[j].RuleID })
+	return matches
+}
+
+// MaskSpans — объединенные фрагменты правил с действием mask в порядке следования в тексте
+func MaskSpans(matches []RuleMatch) []Span {
+	var spans []Span
+	for _, m := range matches {
+		if m.Action == ActionMask {
+			spans = append(spans, m.Spans...)
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if sp.Start <= last.End {
+			last.End = max(last.End, sp.End)
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return merged
+}
+
+func (r Rule) match(spans []Span) RuleMatch {
+	return RuleMatch{RuleID: r.ID, Pattern: r.Pattern, Severity: r.Severity, Action: r.Action, Spans: spans}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestMatcher(t *testing.T) {
+	m, err := NewMatcher(7, []Rule{
+		{ID: 3, Pattern: `\d{3}-\d{2}-\d{2}`, Kind: RuleRegex, Action: ActionMask, Enabled: true},
+		{ID: 1, Pattern: "Спам", Kind: RuleTerm, Action: ActionBlock, Enabled: true},
+		{ID: 2, Pattern: "реклама", Kind: RuleTerm, Action: ActionQueue},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != 7 {
+		t.Errorf("Ожидалась версия 7, получена %d", m.Version)
+	}
+
+	text := "СПАМ и реклама, звоните 123-45-67 или 765-43-21, спам"
+	matches := m.Match(text, Scope{})
+	if len(matches) != 2 || matches[0].RuleID != 1 || matches[1].RuleID != 3 {
+		t.Fatalf("Ожидались правила 1 и 3 без выключенного правила 2: %+v", matches)
+	}
+	if spans := matches[0].Spans; len(spans) != 2 || text[spans[0].Start:spans[0].End] != "СПАМ" || text[spans[1].Start:spans[1].End] != "спам" {
+		t.Errorf("Должны возвращаться все вхождения термина: %+v", spans)
+	}
+	if spans := matches[1].Spans; len(spans) != 2 || text[spans[1].Start:spans[1].End] != "765-43-21" {
+		t.Errorf("Должны возвращаться все вхождения регулярного выражения: %+v", spans)
+	}
+	if matches := m.Match("обычный текст", Scope{}); len(matches) != 0 {
+		t.Errorf("Совпадений быть не должно: %+v", matches)
+	}
+}
+
+func TestMatcherInvalidRegex(t *testing.T) {
+	if _, err := NewMatcher(1, []Rule{{Pattern: "(", Kind: RuleRegex, Enabled: true}}); err == nil {
+		t.Error("Некорректное регулярное выражение должно быть ошибкой")
+	}
+}
+
+func TestMaskSpans(t *testing.T) {
+	matches := []RuleMatch{
+		{RuleID: 1, Action: ActionMask, Spans: []Span{{10, 15}, {0, 4}}},
+		{RuleID: 2, Action: ActionBlock, Spans: []Span{{20, 25}}},
+		{RuleID: 3, Action: ActionMask, Spans: []Span{{2, 6}, {15, 18}}},
+	}
+	got := MaskSpans(matches)
+	want := []Span{{0, 6}, {10, 18}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Пересекающиеся и смежные фрагменты должны объединяться: ожидалось %v, получено %v", want, got)
+	}
+	if MaskSpans(matches[1:2]) != nil {
+		t.Error("Без правил mask скрывать нечего")
+	}
+}
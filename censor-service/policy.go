@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"pkg/httpx"
+)
+
+// maxPolicyLength — ограничение длины имени политики
+const maxPolicyLength = 64
+
+// Языки, которые определяются по тексту
+const (
+	LanguageRU = "ru"
+	LanguageEN = "en"
+)
+
+// Scope — область проверки: язык текста и политика раздела или сайта-партнера.
+// Правило словаря действует в области, если его язык пуст или совпадает с языком текста,
+// а политика пуста (общее правило) или совпадает с политикой проверки.
+// Пока язык текста неизвестен, действуют правила всех языков.
+type Scope struct {
+	Language string
+	Policy   string
+}
+
+func (s Scope) applies(rule Rule) bool {
+	return (rule.Language == "" || s.Language == "" || rule.Language == s.Language) &&
+		(rule.Policy == "" || rule.Policy == s.Policy)
+}
+
+// detectLanguage — язык по преобладающей письменности: кириллица — ru, латиница — en;
+// пустая строка, если букв нет
+func detectLanguage(text string) string {
+	var cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	switch {
+	case cyrillic == 0 && latin == 0:
+		return ""
+	case cyrillic >= latin:
+		return LanguageRU
+	default:
+		return LanguageEN
+	}
+}
+
+// scope — проверяет язык и политику из запроса; язык, не указанный вызывающей стороной, определяется по тексту
+func (a *App) scope(text, language, policy string) (Scope, *httpx.Problem) {
+	s := Scope{Language: strings.ToLower(strings.TrimSpace(language)), Policy: strings.ToLower(strings.TrimSpace(policy))}
+	var fields []httpx.FieldError
+	if len(s.Language) > maxLanguageLength {
+		fields = append(fields, httpx.FieldError{Field: "language", Code: httpx.FieldTooLong, Message: "Language too long"})
+	}
+	if field := checkPolicy(a.config.Policies, s.Policy); field != nil {
+		fields = append(fields, *field)
+	}
+	if len(fields) > 0 {
+		return Scope{}, httpx.ValidationProblem(fields)
+	}
+	if s.Language == "" {
+		s.Language = detectLanguage(text)
+	}
+	return s, nil
+}
+
+// checkPolicy — политика должна быть описана в конфигурации; пустая — политика по умолчанию
+func checkPolicy(policies map[string]PolicyConfig, policy string) *httpx.FieldError {
+	if _, ok := policies[policy]; policy != "" && !ok {
+		return &httpx.FieldError{Field: "policy", Code: httpx.FieldUnknown, Message: "Unknown policy " + policy}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pkg/httpx"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"Привет, мир":                   LanguageRU,
+		"Hello, world":                  LanguageEN,
+		"Новость про iPhone":            LanguageRU,
+		"Breaking: взрыв news in Paris": LanguageEN,
+		"123 😀 !!!":                     "",
+	}
+	for text, want := range tests {
+		if got := detectLanguage(text); got != want {
+			t.Errorf("%q: ожидался язык %q, получен %q", text, want, got)
+		}
+	}
+}
+
+func TestMatcherScope(t *testing.T) {
+	m, err := NewMatcher(1, []Rule{
+		{ID: 1, Pattern: "bad", Language: LanguageEN, Action: ActionBlock, Enabled: true},
+		{ID: 2, Pattern: "плохо", Language: LanguageRU, Action: ActionBlock, Enabled: true},
+		{ID: 3, Pattern: "казино", Policy: "sport", Action: ActionBlock, Enabled: true},
+		{ID: 4, Pattern: `\d{5}`, Kind: RuleRegex, Policy: "partner", Action: ActionMask, Enabled: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "bad плохо казино 12345"
+	tests := []struct {
+		name  string
+		scope Scope
+		want  []int
+	}{
+		{"язык неизвестен, политика по умолчанию", Scope{}, []int{1, 2}},
+		{"русский", Scope{Language: LanguageRU}, []int{2}},
+		{"английский, политика sport", Scope{Language: LanguageEN, Policy: "sport"}, []int{1, 3}},
+		{"политика partner", Scope{Language: LanguageRU, Policy: "partner"}, []int{2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, match := range m.Match(text, tt.scope) {
+				got = append(got, match.RuleID)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && (got[0] != tt.want[0] || got[len(got)-1] != tt.want[len(tt.want)-1])) {
+				t.Errorf("Ожидались правила %v, получено %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func newPolicyTestApp(t *testing.T) *App {
+	t.Helper()
+	cfg := testConfig(t)
+	cfg.Policies = map[string]PolicyConfig{
+		"strict":  {QueueThreshold: 0.05, RejectThreshold: 0.5},
+		"partner": {},
+	}
+	app := newTestApp(t, cfg)
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"ставки","language":"ru","policy":"strict"}`)
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"damn","language":"en","action":"mask"}`)
+	return app
+}
+
+func postCheck(t *testing.T, app *App, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(body)))
+	return rr
+}
+
+func TestCheckTextPolicies(t *testing.T) {
+	app := newPolicyTestApp(t)
+
+	// Правило политики strict не действует в других политиках
+	if rr := postCheck(t, app, `{"text":"Лучшие ставки"}`); rr.Code != http.StatusOK {
+		t.Errorf("Без политики правило strict не должно срабатывать: %d", rr.Code)
+	}
+	if rr := postCheck(t, app, `{"text":"Лучшие ставки","policy":"Strict"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("В политике strict текст должен отклоняться: %d", rr.Code)
+	}
+
+	// Английское правило не применяется к тексту, определенному как русский, пока язык не указан явно
+	res := decodeData[CheckResult](t, postCheck(t, app, `{"text":"Ну damn, как так"}`))
+	if res.Language != LanguageRU || len(res.Mask) != 0 {
+		t.Errorf("Ожидался русский язык без скрытых слов: %+v", res)
+	}
+	res = decodeData[CheckResult](t, postCheck(t, app, `{"text":"Ну damn, как так","language":"en"}`))
+	if res.Language != LanguageEN || len(res.Mask) != 1 {
+		t.Errorf("Явно указанный язык должен применять английские правила: %+v", res)
+	}
+
+	// Пороги политики strict строже порогов по умолчанию; тексты разные, чтобы не сработал поиск повторов
+	if res := decodeData[CheckResult](t, postCheck(t, app, `{"text":"Подробнее на http://news.example"}`)); res.Verdict != VerdictAccept {
+		t.Errorf("По умолчанию одна ссылка не спам: %+v", res)
+	}
+	if res := decodeData[CheckResult](t, postCheck(t, app, `{"text":"Смотрите обзор матча: www.sport.example","policy":"strict"}`)); res.Verdict != VerdictQueue || res.Policy != "strict" {
+		t.Errorf("В политике strict ссылка должна отправлять на модерацию: %+v", res)
+	}
+	// Политика без порогов использует пороги spam
+	if res := decodeData[CheckResult](t, postCheck(t, app, `{"text":"Интервью целиком — https://partner.example/interview","policy":"partner"}`)); res.Verdict != VerdictAccept {
+		t.Errorf("Политика partner должна использовать пороги по умолчанию: %+v", res)
+	}
+}
+
+func TestUnknownPolicy(t *testing.T) {
+	app := newPolicyTestApp(t)
+	for _, rr := range []*httptest.ResponseRecorder{
+		postCheck(t, app, `{"text":"текст","policy":"unknown"}`),
+		doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"слово","policy":"unknown"}`),
+	} {
+		var p httpx.Problem
+		json.Unmarshal(rr.Body.Bytes(), &p)
+		if rr.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "policy" || p.Errors[0].Code != httpx.FieldUnknown {
+			t.Errorf("Неизвестная политика должна быть ошибкой поля policy: %d %s", rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestCheckBatchScope(t *testing.T) {
+	app := newPolicyTestApp(t)
+	body := `[{"id":1,"text":"ставки","policy":"strict"},{"id":2,"text":"ставки"},{"id":3,"text":"ставки","policy":"unknown"}]`
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check/batch", strings.NewReader(body)))
+	results := decodeData[[]BatchResult](t, rr)
+	if len(results) != 3 || results[0].Verdict != VerdictReject || results[1].Verdict != VerdictAccept || results[2].Error != "invalid_item" {
+		t.Errorf("Пакетная проверка должна учитывать политику элемента: %+v", results)
+	}
+}
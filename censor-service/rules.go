@@ -0,0 +1,573 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"pkg/httpx"
+)
+
+// Виды правил: термин ищется как подстрока без учета регистра, regex — регулярное выражение
+const (
+	RuleTerm  = "term"
+	RuleRegex = "regex"
+)
+
+// Важность правила
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Действия правила: block отклоняет текст, mask скрывает совпадение, queue отправляет на модерацию
+const (
+	ActionBlock = "block"
+	ActionMask  = "mask"
+	ActionQueue = "queue"
+)
+
+// Изменения правил в журнале аудита
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// moderatorKey — ключ контекста с именем модератора, подтвержденным токеном; оно записывается в журнал аудита
+type moderatorKey struct{}
+
+// ModeratorOnly — мидлвар изменения правил: пропускает запросы с токеном одного из модераторов moderators
+// и передает обработчику имя модератора. Без настроенных модераторов правила менять нельзя.
+func (a *App) ModeratorOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(a.config.Moderators) == 0 {
+			httpx.SendError(w, r, http.StatusForbidden, httpx.CodeForbidden, "Moderator access is not configured")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		moderator := ""
+		for name, want := range a.config.Moderators {
+			if ok && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+				moderator = name
+			}
+		}
+		if moderator == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			httpx.SendError(w, r, http.StatusUnauthorized, httpx.CodeUnauthorized, "Moderator token required")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), moderatorKey{}, moderator)))
+	})
+}
+
+// moderator — имя модератора, которого пропустил ModeratorOnly
+func moderator(ctx context.Context) string {
+	name, _ := ctx.Value(moderatorKey{}).(string)
+	return name
+}
+
+// configActor — автор правил, перенесенных из forbidden_words при первом запуске
+const configActor = "config"
+
+// maxLanguageLength — ограничение длины кода языка (ru, en, pt-br)
+const maxLanguageLength = 16
+
+// Размер страницы журнала аудита
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// Rule — правило словаря. Пустой Language означает, что правило действует для всех языков,
+// пустой Policy — для всех политик.
+type Rule struct {
+	ID        int       `json:"id"`
+	Pattern   string    `json:"pattern"`
+	Kind      string    `json:"kind"`
+	Severity  string    `json:"severity"`
+	Language  string    `json:"language"`
+	Policy    string    `json:"policy"`
+	Action    string    `json:"action"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RuleAudit — запись журнала изменений правила: значения до и после изменения
+type RuleAudit struct {
+	ID        int       `json:"id"`
+	RuleID    int       `json:"rule_id"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	Old       *Rule     `json:"old,omitempty"`
+	New       *Rule     `json:"new,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ruleInput — тело POST и PUT /rules; незаданные поля получают значения по умолчанию
+type ruleInput struct {
+	Pattern  string `json:"pattern"`
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Language string `json:"language"`
+	Policy   string `json:"policy"`
+	Action   string `json:"action"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+// rule — проверяет тело запроса и возвращает правило без идентификатора и дат;
+// политика правила должна быть описана в конфигурации
+func (in ruleInput) rule(policies map[string]PolicyConfig) (Rule, *httpx.Problem) {
+	rule := Rule{
+		Pattern:  strings.TrimSpace(in.Pattern),
+		Kind:     defaultString(in.Kind, RuleTerm),
+		Severity: defaultString(in.Severity, SeverityMedium),
+		Language: strings.ToLower(strings.TrimSpace(in.Language)),
+		Policy:   strings.ToLower(strings.TrimSpace(in.Policy)),
+		Action:   defaultString(in.Action, ActionBlock),
+		Enabled:  in.Enabled == nil || *in.Enabled,
+	}
+
+	var fields []httpx.FieldError
+	switch {
+	case rule.Pattern == "":
+		fields = append(fields, httpx.FieldError{Field: "pattern", Code: httpx.FieldRequired, Message: "Pattern is required"})
+	case rule.Kind == RuleRegex:
+		if _, err := compileRulePattern(rule.Pattern); err != nil {
+			fields = append(fields, httpx.FieldError{Field: "pattern", Code: httpx.FieldInvalid, Message: "Invalid regular expression: " + err.Error()})
+		}
+	}
+	if rule.Kind != RuleTerm && rule.Kind != RuleRegex {
+		fields = append(fields, httpx.FieldError{Field: "kind", Code: httpx.FieldUnknown, Message: "Kind must be term or regex"})
+	}
+	if rule.Severity != SeverityLow && rule.Severity != SeverityMedium && rule.Severity != SeverityHigh {
+		fields = append(fields, httpx.FieldError{Field: "severity", Code: httpx.FieldUnknown, Message: "Severity must be low, medium or high"})
+	}
+	if rule.Action != ActionBlock && rule.Action != ActionMask && rule.Action != ActionQueue {
+		fields = append(fields, httpx.FieldError{Field: "action", Code: httpx.FieldUnknown, Message: "Action must be block, mask or queue"})
+	}
+	if len(rule.Language) > maxLanguageLength {
+		fields = append(fields, httpx.FieldError{Field: "language", Code: httpx.FieldTooLong, Message: "Language too long"})
+	}
+	if field := checkPolicy(policies, rule.Policy); field != nil {
+		fields = append(fields, *field)
+	}
+	if len(fields) > 0 {
+		return Rule{}, httpx.ValidationProblem(fields)
+	}
+	return rule, nil
+}
+
+func defaultString(value, def string) string {
+	if value = strings.ToLower(strings.TrimSpace(value)); value == "" {
+		return def
+	}
+	return value
+}
+
+var (
+	errDatabase     = httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Database error")
+	errRuleNotFound = httpx.NewProblem(http.StatusNotFound, httpx.CodeNotFound, "Rule not found")
+)
+
+// openRulesDB — открывает базу правил и создает таблицы
+func openRulesDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// Одно соединение: записи в SQLite все равно идут по очереди, а так они не получают SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pattern TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			severity TEXT NOT NULL,
+			language TEXT NOT NULL DEFAULT '',
+			policy TEXT NOT NULL DEFAULT '',
+			action TEXT NOT NULL,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS rule_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			rule_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			actor TEXT NOT NULL DEFAULT '',
+			old_value TEXT,
+			new_value TEXT,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_rule_audit_rule_id ON rule_audit(rule_id);
+	`)
+	if err == nil {
+		err = migrateRulesDB(db)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrateRulesDB — добавляет столбец policy в базу, созданную до появления политик
+func migrateRulesDB(db *sql.DB) error {
+	var hasPolicy bool
+	if err := db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info('rules') WHERE name = 'policy'`).Scan(&hasPolicy); err != nil {
+		return err
+	}
+	if hasPolicy {
+		return nil
+	}
+	_, err := db.Exec(`ALTER TABLE rules ADD COLUMN policy TEXT NOT NULL DEFAULT ''`)
+	return err
+}
+
+// seedRules — при первом запуске переносит forbidden_words из конфигурации в словарь.
+// Если правила уже менялись через API, конфигурация больше не влияет на словарь.
+func (a *App) seedRules(ctx context.Context, words []string) error {
+	var changes int
+	if err := a.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM rule_audit`).Scan(&changes); err != nil {
+		return err
+	}
+	if changes > 0 {
+		return nil
+	}
+	for _, word := range words {
+		rule := Rule{Pattern: word, Kind: RuleTerm, Severity: SeverityHigh, Action: ActionBlock, Enabled: true}
+		if _, problem := a.saveRule(ctx, configActor, AuditCreate, 0, &rule); problem != nil {
+			return fmt.Errorf("seed rule %q: %s", word, problem.Detail)
+		}
+	}
+	return nil
+}
+
+// querier — база или транзакция, из которой читаются правила
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// reloadRules — собирает словарь из базы и атомарно заменяет им текущий
+func (a *App) reloadRules(ctx context.Context) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	matcher, err := buildMatcher(ctx, a.db)
+	if err != nil {
+		return err
+	}
+	a.matcher.Store(matcher)
+	return nil
+}
+
+// buildMatcher — словарь из правил в базе. Версия словаря — номер последней записи аудита:
+// любое изменение правил ее увеличивает.
+func buildMatcher(ctx context.Context, q querier) (*Matcher, error) {
+	var version int64
+	if err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM rule_audit`).Scan(&version); err != nil {
+		return nil, err
+	}
+	rules, err := loadRules(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return NewMatcher(version, rules)
+}
+
+const ruleColumns = "id, pattern, kind, severity, language, policy, action, enabled, created_at, updated_at"
+
+func scanRule(row interface{ Scan(...any) error }) (Rule, error) {
+	var r Rule
+	err := row.Scan(&r.ID, &r.Pattern, &r.Kind, &r.Severity, &r.Language, &r.Policy, &r.Action, &r.Enabled, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+func loadRules(ctx context.Context, q querier) ([]Rule, error) {
+	rows, err := q.QueryContext(ctx, "SELECT "+ruleColumns+" FROM rules ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rules := []Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (a *App) getRule(ctx context.Context, id int) (Rule, *httpx.Problem) {
+	rule, err := scanRule(a.db.QueryRowContext(ctx, "SELECT "+ruleColumns+" FROM rules WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return Rule{}, errRuleNotFound
+	}
+	if err != nil {
+		return Rule{}, errDatabase
+	}
+	return rule, nil
+}
+
+// saveRule — создает, изменяет или удаляет правило id и пишет запись аудита в одной транзакции.
+// Прежнее состояние правила для журнала читается в той же транзакции, так что запись аудита
+// соответствует тому, что изменено; правило, которого уже нет, — ошибка 404.
+// Новый словарь собирается в той же транзакции и заменяет текущий после фиксации, поэтому
+// изменение либо сохраняется и сразу действует, либо не сохраняется вовсе.
+// Для создания id не используется, для удаления next равен nil.
+func (a *App) saveRule(ctx context.Context, actor, action string, id int, next *Rule) (*Rule, *httpx.Problem) {
+	// Словари заменяются в порядке фиксации изменений
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errDatabase
+	}
+	defer tx.Rollback()
+
+	var old *Rule
+	if action != AuditCreate {
+		rule, err := scanRule(tx.QueryRowContext(ctx, "SELECT "+ruleColumns+" FROM rules WHERE id = ?", id))
+		if err == sql.ErrNoRows {
+			return nil, errRuleNotFound
+		}
+		if err != nil {
+			return nil, errDatabase
+		}
+		old = &rule
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var res sql.Result
+	switch action {
+	case AuditCreate:
+		next.CreatedAt, next.UpdatedAt = now, now
+		res, err = tx.ExecContext(ctx, `INSERT INTO rules (pattern, kind, severity, language, policy, action, enabled, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			next.Pattern, next.Kind, next.Severity, next.Language, next.Policy, next.Action, next.Enabled, next.CreatedAt, next.UpdatedAt)
+		if err != nil {
+			return nil, errDatabase
+		}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			return nil, errDatabase
+		}
+		id = int(lastID)
+		next.ID = id
+	case AuditUpdate:
+		next.ID, next.CreatedAt, next.UpdatedAt = id, old.CreatedAt, now
+		res, err = tx.ExecContext(ctx, `UPDATE rules SET pattern = ?, kind = ?, severity = ?, language = ?, policy = ?, action = ?, enabled = ?, updated_at = ? WHERE id = ?`,
+			next.Pattern, next.Kind, next.Severity, next.Language, next.Policy, next.Action, next.Enabled, next.UpdatedAt, id)
+	case AuditDelete:
+		res, err = tx.ExecContext(ctx, `DELETE FROM rules WHERE id = ?`, id)
+	}
+	if err != nil {
+		return nil, errDatabase
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, errDatabase
+	} else if n == 0 {
+		return nil, errRuleNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO rule_audit (rule_id, action, actor, old_value, new_value, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, action, actor, auditValue(old), auditValue(next), now)
+	if err != nil {
+		return nil, errDatabase
+	}
+	matcher, err := buildMatcher(ctx, tx)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("Failed to build censor rules")
+		return nil, errDatabase
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errDatabase
+	}
+	a.matcher.Store(matcher)
+	return next, nil
+}
+
+// auditValue — состояние правила для журнала; nil, если правила не было или не стало
+func auditValue(rule *Rule) any {
+	if rule == nil {
+		return nil
+	}
+	data, _ := json.Marshal(rule)
+	return string(data)
+}
+
+func ruleID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid rule ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (a *App) decodeRule(w http.ResponseWriter, r *http.Request) (Rule, bool) {
+	var in ruleInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
+		return Rule{}, false
+	}
+	rule, problem := in.rule(a.config.Policies)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return Rule{}, false
+	}
+	return rule, true
+}
+
+// ListRules — все правила словаря, включая выключенные: GET /rules
+func (a *App) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := loadRules(r.Context(), a.db)
+	if err != nil {
+		httpx.SendProblem(w, r, errDatabase)
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, rules)
+}
+
+// GetRule — правило по идентификатору: GET /rules/{id}
+func (a *App) GetRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(w, r)
+	if !ok {
+		return
+	}
+	rule, problem := a.getRule(r.Context(), id)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, rule)
+}
+
+// CreateRule — новое правило: POST /rules
+func (a *App) CreateRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := a.decodeRule(w, r)
+	if !ok {
+		return
+	}
+	created, problem := a.saveRule(r.Context(), moderator(r.Context()), AuditCreate, 0, &rule)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+	httpx.SendResponse(w, http.StatusCreated, created)
+}
+
+// UpdateRule — замена правила целиком: PUT /rules/{id}
+func (a *App) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(w, r)
+	if !ok {
+		return
+	}
+	rule, ok := a.decodeRule(w, r)
+	if !ok {
+		return
+	}
+	updated, problem := a.saveRule(r.Context(), moderator(r.Context()), AuditUpdate, id, &rule)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, updated)
+}
+
+// DeleteRule — удаление правила: DELETE /rules/{id}; журнал аудита сохраняет его последнее состояние
+func (a *App) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(w, r)
+	if !ok {
+		return
+	}
+	if _, problem := a.saveRule(r.Context(), moderator(r.Context()), AuditDelete, id, nil); problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, "Rule deleted")
+}
+
+// RulesVersion — текущая версия словаря: GET /rules/version; меняется при любом изменении правил
+func (a *App) RulesVersion(w http.ResponseWriter, r *http.Request) {
+	httpx.SendResponse(w, http.StatusOK, map[string]int64{"version": a.matcher.Load().Version})
+}
+
+// ListRuleAudit — журнал изменений правил, новые записи первыми: GET /rules/audit[?rule_id=&page=&page_size=]
+func (a *App) ListRuleAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var where string
+	var args []any
+	if v := query.Get("rule_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			httpx.SendValidationError(w, r, httpx.FieldError{Field: "rule_id", Code: httpx.FieldInvalid, Message: "Invalid rule ID"})
+			return
+		}
+		where = ` WHERE rule_id = ?`
+		args = append(args, id)
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize < 1 || pageSize > maxAuditPageSize {
+		pageSize = defaultAuditPageSize
+	}
+	var total int
+	if err := a.db.QueryRowContext(r.Context(), `SELECT COUNT(*) FROM rule_audit`+where, args...).Scan(&total); err != nil {
+		httpx.SendProblem(w, r, errDatabase)
+		return
+	}
+
+	rows, err := a.db.QueryContext(r.Context(), `SELECT id, rule_id, action, actor, old_value, new_value, created_at FROM rule_audit`+where+
+		` ORDER BY id DESC LIMIT ? OFFSET ?`, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		httpx.SendProblem(w, r, errDatabase)
+		return
+	}
+	defer rows.Close()
+	entries := []RuleAudit{}
+	for rows.Next() {
+		var e RuleAudit
+		var oldValue, newValue sql.NullString
+		if err := rows.Scan(&e.ID, &e.RuleID, &e.Action, &e.Actor, &oldValue, &newValue, &e.CreatedAt); err != nil {
+			httpx.SendProblem(w, r, errDatabase)
+			return
+		}
+		e.Old, e.New = auditRule(oldValue), auditRule(newValue)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.SendProblem(w, r, errDatabase)
+		return
+	}
+	httpx.SendPage(w, http.StatusOK, entries, httpx.NewPagination(page, pageSize, total))
+}
+
+func auditRule(value sql.NullString) *Rule {
+	if !value.Valid {
+		return nil
+	}
+	var rule Rule
+	if err := json.Unmarshal([]byte(value.String), &rule); err != nil {
+		return nil
+	}
+	return &rule
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"pkg/httpx"
+)
+
+func doRulesRequest(t *testing.T, app *App, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testModeratorToken)
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func decodeData[T any](t *testing.T, rr *httptest.ResponseRecorder) T {
+	t.Helper()
+	var resp struct {
+		Data T `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Некорректный ответ %s: %v", rr.Body.String(), err)
+	}
+	return resp.Data
+}
+
+func checkStatus(t *testing.T, app *App, text string) int {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text":"`+text+`"}`)))
+	return rr.Code
+}
+
+func TestRulesSeededFromConfig(t *testing.T) {
+	cfg := testConfig(t)
+	app := newTestApp(t, cfg)
+
+	rules := decodeData[[]Rule](t, doRulesRequest(t, app, http.MethodGet, "/rules", ""))
+	if len(rules) != len(cfg.ForbiddenWords) || rules[0].Pattern != cfg.ForbiddenWords[0] || rules[0].Action != ActionBlock || !rules[0].Enabled {
+		t.Fatalf("Словарь должен заполняться из forbidden_words: %+v", rules)
+	}
+
+	// После изменений через API конфигурация не возвращает удаленные правила
+	doRulesRequest(t, app, http.MethodDelete, "/rules/"+strconv.Itoa(rules[0].ID), "")
+	app.db.Close()
+	app = newTestApp(t, cfg)
+	if rules := decodeData[[]Rule](t, doRulesRequest(t, app, http.MethodGet, "/rules", "")); len(rules) != len(cfg.ForbiddenWords)-1 {
+		t.Errorf("Удаленное правило не должно появляться после перезапуска: %+v", rules)
+	}
+}
+
+func TestRulesCRUDReloadsMatcher(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	version := app.matcher.Load().Version
+
+	if code := checkStatus(t, app, "купите виагру"); code != http.StatusOK {
+		t.Fatalf("До добавления правила текст должен проходить, получен %d", code)
+	}
+	rr := doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"виагр","severity":"high","language":"RU"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	rule := decodeData[Rule](t, rr)
+	if rule.ID == 0 || rule.Kind != RuleTerm || rule.Action != ActionBlock || rule.Language != "ru" || !rule.Enabled {
+		t.Errorf("Неверные значения по умолчанию: %+v", rule)
+	}
+	if app.matcher.Load().Version <= version {
+		t.Error("Изменение правил должно увеличивать версию словаря")
+	}
+	if code := checkStatus(t, app, "купите ВИАГРУ"); code != http.StatusBadRequest {
+		t.Errorf("Новое правило должно применяться сразу, получен %d", code)
+	}
+
+	path := "/rules/" + strconv.Itoa(rule.ID)
+	if rr := doRulesRequest(t, app, http.MethodPut, path, `{"pattern":"виагр","enabled":false}`); rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if code := checkStatus(t, app, "купите виагру"); code != http.StatusOK {
+		t.Errorf("Выключенное правило не должно применяться, получен %d", code)
+	}
+	if got := decodeData[Rule](t, doRulesRequest(t, app, http.MethodGet, path, "")); got.Enabled || !got.CreatedAt.Equal(rule.CreatedAt) {
+		t.Errorf("Изменение должно сохраняться: %+v", got)
+	}
+
+	if rr := doRulesRequest(t, app, http.MethodDelete, path, ""); rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
+	}
+	if rr := doRulesRequest(t, app, http.MethodGet, path, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Удаленное правило должно отсутствовать, получен %d", rr.Code)
+	}
+	if rr := doRulesRequest(t, app, http.MethodPut, path, `{"pattern":"x"}`); rr.Code != http.StatusNotFound {
+		t.Errorf("Изменение отсутствующего правила: ожидался %d, получен %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestRulesValidation(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+
+	rr := doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"(","kind":"regex","severity":"extreme","action":"ban"}`)
+	var p httpx.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{}
+	for _, e := range p.Errors {
+		fields[e.Field] = e.Code
+	}
+	if rr.Code != http.StatusBadRequest || fields["pattern"] != httpx.FieldInvalid || fields["severity"] != httpx.FieldUnknown || fields["action"] != httpx.FieldUnknown {
+		t.Errorf("Ожидались ошибки pattern, severity и action, получено %d %+v", rr.Code, p)
+	}
+	if rr := doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"  "}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Пустой шаблон: ожидался статус %d, получен %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestRuleActions(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"дурак","action":"mask","severity":"low"}`)
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"казин[оа]","kind":"regex","action":"queue"}`)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text":"Сам дурак, иди в Казино"}`)))
+	result := decodeData[CheckResult](t, rr)
+	if rr.Code != http.StatusOK || result.Verdict != VerdictQueue || len(result.Matches) != 2 {
+		t.Fatalf("Правило queue должно отправлять на модерацию, mask — попадать в совпадения: %d %+v", rr.Code, result)
+	}
+	if result.Matches[0].Action != ActionMask || result.Matches[1].Action != ActionQueue {
+		t.Errorf("Совпадения должны идти в порядке правил: %+v", result.Matches)
+	}
+	text := "Сам дурак, иди в Казино"
+	if len(result.Mask) != 1 || text[result.Mask[0].Start:result.Mask[0].End] != "дурак" {
+		t.Errorf("Скрыть нужно только совпадения правил mask: %+v", result.Mask)
+	}
+}
+
+func TestRuleAudit(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	rule := decodeData[Rule](t, doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"реклама"}`))
+	path := "/rules/" + strconv.Itoa(rule.ID)
+	doRulesRequest(t, app, http.MethodPut, path, `{"pattern":"реклама","action":"queue"}`)
+	doRulesRequest(t, app, http.MethodDelete, path, "")
+
+	entries := decodeData[[]RuleAudit](t, doRulesRequest(t, app, http.MethodGet, "/rules/audit?rule_id="+strconv.Itoa(rule.ID), ""))
+	if len(entries) != 3 {
+		t.Fatalf("Ожидалось 3 записи аудита, получено %d", len(entries))
+	}
+	del, upd, create := entries[0], entries[1], entries[2]
+	if create.Action != AuditCreate || create.Old != nil || create.New == nil || create.Actor != "anna" {
+		t.Errorf("Неверная запись о создании: %+v", create)
+	}
+	if upd.Action != AuditUpdate || upd.Old.Action != ActionBlock || upd.New.Action != ActionQueue {
+		t.Errorf("Запись об изменении должна хранить состояние до и после: %+v", upd)
+	}
+	if del.Action != AuditDelete || del.Old == nil || del.New != nil {
+		t.Errorf("Неверная запись об удалении: %+v", del)
+	}
+
+	all := decodeData[[]RuleAudit](t, doRulesRequest(t, app, http.MethodGet, "/rules/audit", ""))
+	if last := all[len(all)-1]; len(all) != len(DefaultConfig().ForbiddenWords)+3 || last.Actor != configActor {
+		t.Errorf("Журнал должен включать перенос правил из конфигурации: %+v", all)
+	}
+	if rr := doRulesRequest(t, app, http.MethodGet, "/rules/audit?rule_id=x", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Некорректный rule_id: ожидался статус %d, получен %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestRulesRequireModerator(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	send := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/rules", strings.NewReader(`{"pattern":"реклама"}`))
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := send("", ""); code != http.StatusUnauthorized {
+		t.Errorf("Без токена: ожидался статус %d, получен %d", http.StatusUnauthorized, code)
+	}
+	// Имя модератора из заголовка не подтверждено и не принимается
+	if code := send("X-Moderator", "anna"); code != http.StatusUnauthorized {
+		t.Errorf("С заголовком X-Moderator: ожидался статус %d, получен %d", http.StatusUnauthorized, code)
+	}
+	if code := send("Authorization", "Bearer чужой"); code != http.StatusUnauthorized {
+		t.Errorf("С неверным токеном: ожидался статус %d, получен %d", http.StatusUnauthorized, code)
+	}
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/rules/version", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Чтение правил не требует токена, получен статус %d", rr.Code)
+	}
+
+	cfg := testConfig(t)
+	cfg.Moderators = nil
+	app = newTestApp(t, cfg)
+	if code := send("Authorization", "Bearer "+testModeratorToken); code != http.StatusForbidden {
+		t.Errorf("Без настроенных модераторов: ожидался статус %d, получен %d", http.StatusForbidden, code)
+	}
+}
+
+func TestSaveRuleMissingRule(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	rule := decodeData[Rule](t, doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"реклама"}`))
+	doRulesRequest(t, app, http.MethodDelete, "/rules/"+strconv.Itoa(rule.ID), "")
+
+	// Правило удалено между чтением и изменением: изменение не сохраняется и не попадает в журнал
+	for _, action := range []string{AuditUpdate, AuditDelete} {
+		next := &Rule{Pattern: "реклама", Kind: RuleTerm, Severity: SeverityMedium, Action: ActionQueue, Enabled: true}
+		if action == AuditDelete {
+			next = nil
+		}
+		if _, problem := app.saveRule(context.Background(), "anna", action, rule.ID, next); problem != errRuleNotFound {
+			t.Errorf("%s: ожидалась ошибка %v, получено %v", action, errRuleNotFound, problem)
+		}
+	}
+	var entries int
+	app.db.QueryRow(`SELECT COUNT(*) FROM rule_audit WHERE rule_id = ?`, rule.ID).Scan(&entries)
+	if entries != 2 {
+		t.Errorf("В журнале должны остаться только создание и удаление, записей: %d", entries)
+	}
+}
+
+func TestRuleAuditPagination(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"реклама"}`)
+
+	rr := doRulesRequest(t, app, http.MethodGet, "/rules/audit?page=2&page_size=3", "")
+	var resp struct {
+		Data       []RuleAudit       `json:"data"`
+		Pagination *httpx.Pagination `json:"pagination"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// Три правила из конфигурации и одно созданное: на второй странице — самая старая запись
+	want := httpx.Pagination{Page: 2, PageSize: 3, Total: 4, PageCount: 2}
+	if rr.Code != http.StatusOK || resp.Pagination == nil || *resp.Pagination != want {
+		t.Fatalf("Ожидалась пагинация %+v, получено %d %s", want, rr.Code, rr.Body.String())
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Actor != configActor {
+		t.Errorf("На второй странице ожидалась первая запись журнала: %+v", resp.Data)
+	}
+}
+
+func TestSaveRuleKeepsDictionaryConsistent(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	// Правило, из которого нельзя собрать словарь, попало в базу в обход API
+	res, err := app.db.Exec(`INSERT INTO rules (pattern, kind, severity, action, enabled, created_at, updated_at) VALUES ('(', 'regex', 'high', 'block', 1, datetime('now'), datetime('now'))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badID, _ := res.LastInsertId()
+	version := app.matcher.Load().Version
+
+	if rr := doRulesRequest(t, app, http.MethodPost, "/rules", `{"pattern":"реклама"}`); rr.Code != http.StatusInternalServerError {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusInternalServerError, rr.Code)
+	}
+	var rules, audit int
+	app.db.QueryRow(`SELECT COUNT(*) FROM rules WHERE pattern = 'реклама'`).Scan(&rules)
+	app.db.QueryRow(`SELECT COUNT(*) FROM rule_audit`).Scan(&audit)
+	if rules != 0 || audit != len(DefaultConfig().ForbiddenWords) || app.matcher.Load().Version != version {
+		t.Errorf("Изменение, с которым нельзя собрать словарь, не должно сохраняться: правил %d, записей аудита %d", rules, audit)
+	}
+
+	// Удаление сломанного правила восстанавливает словарь и сразу действует
+	if rr := doRulesRequest(t, app, http.MethodDelete, "/rules/"+strconv.FormatInt(badID, 10), ""); rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if app.matcher.Load().Version == version {
+		t.Error("После удаления правила версия словаря должна измениться")
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Решения проверки на спам
+const (
+	VerdictAccept = "accept"
+	VerdictQueue  = "queue"
+	VerdictReject = "reject"
+)
+
+// Submission — проверяемый комментарий; Key — ключ идемпотентности запроса, по которому правила с состоянием
+// узнают повтор уже учтенной отправки (клиент повторил запрос после сбоя или таймаута)
+type Submission struct {
+	Text   string
+	Author string
+	Key    string
+	At     time.Time
+}
+
+// SpamSignal — вклад одного правила в оценку спама
+type SpamSignal struct {
+	Rule   string  `json:"rule"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// SpamResult — оценка спама от 0 до 1, решение и сработавшие правила
+type SpamResult struct {
+	Verdict   string       `json:"verdict"`
+	SpamScore float64      `json:"spam_score"`
+	Signals   []SpamSignal `json:"signals,omitempty"`
+}
+
+// SpamRule — правило оценки спама. Score возвращает вклад правила от 0 до 1 и пояснение;
+// правила с состоянием (повторы, частота) учитывают каждую проверенную отправку, но повтор с уже
+// встречавшимся ключом идемпотентности оценивают как исходную отправку, не сравнивая с ней самой.
+type SpamRule interface {
+	Name() string
+	Score(s Submission) (float64, string)
+}
+
+// SpamPipeline — суммирует оценки правил и выносит решение по порогам
+type SpamPipeline struct {
+	rules           []SpamRule
+	queueThreshold  float64
+	rejectThreshold float64
+	now             func() time.Time
+}
+
+// NewSpamPipeline — конвейер из правил по умолчанию
+func NewSpamPipeline(cfg SpamConfig) *SpamPipeline {
+	return NewSpamPipelineWithRules(cfg,
+		newLinkRule(cfg.MaxLinks, cfg.BlockedDomains),
+		shapeRule{},
+		newDuplicateRule(cfg.DuplicateWindow, cfg.DuplicateHistory),
+		newVelocityRule(cfg.VelocityWindow, cfg.VelocityLimit),
+	)
+}
+
+// NewSpamPipelineWithRules — конвейер с заданным набором правил
+func NewSpamPipelineWithRules(cfg SpamConfig, rules ...SpamRule) *SpamPipeline {
+	return &SpamPipeline{
+		rules:           rules,
+		queueThreshold:  cfg.QueueThreshold,
+		rejectThreshold: cfg.RejectThreshold,
+		now:             time.Now,
+	}
+}
+
+// Evaluate — оценивает комментарий всеми правилами; key — ключ идемпотентности запроса или пустая строка
+func (p *SpamPipeline) Evaluate(text, author, key string) SpamResult {
+	s := Submission{Text: text, Author: author, Key: key, At: p.now()}
+	var result SpamResult
+	for _, rule := range p.rules {
+		score, detail := rule.Score(s)
+		if score <= 0 {
+			continue
+		}
+		score = min(score, 1)
+		result.SpamScore += score
+		result.Signals = append(result.Signals, SpamSignal{Rule: rule.Name(), Score: score, Detail: detail})
+	}
+	result.SpamScore = min(result.SpamScore, 1)
+	result.Verdict = spamVerdict(result.SpamScore, p.queueThreshold, p.rejectThreshold)
+	return result
+}
+
+// spamVerdict — решение по оценке спама и порогам модерации и отказа
+func spamVerdict(score, queue, reject float64) string {
+	switch {
+	case score >= reject:
+		return VerdictReject
+	case score >= queue:
+		return VerdictQueue
+	default:
+		return VerdictAccept
+	}
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// linkRule — ссылки: каждая немного повышает оценку, сверх maxLinks — заметно,
+// ссылка на домен из черного списка — сразу максимум
+type linkRule struct {
+	maxLinks int
+	blocked  []string
+}
+
+func newLinkRule(maxLinks int, blocked []string) linkRule {
+	r := linkRule{maxLinks: maxLinks}
+	for _, d := range blocked {
+		r.blocked = append(r.blocked, strings.ToLower(strings.TrimPrefix(d, ".")))
+	}
+	return r
+}
+
+func (linkRule) Name() string { return "links" }
+
+func (r linkRule) Score(s Submission) (float64, string) {
+	links := linkPattern.FindAllString(s.Text, -1)
+	if len(links) == 0 {
+		return 0, ""
+	}
+	for _, link := range links {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, d := range r.blocked {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return 1, "blocked domain " + d
+			}
+		}
+	}
+	score := 0.1 * float64(len(links))
+	if extra := len(links) - r.maxLinks; extra > 0 {
+		score += 0.3 * float64(extra)
+	}
+	return score, fmt.Sprintf("%d links", len(links))
+}
+
+// Параметры правила shapeRule
+const (
+	minCapsLetters = 10
+	maxCapsRatio   = 0.7
+	maxCharRepeat  = 5
+)
+
+// shapeRule — текст заглавными буквами и длинные повторы одного символа («!!!!!!», «ааааааа»)
+type shapeRule struct{}
+
+func (shapeRule) Name() string { return "shape" }
+
+func (shapeRule) Score(s Submission) (float64, string) {
+	var letters, upper, run, maxRun int
+	var prev rune
+	for _, r := range s.Text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+		if r == prev && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		maxRun = max(maxRun, run)
+		prev = r
+	}
+
+	var score float64
+	var details []string
+	if letters >= minCapsLetters && float64(upper)/float64(letters) > maxCapsRatio {
+		score += 0.3
+		details = append(details, fmt.Sprintf("caps ratio %.2f", float64(upper)/float64(letters)))
+	}
+	if maxRun > maxCharRepeat {
+		score += 0.3
+		details = append(details, fmt.Sprintf("%d repeated characters", maxRun))
+	}
+	return score, strings.Join(details, ", ")
+}
+
+// Параметры правила duplicateRule
+const (
+	minDuplicateWords  = 5
+	maxSimhashDistance = 10
+)
+
+// duplicateRule — почти одинаковые тексты за последнее время, в том числе от разных авторов.
+// Тексты сравниваются по simhash, поэтому мелкие правки не спасают от обнаружения.
+// Короткие тексты («спасибо», «согласен») не учитываются.
+type duplicateRule struct {
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int
+	history []simhashEntry
+}
+
+type simhashEntry struct {
+	hash uint64
+	key  string
+	at   time.Time
+}
+
+func newDuplicateRule(window time.Duration, limit int) *duplicateRule {
+	return &duplicateRule{window: window, limit: limit}
+}
+
+func (*duplicateRule) Name() string { return "duplicate" }
+
+func (r *duplicateRule) Score(s Submission) (float64, string) {
+	words := textWords(s.Text)
+	if len(words) < minDuplicateWords {
+		return 0, ""
+	}
+	hash := simhash(words)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cutoff := s.At.Add(-r.window)
+	kept := r.history[:0]
+	matches := 0
+	seen := false
+	for _, e := range r.history {
+		if e.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, e)
+		if s.Key != "" && e.key == s.Key {
+			seen = true
+			continue
+		}
+		if bits.OnesCount64(e.hash^hash) <= maxSimhashDistance {
+			matches++
+		}
+	}
+	r.history = kept
+	if !seen {
+		r.history = append(r.history, simhashEntry{hash: hash, key: s.Key, at: s.At})
+	}
+	if len(r.history) > r.limit {
+		r.history = r.history[len(r.history)-r.limit:]
+	}
+
+	if matches == 0 {
+		return 0, ""
+	}
+	return 0.4 + 0.2*float64(matches-1), fmt.Sprintf("%d similar comments", matches)
+}
+
+// textWords — слова текста в нижнем регистре
+func textWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// simhash — 64-битный отпечаток текста по триграммам символов: у похожих текстов отпечатки
+// различаются в немногих битах (правка одного слова — 5–7 бит, разные тексты — около 30)
+func simhash(words []string) uint64 {
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	text := []rune(strings.Join(words, " "))
+	for i := 0; i+3 <= len(text); i++ {
+		add(string(text[i : i+3]))
+	}
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// velocityRule — автор, оставляющий больше limit комментариев за window.
+// Анонимные комментарии не учитываются.
+type velocityRule struct {
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int
+	authors map[string][]velocityEntry
+}
+
+type velocityEntry struct {
+	key string
+	at  time.Time
+}
+
+func newVelocityRule(window time.Duration, limit int) *velocityRule {
+	return &velocityRule{window: window, limit: limit, authors: make(map[string][]velocityEntry)}
+}
+
+func (*velocityRule) Name() string { return "velocity" }
+
+func (r *velocityRule) Score(s Submission) (float64, string) {
+	author := strings.ToLower(strings.TrimSpace(s.Author))
+	if author == "" {
+		return 0, ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cutoff := s.At.Add(-r.window)
+	// Устаревшие отметки других авторов вычищаются, чтобы карта не росла без ограничений
+	for a, times := range r.authors {
+		if len(times) > 0 && times[len(times)-1].at.Before(cutoff) {
+			delete(r.authors, a)
+		}
+	}
+	times := r.authors[author]
+	for len(times) > 0 && times[0].at.Before(cutoff) {
+		times = times[1:]
+	}
+	if !slices.ContainsFunc(times, func(e velocityEntry) bool { return s.Key != "" && e.key == s.Key }) {
+		times = append(times, velocityEntry{key: s.Key, at: s.At})
+	}
+	r.authors[author] = times
+
+	extra := len(times) - r.limit
+	if extra <= 0 {
+		return 0, ""
+	}
+	return 0.5 + 0.1*float64(extra-1), fmt.Sprintf("%d comments in %s", len(times), r.window)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"math/bits"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pkg/idempotency"
+)
+
+func testSpamPipeline() *SpamPipeline {
+	cfg := DefaultConfig().Spam
+	cfg.BlockedDomains = []string{"spam.example"}
+	return NewSpamPipeline(cfg)
+}
+
+func signal(res SpamResult, rule string) *SpamSignal {
+	for i := range res.Signals {
+		if res.Signals[i].Rule == rule {
+			return &res.Signals[i]
+		}
+	}
+	return nil
+}
+
+func TestSpamLinks(t *testing.T) {
+	p := testSpamPipeline()
+
+	if res := p.Evaluate("Подробности на https://example.com/news", "", ""); res.Verdict != VerdictAccept || signal(res, "links") == nil {
+		t.Errorf("Одна ссылка повышает оценку, но не блокирует комментарий: %+v", res)
+	}
+	res := p.Evaluate("Заходите: http://a.example www.b.example https://c.example", "", "")
+	if res.Verdict != VerdictQueue {
+		t.Errorf("Много ссылок — на модерацию: %+v", res)
+	}
+	res = p.Evaluate("Скидки тут https://shop.SPAM.example/promo", "", "")
+	if res.Verdict != VerdictReject || signal(res, "links").Detail != "blocked domain spam.example" {
+		t.Errorf("Ссылка на запрещенный домен должна отклоняться: %+v", res)
+	}
+}
+
+func TestSpamShape(t *testing.T) {
+	p := testSpamPipeline()
+
+	if res := p.Evaluate("Обычный комментарий, ничего особенного", "", ""); res.SpamScore != 0 || res.Verdict != VerdictAccept {
+		t.Errorf("Обычный текст не должен получать оценку: %+v", res)
+	}
+	res := p.Evaluate("ПОКУПАЙТЕ СРОЧНО!!!!!!!!", "", "")
+	if sig := signal(res, "shape"); sig == nil || sig.Score < 0.6 || res.Verdict != VerdictQueue {
+		t.Errorf("Заглавные буквы и повторы символов должны повышать оценку: %+v", res)
+	}
+	if res := p.Evaluate("NASA и ESA", "", ""); signal(res, "shape") != nil {
+		t.Errorf("Короткие аббревиатуры не должны считаться криком: %+v", res)
+	}
+}
+
+func TestSpamDuplicates(t *testing.T) {
+	p := testSpamPipeline()
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	text := "Лучший способ заработать дома без вложений и опыта работы, пишите в личные сообщения"
+	if res := p.Evaluate(text, "a", ""); signal(res, "duplicate") != nil {
+		t.Fatalf("Первый текст не может быть повтором: %+v", res)
+	}
+	// Небольшая правка текста и другой автор не мешают найти повтор
+	res := p.Evaluate(strings.Replace(text, "Лучший", "Лучшiй", 1), "b", "")
+	if signal(res, "duplicate") == nil {
+		t.Errorf("Почти одинаковый текст должен считаться повтором: %+v", res)
+	}
+	if res := p.Evaluate("Совсем другой комментарий про погоду в Москве на выходных", "c", ""); signal(res, "duplicate") != nil {
+		t.Errorf("Разные тексты не должны считаться повтором: %+v", res)
+	}
+	p.Evaluate("Спасибо за новость", "a", "")
+	if res := p.Evaluate("Спасибо за новость", "b", ""); signal(res, "duplicate") != nil {
+		t.Errorf("Короткие тексты не проверяются на повторы: %+v", res)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if res := p.Evaluate(text, "d", ""); signal(res, "duplicate") != nil {
+		t.Errorf("Повторы ищутся только в окне duplicate_window: %+v", res)
+	}
+}
+
+func TestSimhashDistance(t *testing.T) {
+	a := simhash(textWords("новости о выборах в городской совет прошли спокойно"))
+	b := simhash(textWords("новости о выборах в городской совет прошли спокойно!"))
+	c := simhash(textWords("футбольный клуб подписал контракт с новым тренером"))
+	if a != b {
+		t.Error("Пунктуация не должна влиять на отпечаток")
+	}
+	if bits.OnesCount64(a^c) <= maxSimhashDistance {
+		t.Error("Отпечатки разных текстов должны заметно различаться")
+	}
+}
+
+func TestSpamVelocity(t *testing.T) {
+	p := testSpamPipeline()
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	limit := DefaultConfig().Spam.VelocityLimit
+
+	for i := 0; i < limit; i++ {
+		if res := p.Evaluate("комментарий номер "+strings.Repeat("x", i+1), "Anna", ""); signal(res, "velocity") != nil {
+			t.Fatalf("Комментарий %d в пределах лимита: %+v", i+1, res)
+		}
+		p.Evaluate("анонимный", "", "")
+	}
+	if res := p.Evaluate("еще один", "anna ", ""); signal(res, "velocity") == nil || res.Verdict != VerdictQueue {
+		t.Errorf("Превышение частоты должно отправлять на модерацию: %+v", res)
+	}
+	if res := p.Evaluate("еще один", "boris", ""); signal(res, "velocity") != nil {
+		t.Errorf("Частота считается для каждого автора отдельно: %+v", res)
+	}
+
+	now = now.Add(time.Minute + time.Second)
+	if res := p.Evaluate("через минуту", "anna", ""); signal(res, "velocity") != nil {
+		t.Errorf("После окна счетчик сбрасывается: %+v", res)
+	}
+}
+
+func TestCheckTextReturnsSpamScore(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Spam.BlockedDomains = []string{"spam.example"}
+	app := newTestApp(t, cfg)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text":"Скидки http://spam.example","author":"bot"}`)))
+	var resp struct {
+		Data SpamResult `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || resp.Data.Verdict != VerdictReject || resp.Data.SpamScore != 1 || len(resp.Data.Signals) != 1 {
+		t.Errorf("Неверный ответ: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSpamRetryWithIdempotencyKey(t *testing.T) {
+	p := testSpamPipeline()
+	limit := DefaultConfig().Spam.VelocityLimit
+
+	// Повтор запроса после сбоя шлюза не должен считаться повтором самого себя: с одной ссылкой
+	// оценка дошла бы до порога модерации и статус комментария изменился бы
+	text := "Подробности о выборах в городской совет на сайте https://example.com/news"
+	first := p.Evaluate(text, "anna", "key-1")
+	for i := 0; i < limit+1; i++ {
+		retry := p.Evaluate(text, "anna", "key-1")
+		if retry.SpamScore != first.SpamScore || retry.Verdict != first.Verdict {
+			t.Fatalf("Повтор %d с тем же ключом должен получить исходную оценку %+v, получено %+v", i+1, first, retry)
+		}
+	}
+
+	// Тот же текст с другим ключом — новый комментарий
+	if res := p.Evaluate(text, "anna", "key-2"); signal(res, "duplicate") == nil {
+		t.Errorf("Новая отправка того же текста должна считаться повтором: %+v", res)
+	}
+}
+
+func TestCheckTextRetryWithIdempotencyKey(t *testing.T) {
+	app := newTestApp(t, testConfig(t))
+	check := func(key string) SpamResult {
+		req := httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text":"Подробности о выборах в городской совет на сайте https://example.com/news","author":"anna"}`))
+		req.Header.Set(idempotency.Header, key)
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		var resp struct {
+			Data SpamResult `json:"data"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp.Data
+	}
+
+	first := check("key-1")
+	if retry := check("key-1"); retry.Verdict != VerdictAccept || retry.SpamScore != first.SpamScore {
+		t.Errorf("Повтор с тем же Idempotency-Key должен получить исходную оценку %+v, получено %+v", first, retry)
+	}
+	if res := check("key-2"); res.Verdict != VerdictQueue {
+		t.Errorf("Тот же текст с другим ключом должен считаться повтором: %+v", res)
+	}
+}
@@ -1,20 +1,215 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"pkg/httpx"
+	"pkg/server"
 )
 
 func TestHealthCheck(t *testing.T) {
 	req, _ := http.NewRequest("GET", "/health", nil)
 	rr := httptest.NewRecorder()
 
-	config := Config{Port: "8080"}
-	app := NewApp(config)
+	app := newTestApp(t)
 	app.router.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusOK {
 		t.Errorf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestCreateCommentValidationProblem(t *testing.T) {
+	app := newTestApp(t)
+
+	body := `{"news_id":0,"author":"` + strings.Repeat("a", 101) + `","text":"test"}`
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body)))
+
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != httpx.ProblemContentType {
+		t.Fatalf("Ожидалась ошибка %d в формате problem+json, получено %d %s", http.StatusBadRequest, rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var p httpx.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Code != httpx.CodeValidationFailed || p.Instance != "/comments" || len(p.Errors) != 2 {
+		t.Errorf("Неверное описание ошибки: %+v", p)
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(`{"news_id":1,"parent_id":42,"text":"test"}`)))
+	json.Unmarshal(rr.Body.Bytes(), &p)
+	if rr.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "parent_id" {
+		t.Errorf("Несуществующий родитель должен давать ошибку поля parent_id: %d %+v", rr.Code, p)
+	}
+}
+
+func TestCreateCommentParentFromAnotherNews(t *testing.T) {
+	app := newTestApp(t)
+	parent := createTestComment(t, app, `{"news_id":1,"text":"родитель"}`)
+
+	body := fmt.Sprintf(`{"news_id":2,"parent_id":%d,"text":"ответ"}`, parent.ID)
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body)))
+	var p httpx.Problem
+	json.Unmarshal(rr.Body.Bytes(), &p)
+	if rr.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "parent_id" || p.Errors[0].Code != httpx.FieldInvalid {
+		t.Errorf("Родитель из другой новости должен давать ошибку поля parent_id: %d %+v", rr.Code, p)
+	}
+
+	createTestComment(t, app, fmt.Sprintf(`{"news_id":1,"parent_id":%d,"text":"ответ"}`, parent.ID))
+}
+
+func TestCreateCommentSanitizesText(t *testing.T) {
+	app := newTestApp(t)
+	// Управляющий символ и некорректный UTF-8 удаляются, HTML экранируется, Markdown размечается
+	created := createTestComment(t, app, `{"news_id":1,"author":"an\u0000na","text":"**Важно**\u0007 <script>alert(1)</script>\r\nсм. [тут](https://example.com)\u202e"}`)
+	wantText := "**Важно** <script>alert(1)</script>\nсм. [тут](https://example.com)"
+	wantHTML := `<p><strong>Важно</strong> &lt;script&gt;alert(1)&lt;/script&gt;<br>см. <a href="https://example.com" rel="nofollow noopener ugc">тут</a></p>`
+	if created.Author != "anna" || created.Text != wantText || created.HTML != wantHTML {
+		t.Errorf("Неверно очищенный комментарий: %+v", created)
+	}
+
+	comments, err := listComments(context.Background(), 1)
+	if err != nil || len(comments) != 1 || comments[0].Text != wantText || comments[0].HTML != wantHTML {
+		t.Errorf("Список должен возвращать очищенный текст и HTML: %+v %v", comments, err)
+	}
+}
+
+func TestCreateCommentLengthInCharacters(t *testing.T) {
+	app := newTestApp(t)
+	post := func(text string) (int, httpx.Problem) {
+		body, _ := json.Marshal(map[string]any{"news_id": 1, "text": text})
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", bytes.NewReader(body)))
+		var p httpx.Problem
+		json.Unmarshal(rr.Body.Bytes(), &p)
+		return rr.Code, p
+	}
+
+	// Лимит в 1000 символов считается в буквах, а не в байтах: 1000 русских букв — это 2000 байт
+	accepted := []string{
+		strings.Repeat("ж", 1000),
+		strings.Repeat("\U0001f44d\U0001f3fd", 1000),
+		strings.Repeat("е\u0308", 1000),
+		"  " + strings.Repeat("ж", 1000) + "\n\t ",
+	}
+	for _, text := range accepted {
+		if code, p := post(text); code != http.StatusOK {
+			t.Errorf("Текст из 1000 символов (%d байт) должен приниматься: %d %+v", len(text), code, p)
+		}
+	}
+
+	for _, text := range []string{strings.Repeat("ж", 1001), strings.Repeat("\U0001f600", 1001)} {
+		if code, p := post(text); code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Code != httpx.FieldTooLong {
+			t.Errorf("Текст из 1001 символа должен отклоняться как too_long: %d %+v", code, p)
+		}
+	}
+
+	for _, text := range []string{"", "   \n\t", "\u0000\u202e"} {
+		if code, p := post(text); code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Code != httpx.FieldRequired {
+			t.Errorf("Пустой текст %q должен отклоняться как required: %d %+v", text, code, p)
+		}
+	}
+}
+
+func TestCreateCommentNormalizesText(t *testing.T) {
+	app := newTestApp(t)
+	// «ё» и «й» из буквы и комбинирующего знака сохраняются одним символом (NFC), пробелы по краям убираются
+	created := createTestComment(t, app, `{"news_id":1,"author":" Алёна ","text":"  е\u0308ж и и\u0306од\n"}`)
+	if created.Text != "\u0451ж и \u0439од" || created.Author != "Алёна" {
+		t.Errorf("Текст должен сохраняться в форме NFC без пробелов по краям: %+v", created)
+	}
+}
+
+func TestGetCommentsForSeveralNews(t *testing.T) {
+	app := newTestApp(t)
+	createTestComment(t, app, `{"news_id":1,"text":"первый"}`)
+	createTestComment(t, app, `{"news_id":2,"text":"второй"}`)
+	createTestComment(t, app, `{"news_id":3,"text":"третий"}`)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/comments?news_id=1,3", nil))
+	var resp struct {
+		Data []Comment `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || len(resp.Data) != 2 {
+		t.Fatalf("Ожидалось 2 комментария к новостям 1 и 3, получено %d %s", rr.Code, rr.Body.String())
+	}
+	for _, c := range resp.Data {
+		if c.NewsID == 2 {
+			t.Errorf("Лишний комментарий к новости 2: %+v", c)
+		}
+	}
+
+	ids := strings.TrimSuffix(strings.Repeat("1,", maxNewsIDs+1), ",")
+	for _, query := range []string{"news_id=1,abc", "news_id=", "news_id=" + ids} {
+		rr = httptest.NewRecorder()
+		app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/comments?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: ожидался статус %d, получен %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestCountComments(t *testing.T) {
+	app := newTestApp(t)
+	createTestComment(t, app, `{"news_id":1,"text":"первый"}`)
+	createTestComment(t, app, `{"news_id":1,"text":"второй"}`)
+	createTestComment(t, app, `{"news_id":2,"text":"третий"}`)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/comments/counts?news_id=1,2,3", nil))
+	var resp struct {
+		Data map[int]int `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || len(resp.Data) != 3 || resp.Data[1] != 2 || resp.Data[2] != 1 || resp.Data[3] != 0 {
+		t.Errorf("Неверное число комментариев: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/comments/counts?news_id=0", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус %d, получен %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestReadinessChecksDatabase(t *testing.T) {
+	app := newTestApp(t)
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	db.Close()
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report server.ReadinessReport
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if rr.Code != http.StatusServiceUnavailable || report.Checks["database"] == "ok" {
+		t.Errorf("При недоступной базе ожидался статус %d, получено %d %+v", http.StatusServiceUnavailable, rr.Code, report)
+	}
+
+	rr = httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Проба живости не должна зависеть от базы, получен статус %d", rr.Code)
+	}
+}
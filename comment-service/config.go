@@ -0,0 +1,81 @@
+package main
+
+import (
+	"time"
+
+	"pkg/config"
+	"pkg/idempotency"
+	"pkg/server"
+)
+
+type Config struct {
+	Port           string                `yaml:"port" desc:"порт HTTP-сервера"`
+	GRPCPort       string                `yaml:"grpc_port" desc:"порт внутреннего gRPC API"`
+	DBPath         string                `yaml:"db_path" desc:"путь к файлу базы SQLite"`
+	IdempotencyTTL time.Duration         `yaml:"idempotency_ttl" desc:"сколько хранится ответ на запрос с Idempotency-Key"`
+	CensorURL      string                `yaml:"censor_service_url" desc:"адрес Censor Service для повторной проверки комментариев; пусто — проверка выключена"`
+	Rescan         RescanConfig          `yaml:"rescan"`
+	Limits         LimitsConfig          `yaml:"limits"`
+	Shutdown       server.ShutdownConfig `yaml:"shutdown"`
+}
+
+type LimitsConfig struct {
+	MaxTextLength   int `yaml:"max_text_length" desc:"максимальная длина текста комментария в символах"`
+	MaxAuthorLength int `yaml:"max_author_length" desc:"максимальная длина имени автора в символах"`
+	MaxSearchLength int `yaml:"max_search_length" desc:"максимальная длина поискового запроса в символах"`
+	DefaultPageSize int `yaml:"default_page_size" desc:"размер страницы поиска по умолчанию"`
+	MaxPageSize     int `yaml:"max_page_size" desc:"максимальный размер страницы поиска"`
+}
+
+// RescanConfig — повторная проверка комментариев после изменения словаря Censor Service
+type RescanConfig struct {
+	Interval  time.Duration `yaml:"interval" desc:"как часто проверять, не изменился ли словарь; 0 — только вручную"`
+	BatchSize int           `yaml:"batch_size" desc:"сколько комментариев отправлять в Censor Service за один запрос"`
+	Timeout   time.Duration `yaml:"timeout" desc:"таймаут запроса к Censor Service"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Port:           "8081",
+		GRPCPort:       "9081",
+		DBPath:         "./comments.db",
+		IdempotencyTTL: idempotency.DefaultTTL,
+		Rescan: RescanConfig{
+			Interval:  5 * time.Minute,
+			BatchSize: 500,
+			Timeout:   30 * time.Second,
+		},
+		Limits: LimitsConfig{
+			MaxTextLength:   1000,
+			MaxAuthorLength: 100,
+			MaxSearchLength: 200,
+			DefaultPageSize: 20,
+			MaxPageSize:     100,
+		},
+		Shutdown: server.DefaultShutdownConfig(),
+	}
+}
+
+func (c *Config) Validate() error {
+	var errs config.Errors
+	errs.Port("port", c.Port)
+	errs.Port("grpc_port", c.GRPCPort)
+	errs.Required("db_path", c.DBPath)
+	errs.PositiveDuration("idempotency_ttl", c.IdempotencyTTL)
+	if c.CensorURL != "" {
+		errs.URL("censor_service_url", c.CensorURL)
+	}
+	errs.NonNegativeDuration("rescan.interval", c.Rescan.Interval)
+	errs.Positive("rescan.batch_size", c.Rescan.BatchSize)
+	errs.PositiveDuration("rescan.timeout", c.Rescan.Timeout)
+	errs.Positive("limits.max_text_length", c.Limits.MaxTextLength)
+	errs.Positive("limits.max_author_length", c.Limits.MaxAuthorLength)
+	errs.Positive("limits.max_search_length", c.Limits.MaxSearchLength)
+	errs.Positive("limits.default_page_size", c.Limits.DefaultPageSize)
+	errs.Positive("limits.max_page_size", c.Limits.MaxPageSize)
+	if c.Limits.DefaultPageSize > c.Limits.MaxPageSize {
+		errs.Add("limits.default_page_size", "must not exceed limits.max_page_size (%d)", c.Limits.MaxPageSize)
+	}
+	c.Shutdown.Validate(&errs, "shutdown")
+	return errs.Err()
+}
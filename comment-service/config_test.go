@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Конфигурация по умолчанию должна быть корректной: %v", err)
+	}
+
+	cfg.DBPath = ""
+	cfg.Limits.MaxTextLength = 0
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "db_path:") || !strings.Contains(err.Error(), "limits.max_text_length:") {
+		t.Errorf("Ожидались ошибки db_path и limits.max_text_length, получено: %v", err)
+	}
+}
+
+func TestCommentLengthFromConfig(t *testing.T) {
+	cfg := testConfig(filepath.Join(t.TempDir(), "comments.db"))
+	cfg.Limits.MaxTextLength = 5
+	app := NewApp(cfg)
+	t.Cleanup(func() { db.Close() })
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest("POST", "/comments", strings.NewReader(`{"news_id":1,"text":"слишком длинный"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус %d, получен %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/commentpb"
+)
+
+// commentServer — внутренний gRPC API комментариев
+type commentServer struct {
+	commentpb.UnimplementedCommentServiceServer
+	app *App
+}
+
+func (s *commentServer) CreateComment(ctx context.Context, req *commentpb.CreateCommentRequest) (*commentpb.Comment, error) {
+	comment := Comment{
+		NewsID:       int(req.GetNewsId()),
+		Author:       req.GetAuthor(),
+		Text:         req.GetText(),
+		Status:       req.GetStatus(),
+		OriginalText: req.GetOriginalText(),
+		Language:     req.GetLanguage(),
+		Policy:       req.GetPolicy(),
+	}
+	if req.ParentId != nil {
+		parentID := int(req.GetParentId())
+		comment.ParentID = &parentID
+	}
+	comment, problem := s.app.createComment(ctx, comment)
+	if problem != nil {
+		return nil, grpcx.Error(problem)
+	}
+	return commentToProto(comment), nil
+}
+
+func (s *commentServer) ListComments(ctx context.Context, req *commentpb.ListCommentsRequest) (*commentpb.ListCommentsResponse, error) {
+	var newsIDs []int
+	if req.GetNewsId() != 0 || len(req.GetNewsIds()) == 0 {
+		newsIDs = append(newsIDs, int(req.GetNewsId()))
+	}
+	for _, id := range req.GetNewsIds() {
+		newsIDs = append(newsIDs, int(id))
+	}
+	if fieldErr := checkNewsIDs("news_id", newsIDs); fieldErr != nil {
+		return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{*fieldErr}))
+	}
+	comments, err := listComments(ctx, newsIDs...)
+	if err != nil {
+		return nil, grpcx.Error(errDatabase)
+	}
+	return commentsToProto(comments), nil
+}
+
+func (s *commentServer) CountComments(ctx context.Context, req *commentpb.CountCommentsRequest) (*commentpb.CountCommentsResponse, error) {
+	newsIDs := make([]int, len(req.GetNewsIds()))
+	for i, id := range req.GetNewsIds() {
+		newsIDs[i] = int(id)
+	}
+	if len(newsIDs) == 0 {
+		return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{{Field: "news_ids", Code: httpx.FieldRequired, Message: "news_ids is required"}}))
+	}
+	if fieldErr := checkNewsIDs("news_ids", newsIDs); fieldErr != nil {
+		return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{*fieldErr}))
+	}
+	counts, err := countComments(ctx, newsIDs...)
+	if err != nil {
+		return nil, grpcx.Error(errDatabase)
+	}
+	resp := &commentpb.CountCommentsResponse{Counts: make(map[int64]int32, len(counts))}
+	for id, n := range counts {
+		resp.Counts[int64(id)] = int32(n)
+	}
+	return resp, nil
+}
+
+var errDatabase = httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Database error")
+
+func (s *commentServer) SearchComments(ctx context.Context, req *commentpb.SearchCommentsRequest) (*commentpb.ListCommentsResponse, error) {
+	// Параметры разбираются тем же кодом, что и параметры HTTP-запроса
+	q := url.Values{}
+	for name, value := range map[string]string{
+		"q":      req.GetQ(),
+		"author": req.GetAuthor(),
+		"status": req.GetStatus(),
+		"from":   req.GetFrom(),
+		"to":     req.GetTo(),
+	} {
+		if value != "" {
+			q.Set(name, value)
+		}
+	}
+	for name, value := range map[string]int64{
+		"news_id":   req.GetNewsId(),
+		"page":      int64(req.GetPage()),
+		"page_size": int64(req.GetPageSize()),
+	} {
+		if value != 0 {
+			q.Set(name, strconv.FormatInt(value, 10))
+		}
+	}
+
+	comments, problem := s.app.searchComments(ctx, q)
+	if problem != nil {
+		return nil, grpcx.Error(problem)
+	}
+	return commentsToProto(comments), nil
+}
+
+func (s *commentServer) SetCommentStatus(ctx context.Context, req *commentpb.SetCommentStatusRequest) (*commentpb.Comment, error) {
+	comment, problem := s.app.setCommentStatus(ctx, int(req.GetId()), req.GetStatus())
+	if problem != nil {
+		return nil, grpcx.Error(problem)
+	}
+	return commentToProto(comment), nil
+}
+
+func commentToProto(c Comment) *commentpb.Comment {
+	msg := &commentpb.Comment{
+		Id:           int64(c.ID),
+		NewsId:       int64(c.NewsID),
+		Author:       c.Author,
+		Text:         c.Text,
+		Html:         c.HTML,
+		Status:       c.Status,
+		CreatedAt:    timestamppb.New(c.CreatedAt),
+		OriginalText: c.OriginalText,
+	}
+	// Прежний статус есть только в ответе на смену статуса
+	msg.PreviousStatus = c.PreviousStatus
+	if c.ParentID != nil {
+		parentID := int64(*c.ParentID)
+		msg.ParentId = &parentID
+	}
+	return msg
+}
+
+func commentsToProto(comments []Comment) *commentpb.ListCommentsResponse {
+	resp := &commentpb.ListCommentsResponse{}
+	for _, c := range comments {
+		resp.Comments = append(resp.Comments, commentToProto(c))
+	}
+	return resp
+}
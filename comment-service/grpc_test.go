@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/commentpb"
+)
+
+func newGRPCClient(t *testing.T, app *App) commentpb.CommentServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go app.grpc.Serve(lis)
+	t.Cleanup(app.grpc.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return commentpb.NewCommentServiceClient(conn)
+}
+
+func TestGRPCComments(t *testing.T) {
+	client := newGRPCClient(t, newTestApp(t))
+	ctx := context.Background()
+
+	parent, err := client.CreateComment(ctx, &commentpb.CreateCommentRequest{NewsId: 1, Author: "anna", Text: "Первый комментарий"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentID := parent.GetId()
+	reply, err := client.CreateComment(ctx, &commentpb.CreateCommentRequest{NewsId: 1, ParentId: &parentID, Text: "Ответ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.GetParentId() != parentID || reply.GetCreatedAt() == nil {
+		t.Errorf("Неверный ответ на комментарий: %v", reply)
+	}
+
+	list, err := client.ListComments(ctx, &commentpb.ListCommentsRequest{NewsId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetComments()) != 2 {
+		t.Errorf("Ожидалось 2 комментария, получено %d", len(list.GetComments()))
+	}
+
+	if _, err := client.CreateComment(ctx, &commentpb.CreateCommentRequest{NewsId: 2, Text: "К другой новости"}); err != nil {
+		t.Fatal(err)
+	}
+	list, err = client.ListComments(ctx, &commentpb.ListCommentsRequest{NewsIds: []int64{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetComments()) != 3 {
+		t.Errorf("Ожидалось 3 комментария к новостям 1 и 2, получено %d", len(list.GetComments()))
+	}
+
+	counts, err := client.CountComments(ctx, &commentpb.CountCommentsRequest{NewsIds: []int64{1, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := counts.GetCounts(); len(c) != 3 || c[1] != 2 || c[2] != 1 || c[3] != 0 {
+		t.Errorf("Неверное число комментариев: %v", c)
+	}
+
+	found, err := client.SearchComments(ctx, &commentpb.SearchCommentsRequest{Q: "первый", Author: "anna"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.GetComments()) != 1 || found.GetComments()[0].GetId() != parentID {
+		t.Errorf("Неверный результат поиска: %v", found.GetComments())
+	}
+}
+
+func TestGRPCCommentValidation(t *testing.T) {
+	client := newGRPCClient(t, newTestApp(t))
+	ctx := context.Background()
+
+	missing := int64(100)
+	_, err := client.CreateComment(ctx, &commentpb.CreateCommentRequest{NewsId: 1, ParentId: &missing, Text: "Ответ"})
+	if p, ok := grpcx.Problem(err); !ok || p.Code != httpx.CodeValidationFailed || len(p.Errors) != 1 || p.Errors[0].Field != "parent_id" {
+		t.Errorf("Ожидалась ошибка валидации parent_id, получено %v", err)
+	}
+
+	_, err = client.SearchComments(ctx, &commentpb.SearchCommentsRequest{})
+	if p, ok := grpcx.Problem(err); !ok || len(p.Errors) != 1 || p.Errors[0].Code != httpx.FieldRequired {
+		t.Errorf("Поиск без параметров должен отклоняться, получено %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"pkg/idempotency"
+)
+
+// pendingTimeout — через сколько незавершенный запрос (например, после падения процесса) освобождает ключ
+const pendingTimeout = time.Minute
+
+// sqliteIdempotencyStore — ключи идемпотентности в базе сервиса: повтор через любую реплику шлюза
+// получает тот же ответ
+type sqliteIdempotencyStore struct {
+	db  *sql.DB
+	ttl time.Duration
+	now func() time.Time
+}
+
+func newIdempotencyStore(db *sql.DB, ttl time.Duration) (*sqliteIdempotencyStore, error) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key TEXT PRIMARY KEY,
+			request_hash TEXT NOT NULL,
+			done INTEGER NOT NULL DEFAULT 0,
+			status INTEGER NOT NULL DEFAULT 0,
+			content_type TEXT NOT NULL DEFAULT '',
+			body BLOB,
+			created_at INTEGER NOT NULL
+		);
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteIdempotencyStore{db: db, ttl: ttl, now: time.Now}, nil
+}
+
+func (s *sqliteIdempotencyStore) Reserve(ctx context.Context, key, hash string) (*idempotency.Record, error) {
+	now := s.now()
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE created_at < ? OR (done = 0 AND created_at < ?)",
+		now.Add(-s.ttl).UnixNano(), now.Add(-pendingTimeout).UnixNano())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO idempotency_keys (key, request_hash, created_at) VALUES (?, ?, ?)",
+		key, hash, now.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	var rec idempotency.Record
+	err = s.db.QueryRowContext(ctx,
+		"SELECT request_hash, done, status, content_type, body FROM idempotency_keys WHERE key = ?", key,
+	).Scan(&rec.Hash, &rec.Done, &rec.Response.Status, &rec.Response.ContentType, &rec.Response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (s *sqliteIdempotencyStore) Complete(ctx context.Context, key string, resp idempotency.Response) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE idempotency_keys SET done = 1, status = ?, content_type = ?, body = ? WHERE key = ?",
+		resp.Status, resp.ContentType, resp.Body, key)
+	return err
+}
+
+func (s *sqliteIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE key = ? AND done = 0", key)
+	return err
+}
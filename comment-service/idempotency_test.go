@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"pkg/idempotency"
+	"pkg/pb/commentpb"
+)
+
+func countAllComments(t *testing.T) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM comments").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestCreateCommentIdempotencyKey(t *testing.T) {
+	app := newTestApp(t)
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body))
+		req.Header.Set(idempotency.Header, key)
+		rr := httptest.NewRecorder()
+		app.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := post("key-1", `{"news_id":1,"text":"Комментарий"}`)
+	retry := post("key-1", `{"news_id":1,"text":"Комментарий"}`)
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("Повтор должен получить исходный ответ: %d %s / %d %s", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("Ожидался заголовок %s в ответе на повтор", idempotency.ReplayedHeader)
+	}
+	if n := countAllComments(t); n != 1 {
+		t.Errorf("Повтор не должен создавать комментарий, комментариев: %d", n)
+	}
+
+	if rr := post("key-1", `{"news_id":1,"text":"Другой текст"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Ожидался статус %d для другого тела, получен %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	// Через gRPC ключ передается в метаданных
+	client := newGRPCClient(t, app)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), idempotency.Metadata, "grpc-key")
+	req := &commentpb.CreateCommentRequest{NewsId: 1, Text: "Через gRPC"}
+	a, err := client.CreateComment(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := client.CreateComment(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.GetId() != b.GetId() || countAllComments(t) != 2 {
+		t.Errorf("Повтор gRPC должен вернуть тот же комментарий: %d и %d", a.GetId(), b.GetId())
+	}
+}
+
+func TestIdempotencyStoreExpires(t *testing.T) {
+	newTestApp(t)
+	store, err := newIdempotencyStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	store.Reserve(ctx, "pending", "hash")
+	store.Reserve(ctx, "done", "hash")
+	store.Complete(ctx, "done", idempotency.Response{Status: http.StatusOK, Body: []byte("{}")})
+	if rec, _ := store.Reserve(ctx, "done", "hash"); rec == nil || !rec.Done || string(rec.Response.Body) != "{}" {
+		t.Fatalf("Ожидалась сохраненная запись, получено %+v", rec)
+	}
+
+	// Зависший незавершенный запрос освобождает ключ раньше срока хранения
+	now = now.Add(2 * pendingTimeout)
+	if rec, _ := store.Reserve(ctx, "pending", "other"); rec != nil {
+		t.Errorf("Зависший ключ должен заниматься заново, получено %+v", rec)
+	}
+	if rec, _ := store.Reserve(ctx, "done", "hash"); rec == nil {
+		t.Error("Завершенный запрос должен храниться до истечения срока")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if rec, _ := store.Reserve(ctx, "done", "other"); rec != nil {
+		t.Errorf("Истекший ключ должен заниматься заново, получено %+v", rec)
+	}
+}
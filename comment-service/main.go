@@ -4,86 +4,73 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/hlog"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+
+	"pkg/config"
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/idempotency"
+	"pkg/pb/commentpb"
+	"pkg/server"
+	"pkg/textx"
 )
 
 var db *sql.DB
 
-type Config struct {
-	Port   string
-	DBPath string
-}
-
 type App struct {
 	config Config
 	logger zerolog.Logger
 	router chi.Router
+	grpc   *grpc.Server
+	health *server.Health
+	// rescanner — повторная проверка комментариев; nil, если censor_service_url не задан
+	rescanner *Rescanner
 }
 
 type Comment struct {
-	ID        int       `json:"id"`
-	NewsID    int       `json:"news_id"`
-	ParentID  *int      `json:"parent_id,omitempty"`
-	Text      string    `json:"text"`
+	ID       int    `json:"id"`
+	NewsID   int    `json:"news_id"`
+	ParentID *int   `json:"parent_id,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Text     string `json:"text"`
+	// HTML — текст, размеченный безопасным подмножеством Markdown; не хранится, а строится при чтении
+	HTML      string    `json:"html"`
+	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
+	// OriginalText — текст до маскировки запрещенных слов; только для модераторов:
+	// списки комментариев к новостям его не возвращают
+	OriginalText string `json:"original_text,omitempty"`
+	// Language и Policy — язык и политика, по которым Censor Service проверил текст; сохраняются
+	// для повторной проверки по новой версии словаря и не возвращаются при чтении
+	Language string `json:"language,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+	// PreviousStatus — статус до изменения; только в ответе на смену статуса
+	PreviousStatus string `json:"previous_status,omitempty"`
 }
 
-type Response struct {
-	Status string      `json:"status"`
-	Data   interface{} `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func RequestIDMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-		ctx := context.WithValue(r.Context(), "request_id", requestID)
-		w.Header().Set("X-Request-ID", requestID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
-
-func generateRequestID() string {
-	return strconv.FormatInt(time.Now().UnixNano(), 10)
-}
-
-func LoggerMiddleware(logger *zerolog.Logger) func(http.Handler) http.Handler {
-	return hlog.NewHandler(*logger)
-}
+// maxScopeLength — ограничение длины кода языка и названия политики
+const maxScopeLength = 64
 
 func NewApp(config Config) *App {
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-
-	r := chi.NewRouter()
-
-	r.Use(middleware.Recoverer)
-	r.Use(RequestIDMiddleware)
-	r.Use(LoggerMiddleware(&logger))
+	logger := server.NewLogger("comment-service")
+	health := server.NewHealth()
+	r := server.NewRouter(logger, health)
 
 	app := &App{
 		config: config,
 		logger: logger,
 		router: r,
+		health: health,
 	}
 
 	var err error
@@ -97,7 +84,13 @@ func NewApp(config Config) *App {
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			news_id INTEGER NOT NULL,
 			parent_id INTEGER,
+			author TEXT NOT NULL DEFAULT '',
 			text TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'published',
+			original_text TEXT NOT NULL DEFAULT '',
+			language TEXT NOT NULL DEFAULT '',
+			policy TEXT NOT NULL DEFAULT '',
+			approved_rules TEXT NOT NULL DEFAULT '',
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
 		CREATE INDEX IF NOT EXISTS idx_news_id ON comments(news_id);
@@ -106,12 +99,37 @@ func NewApp(config Config) *App {
 		log.Fatal(err)
 	}
 
+	if err := migrate(db); err != nil {
+		log.Fatal(err)
+	}
+	health.AddCheck("database", checkDatabase)
+
+	idempotencyStore, err := newIdempotencyStore(db, config.IdempotencyTTL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app.grpc = grpcx.NewServer(logger, grpc.ChainUnaryInterceptor(
+		idempotency.UnaryServerInterceptor(idempotencyStore, commentpb.CommentService_CreateComment_FullMethodName),
+	))
+
+	if config.CensorURL != "" {
+		app.rescanner, err = newRescanner(db, config, logger)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	r.Get("/", app.Home)
-	r.Get("/health", app.HealthCheck)
-	r.Post("/comments", app.CreateComment)
+	r.With(idempotency.Middleware(idempotencyStore)).Post("/comments", app.CreateComment)
 	r.Get("/comments", app.GetCommentsByNewsID)
+	r.Get("/comments/search", app.SearchComments)
+	r.Get("/comments/counts", app.CountComments)
+	r.Put("/comments/{id}/status", app.SetCommentStatus)
+	r.Post("/comments/rescan", app.RescanComments)
 	r.Delete("/comments/{id}", app.DeleteComment)
 
+	commentpb.RegisterCommentServiceServer(app.grpc, &commentServer{app: app})
+
 	return app
 }
 
@@ -121,153 +139,303 @@ func (a *App) Home(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Comment Service OK"))
 }
 
-func (a *App) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{Status: "ok"})
-}
-
 func (a *App) CreateComment(w http.ResponseWriter, r *http.Request) {
 	var comment Comment
 	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-		a.sendError(w, http.StatusBadRequest, "Invalid request body")
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
 		return
 	}
 
-	if comment.NewsID < 1 {
-		a.sendError(w, http.StatusBadRequest, "Invalid news_id")
+	comment, problem := a.createComment(r.Context(), comment)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
 		return
 	}
 
-	if len(comment.Text) > 1000 {
-		a.sendError(w, http.StatusBadRequest, "Text too long")
-		return
+	httpx.SendResponse(w, http.StatusOK, comment)
+}
+
+// createComment — нормализует текст (без управляющих символов и некорректного UTF-8, в форме NFC,
+// без пробелов по краям), проверяет и сохраняет комментарий; общая часть HTTP и gRPC API.
+// Длина текста и имени автора считается в видимых символах, а не в байтах.
+func (a *App) createComment(ctx context.Context, comment Comment) (Comment, *httpx.Problem) {
+	comment.Text = textx.Normalize(comment.Text)
+	comment.OriginalText = textx.Normalize(comment.OriginalText)
+	comment.Author = textx.Normalize(comment.Author)
+	comment.Language = strings.TrimSpace(comment.Language)
+	comment.Policy = strings.TrimSpace(comment.Policy)
+
+	var fields []httpx.FieldError
+	if comment.NewsID < 1 {
+		fields = append(fields, httpx.FieldError{Field: "news_id", Code: httpx.FieldInvalid, Message: "Invalid news_id"})
+	}
+	switch {
+	case comment.Text == "":
+		fields = append(fields, httpx.FieldError{Field: "text", Code: httpx.FieldRequired, Message: "Text is required"})
+	case textx.Length(comment.Text) > a.config.Limits.MaxTextLength:
+		fields = append(fields, httpx.FieldError{Field: "text", Code: httpx.FieldTooLong, Message: "Text too long"})
+	}
+	if textx.Length(comment.OriginalText) > a.config.Limits.MaxTextLength {
+		fields = append(fields, httpx.FieldError{Field: "original_text", Code: httpx.FieldTooLong, Message: "Original text too long"})
+	}
+	if textx.Length(comment.Author) > a.config.Limits.MaxAuthorLength {
+		fields = append(fields, httpx.FieldError{Field: "author", Code: httpx.FieldTooLong, Message: "Author too long"})
+	}
+	if len(comment.Language) > maxScopeLength {
+		fields = append(fields, httpx.FieldError{Field: "language", Code: httpx.FieldTooLong, Message: "Language too long"})
+	}
+	if len(comment.Policy) > maxScopeLength {
+		fields = append(fields, httpx.FieldError{Field: "policy", Code: httpx.FieldTooLong, Message: "Policy too long"})
+	}
+	if comment.Status == "" {
+		comment.Status = StatusPublished
+	}
+	if !validStatus(comment.Status) {
+		fields = append(fields, httpx.FieldError{Field: "status", Code: httpx.FieldUnknown, Message: "Status must be published or pending"})
+	}
+	if len(fields) > 0 {
+		return comment, httpx.ValidationProblem(fields)
 	}
 
 	if comment.ParentID != nil {
-		var exists bool
-		err := db.QueryRow("SELECT 1 FROM comments WHERE id = ?", *comment.ParentID).Scan(&exists)
-		if err != nil || !exists {
-			a.sendError(w, http.StatusBadRequest, "Parent comment does not exist")
-			return
+		var parentNewsID int
+		err := db.QueryRowContext(ctx, "SELECT news_id FROM comments WHERE id = ?", *comment.ParentID).Scan(&parentNewsID)
+		if err != nil {
+			return comment, httpx.ValidationProblem([]httpx.FieldError{{Field: "parent_id", Code: httpx.FieldNotFound, Message: "Parent comment does not exist"}})
+		}
+		if parentNewsID != comment.NewsID {
+			return comment, httpx.ValidationProblem([]httpx.FieldError{{Field: "parent_id", Code: httpx.FieldInvalid, Message: "Parent comment belongs to another news"}})
 		}
 	}
 
-	stmt, err := db.Prepare("INSERT INTO comments (news_id, parent_id, text) VALUES (?, ?, ?)")
+	stmt, err := db.PrepareContext(ctx, "INSERT INTO comments (news_id, parent_id, author, text, status, original_text, language, policy) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Database error")
-		return
+		return comment, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Database error")
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(comment.NewsID, comment.ParentID, comment.Text)
+	result, err := stmt.ExecContext(ctx, comment.NewsID, comment.ParentID, comment.Author, comment.Text, comment.Status, comment.OriginalText, comment.Language, comment.Policy)
 	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to insert comment")
-		return
+		return comment, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Failed to insert comment")
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Failed to get comment ID")
-		return
+		return comment, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Failed to get comment ID")
 	}
 
 	comment.ID = int(id)
 	comment.CreatedAt = time.Now()
-
-	a.sendResponse(w, http.StatusOK, comment)
+	comment.HTML = textx.RenderMarkdown(comment.Text)
+	comment.Language, comment.Policy = "", ""
+	return comment, nil
 }
 
+// maxNewsIDs — сколько новостей можно запросить в одном GET /comments?news_id=1,2,3
+const maxNewsIDs = 100
+
 func (a *App) GetCommentsByNewsID(w http.ResponseWriter, r *http.Request) {
-	newsIDStr := r.URL.Query().Get("news_id")
-	newsID, err := strconv.Atoi(newsIDStr)
-	if err != nil || newsID < 1 {
-		a.sendError(w, http.StatusBadRequest, "Invalid news_id")
+	newsIDs, fieldErr := newsIDsParam(r.URL.Query().Get("news_id"))
+	if fieldErr != nil {
+		httpx.SendValidationError(w, r, *fieldErr)
 		return
 	}
 
-	rows, err := db.Query("SELECT id, news_id, parent_id, text, created_at FROM comments WHERE news_id = ?", newsID)
+	comments, err := listComments(r.Context(), newsIDs...)
 	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Database error")
+		httpx.SendError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Database error")
 		return
 	}
+
+	httpx.SendResponse(w, http.StatusOK, comments)
+}
+
+// newsIDsParam — разбирает параметр news_id со списком ID новостей через запятую
+func newsIDsParam(value string) ([]int, *httpx.FieldError) {
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, &httpx.FieldError{Field: "news_id", Code: httpx.FieldInvalid, Message: "Invalid news_id"}
+		}
+		ids = append(ids, id)
+	}
+	if fieldErr := checkNewsIDs("news_id", ids); fieldErr != nil {
+		return nil, fieldErr
+	}
+	return ids, nil
+}
+
+// checkNewsIDs — проверяет список ID новостей, запрошенных одним вызовом
+func checkNewsIDs(field string, ids []int) *httpx.FieldError {
+	for _, id := range ids {
+		if id < 1 {
+			return &httpx.FieldError{Field: field, Code: httpx.FieldInvalid, Message: "Invalid news_id"}
+		}
+	}
+	if len(ids) > maxNewsIDs {
+		return &httpx.FieldError{Field: field, Code: httpx.FieldTooLong, Message: fmt.Sprintf("At most %d news IDs per request", maxNewsIDs)}
+	}
+	return nil
+}
+
+// CountComments — число комментариев к каждой из новостей: GET /comments/counts?news_id=1,2,3
+func (a *App) CountComments(w http.ResponseWriter, r *http.Request) {
+	newsIDs, fieldErr := newsIDsParam(r.URL.Query().Get("news_id"))
+	if fieldErr != nil {
+		httpx.SendValidationError(w, r, *fieldErr)
+		return
+	}
+
+	counts, err := countComments(r.Context(), newsIDs...)
+	if err != nil {
+		httpx.SendError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Database error")
+		return
+	}
+
+	httpx.SendResponse(w, http.StatusOK, counts)
+}
+
+// countComments — число опубликованных комментариев к новостям; новости без комментариев получают 0
+func countComments(ctx context.Context, newsIDs ...int) (map[int]int, error) {
+	placeholders, args := inClause(newsIDs)
+	args = append(args, StatusPublished)
+	rows, err := db.QueryContext(ctx, "SELECT news_id, COUNT(*) FROM comments WHERE news_id IN ("+placeholders+") AND status = ? GROUP BY news_id", args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
+	counts := make(map[int]int, len(newsIDs))
+	for _, id := range newsIDs {
+		counts[id] = 0
+	}
+	for rows.Next() {
+		var newsID, count int
+		if err := rows.Scan(&newsID, &count); err != nil {
+			return nil, err
+		}
+		counts[newsID] = count
+	}
+	return counts, rows.Err()
+}
+
+// inClause — плейсхолдеры и аргументы для условия IN по списку ID
+func inClause(ids []int) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// listComments — опубликованные комментарии к одной или нескольким новостям
+func listComments(ctx context.Context, newsIDs ...int) ([]Comment, error) {
+	placeholders, args := inClause(newsIDs)
+	args = append(args, StatusPublished)
+	rows, err := db.QueryContext(ctx, "SELECT "+commentColumns+" FROM comments WHERE news_id IN ("+placeholders+") AND status = ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comments := scanComments(rows)
+	// Исходный текст замаскированных комментариев видят только модераторы
+	for i := range comments {
+		comments[i].OriginalText = ""
+	}
+	return comments, nil
+}
+
+// commentColumns — столбцы комментария в порядке scanComments
+const commentColumns = "id, news_id, parent_id, author, text, status, created_at, original_text"
+
+func scanComments(rows *sql.Rows) []Comment {
 	var comments []Comment
 	for rows.Next() {
 		var c Comment
 		var createdAtStr string
-		err := rows.Scan(&c.ID, &c.NewsID, &c.ParentID, &c.Text, &createdAtStr)
+		err := rows.Scan(&c.ID, &c.NewsID, &c.ParentID, &c.Author, &c.Text, &c.Status, &createdAtStr, &c.OriginalText)
 		if err != nil {
 			continue
 		}
-		c.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAtStr)
+		c.CreatedAt = parseCreatedAt(createdAtStr)
+		c.HTML = textx.RenderMarkdown(c.Text)
 		comments = append(comments, c)
 	}
-
-	a.sendResponse(w, http.StatusOK, comments)
+	return comments
 }
 
 func (a *App) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id < 1 {
-		a.sendError(w, http.StatusBadRequest, "Invalid comment ID")
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid comment ID"})
 		return
 	}
 
 	result, err := db.Exec("DELETE FROM comments WHERE id = ?", id)
 	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Database error")
+		httpx.SendError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Database error")
 		return
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		a.sendError(w, http.StatusInternalServerError, "Database error")
+		httpx.SendError(w, r, http.StatusInternalServerError, httpx.CodeInternal, "Database error")
 		return
 	}
 
 	if rowsAffected == 0 {
-		a.sendError(w, http.StatusNotFound, "Comment not found")
+		httpx.SendError(w, r, http.StatusNotFound, httpx.CodeNotFound, "Comment not found")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(Response{Status: "success", Data: "Comment deleted"})
-}
-
-func (a *App) sendResponse(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(Response{
-		Status: "success",
-		Data:   data,
-	})
+	httpx.SendResponse(w, http.StatusOK, "Comment deleted")
 }
 
-func (a *App) sendError(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(Response{
-		Status: "error",
-		Error:  message,
-	})
+// checkDatabase — проверка готовности: база доступна и таблица комментариев читается
+func checkDatabase(ctx context.Context) error {
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+	var id int
+	err := db.QueryRowContext(ctx, `SELECT id FROM comments LIMIT 1`).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	return nil
 }
 
 func (a *App) Run() error {
-	return http.ListenAndServe(":"+a.config.Port, a.router)
+	onShutdown := []func() error{db.Close}
+	if a.rescanner != nil && a.config.Rescan.Interval > 0 {
+		a.rescanner.Start(a.config.Rescan.Interval)
+		onShutdown = append([]func() error{a.rescanner.Stop}, onShutdown...)
+	}
+	srv := &server.Server{
+		Addr:            ":" + a.config.Port,
+		Handler:         a.router,
+		Logger:          a.logger,
+		Health:          a.health,
+		GRPCAddr:        ":" + a.config.GRPCPort,
+		GRPC:            a.grpc,
+		DrainDelay:      a.config.Shutdown.DrainDelay,
+		ShutdownTimeout: a.config.Shutdown.Timeout,
+		OnShutdown:      onShutdown,
+	}
+	return srv.Run()
 }
 
 func main() {
-	config := Config{
-		Port:   getEnv("PORT", "8081"),
-		DBPath: getEnv("DB_PATH", "./comments.db"),
-	}
+	cfg := DefaultConfig()
+	config.MustLoad("comment-service", &cfg)
 
-	app := NewApp(config)
+	app := NewApp(cfg)
 
-	log.Printf("Comment Service запущен на порту %s", config.Port)
+	log.Printf("Comment Service запущен на порту %s", cfg.Port)
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
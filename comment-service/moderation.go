@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"pkg/httpx"
+)
+
+// Статусы комментария: pending — в очереди модерации, не показывается в списках и не учитывается в счетчиках
+const (
+	StatusPublished = "published"
+	StatusPending   = "pending"
+)
+
+var errCommentNotFound = httpx.NewProblem(http.StatusNotFound, httpx.CodeNotFound, "Comment not found")
+
+func validStatus(status string) bool {
+	return status == StatusPublished || status == StatusPending
+}
+
+// SetCommentStatus — публикация комментария из очереди модерации: PUT /comments/{id}/status {"status":"published"}
+func (a *App) SetCommentStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid comment ID"})
+		return
+	}
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	comment, problem := a.setCommentStatus(r.Context(), id, req.Status)
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, comment)
+}
+
+// setCommentStatus — меняет статус комментария и возвращает комментарий с прежним статусом в PreviousStatus;
+// общая часть HTTP и gRPC API. Статус меняется одним условным UPDATE, поэтому из двух одновременных
+// одобрений только одно видит прежний статус pending.
+// Публикация из очереди запоминает сработавшие на комментарий правила: повторная проверка не вернет его
+// на модерацию, пока этот набор правил не изменится. Возврат в очередь одобрение отменяет.
+func (a *App) setCommentStatus(ctx context.Context, id int, status string) (Comment, *httpx.Problem) {
+	if !validStatus(status) {
+		return Comment{}, httpx.ValidationProblem([]httpx.FieldError{{Field: "status", Code: httpx.FieldUnknown, Message: "Status must be published or pending"}})
+	}
+
+	approved := ""
+	if status == StatusPublished && a.rescanner != nil {
+		var current string
+		err := db.QueryRowContext(ctx, "SELECT status FROM comments WHERE id = ?", id).Scan(&current)
+		if err == sql.ErrNoRows {
+			return Comment{}, errCommentNotFound
+		}
+		if err != nil {
+			return Comment{}, errDatabase
+		}
+		if current == StatusPending {
+			if approved, err = a.rescanner.approvedRules(ctx, id); err != nil {
+				return Comment{}, errDatabase
+			}
+		}
+	}
+
+	var res sql.Result
+	var err error
+	switch {
+	case approved != "":
+		res, err = db.ExecContext(ctx, "UPDATE comments SET status = ?, approved_rules = ? WHERE id = ? AND status != ?", status, approved, id, status)
+	case status == StatusPending:
+		res, err = db.ExecContext(ctx, "UPDATE comments SET status = ?, approved_rules = '' WHERE id = ? AND status != ?", status, id, status)
+	default:
+		res, err = db.ExecContext(ctx, "UPDATE comments SET status = ? WHERE id = ? AND status != ?", status, id, status)
+	}
+	if err != nil {
+		return Comment{}, errDatabase
+	}
+	changed, err := res.RowsAffected()
+	if err != nil {
+		return Comment{}, errDatabase
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT "+commentColumns+" FROM comments WHERE id = ?", id)
+	if err != nil {
+		return Comment{}, errDatabase
+	}
+	defer rows.Close()
+	comments := scanComments(rows)
+	if len(comments) == 0 {
+		return Comment{}, errCommentNotFound
+	}
+	comment := comments[0]
+	// Статусов два: если UPDATE изменил строку, комментарий был в другом статусе
+	switch {
+	case changed == 0:
+		comment.PreviousStatus = status
+	case status == StatusPublished:
+		comment.PreviousStatus = StatusPending
+	default:
+		comment.PreviousStatus = StatusPublished
+	}
+	return comment, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"pkg/pb/commentpb"
+)
+
+func setStatus(t *testing.T, app *App, id int, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, fmt.Sprintf("/comments/%d/status", id), strings.NewReader(body)))
+	return rr
+}
+
+func TestPendingCommentsAreHidden(t *testing.T) {
+	app := newTestApp(t)
+	published := createTestComment(t, app, `{"news_id":1,"text":"обычный"}`)
+	pending := createTestComment(t, app, `{"news_id":1,"text":"подозрительный","status":"pending"}`)
+	if published.Status != StatusPublished || pending.Status != StatusPending {
+		t.Fatalf("Неверные статусы: %q и %q", published.Status, pending.Status)
+	}
+
+	comments, _ := listComments(context.Background(), 1)
+	counts, _ := countComments(context.Background(), 1)
+	if len(comments) != 1 || comments[0].ID != published.ID || counts[1] != 1 {
+		t.Errorf("Комментарий на модерации не должен попадать в списки и счетчики: %v %v", comments, counts)
+	}
+	if _, found := searchComments(t, app, url.Values{"status": {StatusPending}}); len(found) != 1 || found[0].ID != pending.ID {
+		t.Errorf("Модератор должен находить комментарии на модерации: %v", found)
+	}
+
+	rr := setStatus(t, app, pending.ID, `{"status":"published"}`)
+	var resp struct {
+		Data Comment `json:"data"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp.Data.Status != StatusPublished || resp.Data.PreviousStatus != StatusPending {
+		t.Fatalf("Ожидалась публикация комментария из очереди: %d %s", rr.Code, rr.Body.String())
+	}
+	if counts, _ := countComments(context.Background(), 1); counts[1] != 2 {
+		t.Errorf("Опубликованный комментарий должен учитываться, получено %d", counts[1])
+	}
+
+	// Повторное одобрение ничего не меняет: по прежнему статусу видно, что комментарий уже был опубликован
+	rr = setStatus(t, app, pending.ID, `{"status":"published"}`)
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || resp.Data.PreviousStatus != StatusPublished {
+		t.Errorf("Ожидался прежний статус published: %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestSetCommentStatusErrors(t *testing.T) {
+	app := newTestApp(t)
+	c := createTestComment(t, app, `{"news_id":1,"text":"текст"}`)
+
+	if rr := setStatus(t, app, c.ID, `{"status":"deleted"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Неизвестный статус должен отклоняться, получен %d", rr.Code)
+	}
+	if rr := setStatus(t, app, 999, `{"status":"published"}`); rr.Code != http.StatusNotFound {
+		t.Errorf("Ожидался статус %d, получен %d", http.StatusNotFound, rr.Code)
+	}
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(`{"news_id":1,"text":"текст","status":"hidden"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Неизвестный статус при создании должен отклоняться, получен %d", rr.Code)
+	}
+}
+
+func TestGRPCSetCommentStatus(t *testing.T) {
+	app := newTestApp(t)
+	client := newGRPCClient(t, app)
+	ctx := context.Background()
+
+	created, err := client.CreateComment(ctx, &commentpb.CreateCommentRequest{NewsId: 1, Text: "на модерацию", Status: StatusPending})
+	if err != nil || created.GetStatus() != StatusPending {
+		t.Fatalf("Ожидался комментарий на модерации: %v %v", created, err)
+	}
+	updated, err := client.SetCommentStatus(ctx, &commentpb.SetCommentStatusRequest{Id: created.GetId(), Status: StatusPublished})
+	if err != nil || updated.GetStatus() != StatusPublished || updated.GetPreviousStatus() != StatusPending {
+		t.Errorf("Ожидалась публикация комментария из очереди: %v %v", updated, err)
+	}
+}
+
+func TestOriginalTextOnlyForModerators(t *testing.T) {
+	app := newTestApp(t)
+	created := createTestComment(t, app, `{"news_id":1,"text":"Сам •••••","original_text":"Сам дурак"}`)
+	if created.OriginalText != "Сам дурак" {
+		t.Fatalf("Исходный текст должен сохраняться: %+v", created)
+	}
+
+	comments, _ := listComments(context.Background(), 1)
+	if len(comments) != 1 || comments[0].Text != "Сам •••••" || comments[0].OriginalText != "" {
+		t.Errorf("В списке к новости должен быть только замаскированный текст: %+v", comments)
+	}
+	if _, found := searchComments(t, app, url.Values{"news_id": {"1"}}); len(found) != 1 || found[0].OriginalText != "Сам дурак" {
+		t.Errorf("Модератор должен видеть исходный текст: %+v", found)
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"pkg/httpx"
+)
+
+// Решения пакетной проверки Censor Service, по которым комментарий помечается
+const (
+	verdictQueue  = "queue"
+	verdictReject = "reject"
+)
+
+// censorVersionHeader — версия словаря, по которой Censor Service проверил пакет
+const censorVersionHeader = "X-Dictionary-Version"
+
+// RescanReport — итог повторной проверки комментариев
+type RescanReport struct {
+	Version  int64 `json:"dictionary_version"`
+	Scanned  int   `json:"scanned"`
+	Flagged  []int `json:"flagged"`
+	UpToDate bool  `json:"up_to_date,omitempty"`
+}
+
+// errRescanInProgress — проверка уже идет: по расписанию или запущенная вручную
+var errRescanInProgress = errors.New("rescan is already in progress")
+
+// censorBatchResult — решение Censor Service по комментарию; сработавшие правила сохраняются в пометке как есть
+type censorBatchResult struct {
+	ID      int               `json:"id"`
+	Verdict string            `json:"verdict"`
+	Matches []json.RawMessage `json:"matches"`
+	Error   string            `json:"error"`
+}
+
+// Rescanner — повторная проверка опубликованных комментариев после изменения словаря Censor Service.
+// Комментарии, которые нарушают новую версию словаря, переводятся в очередь модерации (pending),
+// а причина записывается в comment_flags. Проверка идет пакетами по возрастанию id, и после каждого
+// пакета прогресс сохраняется в rescan_state, поэтому после перезапуска она продолжается с места остановки.
+type Rescanner struct {
+	db        *sql.DB
+	client    *http.Client
+	censorURL string
+	batchSize int
+	logger    zerolog.Logger
+
+	mu     sync.Mutex // одна проверка за раз; пока она идет, новые сразу получают errRescanInProgress
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newRescanner(db *sql.DB, cfg Config, logger zerolog.Logger) (*Rescanner, error) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS rescan_state (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			version INTEGER NOT NULL,
+			last_id INTEGER NOT NULL,
+			done INTEGER NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS comment_flags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			comment_id INTEGER NOT NULL,
+			dictionary_version INTEGER NOT NULL,
+			verdict TEXT NOT NULL,
+			matches TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_comment_flags_comment_id ON comment_flags(comment_id);
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return &Rescanner{
+		db:        db,
+		client:    &http.Client{Timeout: cfg.Rescan.Timeout},
+		censorURL: strings.TrimRight(cfg.CensorURL, "/"),
+		batchSize: cfg.Rescan.BatchSize,
+		logger:    logger,
+	}, nil
+}
+
+// Start — проверка по расписанию раз в interval, пока не вызван Stop
+func (s *Rescanner) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			report, err := s.Rescan(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, errRescanInProgress):
+				// Проверка, запущенная вручную, уже делает ту же работу
+			case err != nil:
+				s.logger.Warn().Err(err).Msg("comment rescan failed")
+			case len(report.Flagged) > 0:
+				s.logger.Info().Int64("dictionary_version", report.Version).Int("flagged", len(report.Flagged)).Msg("comments flagged by rescan")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop — останавливает проверку по расписанию и дожидается ее завершения
+func (s *Rescanner) Stop() error {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	return nil
+}
+
+// Rescan — проверяет опубликованные комментарии, если словарь изменился с прошлой проверки.
+// Если словарь меняется во время проверки, она начинается заново с новой версией.
+// Одновременно идет только одна проверка: пока она не завершилась, Rescan возвращает errRescanInProgress.
+func (s *Rescanner) Rescan(ctx context.Context) (RescanReport, error) {
+	if !s.mu.TryLock() {
+		return RescanReport{}, errRescanInProgress
+	}
+	defer s.mu.Unlock()
+
+	version, err := s.censorVersion(ctx)
+	if err != nil {
+		return RescanReport{}, err
+	}
+	report := RescanReport{Version: version, Flagged: []int{}}
+
+	var stateVersion int64
+	var lastID int
+	var done bool
+	err = s.db.QueryRowContext(ctx, `SELECT version, last_id, done FROM rescan_state WHERE id = 1`).Scan(&stateVersion, &lastID, &done)
+	if err != nil && err != sql.ErrNoRows {
+		return report, err
+	}
+	if stateVersion == version && done {
+		report.UpToDate = true
+		return report, nil
+	}
+	if stateVersion != version {
+		lastID = 0
+	}
+
+	for {
+		items, err := s.publishedAfter(ctx, lastID)
+		if err != nil {
+			return report, err
+		}
+		if len(items) == 0 {
+			break
+		}
+		results, batchVersion, err := s.checkBatch(ctx, items)
+		if err != nil {
+			return report, err
+		}
+		if batchVersion != version {
+			// Словарь изменился: комментарии до lastID проверены по старой версии
+			version, lastID = batchVersion, 0
+			report.Version = version
+			continue
+		}
+		for _, res := range results {
+			if res.Verdict != verdictQueue && res.Verdict != verdictReject {
+				continue
+			}
+			flagged, err := s.flag(ctx, res, version)
+			if err != nil {
+				return report, err
+			}
+			if flagged {
+				report.Flagged = append(report.Flagged, res.ID)
+			}
+		}
+		report.Scanned += len(items)
+		lastID = items[len(items)-1].ID
+		if err := s.saveState(ctx, version, lastID, false); err != nil {
+			return report, err
+		}
+	}
+	return report, s.saveState(ctx, version, lastID, true)
+}
+
+// rescanItem — комментарий в пакетной проверке: текст до маскировки и язык с политикой,
+// по которым он проверялся при создании
+type rescanItem struct {
+	ID       int    `json:"id"`
+	Text     string `json:"text"`
+	Language string `json:"language,omitempty"`
+	Policy   string `json:"policy,omitempty"`
+}
+
+// publishedAfter — следующий пакет опубликованных комментариев. У замаскированного комментария проверяется
+// исходный текст: в опубликованном запрещенные слова уже скрыты и не совпадут с новыми правилами.
+func (s *Rescanner) publishedAfter(ctx context.Context, lastID int) ([]rescanItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, text, original_text, language, policy FROM comments WHERE status = ? AND id > ? ORDER BY id LIMIT ?`, StatusPublished, lastID, s.batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []rescanItem
+	for rows.Next() {
+		var item rescanItem
+		var original string
+		if err := rows.Scan(&item.ID, &item.Text, &original, &item.Language, &item.Policy); err != nil {
+			return nil, err
+		}
+		if original != "" {
+			item.Text = original
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// flag — переводит комментарий в очередь модерации и записывает причину.
+// Комментарий, который уже снят с публикации или удален, не помечается.
+func (s *Rescanner) flag(ctx context.Context, res censorBatchResult, version int64) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	// Комментарий, одобренный модератором, остается опубликованным, пока на него срабатывают те же правила
+	var approved string
+	err = tx.QueryRowContext(ctx, `SELECT approved_rules FROM comments WHERE id = ?`, res.ID).Scan(&approved)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if approved != "" && approved == ruleSet(res.Matches) {
+		return false, nil
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE comments SET status = ?, approved_rules = '' WHERE id = ? AND status = ?`, StatusPending, res.ID, StatusPublished)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	matches, err := json.Marshal(res.Matches)
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO comment_flags (comment_id, dictionary_version, verdict, matches, created_at) VALUES (?, ?, ?, ?, ?)`,
+		res.ID, version, res.Verdict, string(matches), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// approvedRules — набор правил, которые срабатывают на комментарий сейчас; его запоминает одобрение модератора.
+// Если Censor Service недоступен, берутся правила последней пометки повторной проверки.
+func (s *Rescanner) approvedRules(ctx context.Context, id int) (string, error) {
+	var item rescanItem
+	var original string
+	err := s.db.QueryRowContext(ctx, `SELECT id, text, original_text, language, policy FROM comments WHERE id = ?`, id).
+		Scan(&item.ID, &item.Text, &original, &item.Language, &item.Policy)
+	if err != nil {
+		return "", err
+	}
+	if original != "" {
+		item.Text = original
+	}
+	results, _, err := s.checkBatch(ctx, []rescanItem{item})
+	if err == nil && len(results) == 1 {
+		return ruleSet(results[0].Matches), nil
+	}
+	if err == nil {
+		err = fmt.Errorf("censor service: expected 1 batch result, got %d", len(results))
+	}
+	s.logger.Warn().Err(err).Int("comment_id", id).Msg("censor check on approval failed, using the last rescan flag")
+
+	var matches string
+	err = s.db.QueryRowContext(ctx, `SELECT matches FROM comment_flags WHERE comment_id = ? ORDER BY id DESC LIMIT 1`, id).Scan(&matches)
+	if err == sql.ErrNoRows {
+		return ruleSet(nil), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(matches), &raw); err != nil {
+		return "", err
+	}
+	return ruleSet(raw), nil
+}
+
+// ruleSet — идентификаторы сработавших правил без повторов в порядке возрастания, JSON-массивом: [1,5]
+func ruleSet(matches []json.RawMessage) string {
+	ids := []int{}
+	for _, raw := range matches {
+		var match struct {
+			RuleID int `json:"rule_id"`
+		}
+		if json.Unmarshal(raw, &match) == nil && !slices.Contains(ids, match.RuleID) {
+			ids = append(ids, match.RuleID)
+		}
+	}
+	slices.Sort(ids)
+	out, _ := json.Marshal(ids)
+	return string(out)
+}
+
+func (s *Rescanner) saveState(ctx context.Context, version int64, lastID int, done bool) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO rescan_state (id, version, last_id, done) VALUES (1, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET version = excluded.version, last_id = excluded.last_id, done = excluded.done`,
+		version, lastID, done)
+	return err
+}
+
+// censorVersion — текущая версия словаря Censor Service
+func (s *Rescanner) censorVersion(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.censorURL+"/rules/version", nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("censor service: GET /rules/version returned %d", resp.StatusCode)
+	}
+	var body struct {
+		Data struct {
+			Version int64 `json:"version"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+	return body.Data.Version, nil
+}
+
+// checkBatch — пакетная проверка текстов; возвращает результаты и версию словаря, по которой они получены
+func (s *Rescanner) checkBatch(ctx context.Context, items []rescanItem) ([]censorBatchResult, int64, error) {
+	payload, err := json.Marshal(items)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.censorURL+"/check/batch", bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("censor service: POST /check/batch returned %d", resp.StatusCode)
+	}
+	version, err := strconv.ParseInt(resp.Header.Get(censorVersionHeader), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("censor service: invalid %s header: %w", censorVersionHeader, err)
+	}
+	var body struct {
+		Data []censorBatchResult `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, 0, err
+	}
+	return body.Data, version, nil
+}
+
+// RescanComments — запуск повторной проверки вручную: POST /comments/rescan; если проверка уже идет — 409
+func (a *App) RescanComments(w http.ResponseWriter, r *http.Request) {
+	if a.rescanner == nil {
+		httpx.SendError(w, r, http.StatusServiceUnavailable, httpx.CodeUpstreamUnavailable, "Censor service is not configured")
+		return
+	}
+	report, err := a.rescanner.Rescan(r.Context())
+	if errors.Is(err, errRescanInProgress) {
+		httpx.SendError(w, r, http.StatusConflict, httpx.CodeRequestInProgress, "Rescan is already in progress")
+		return
+	}
+	if err != nil {
+		a.logger.Warn().Err(err).Msg("comment rescan failed")
+		httpx.SendError(w, r, http.StatusBadGateway, httpx.CodeUpstreamError, "Rescan failed: "+err.Error())
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, report)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"pkg/httpx"
+)
+
+// fakeCensor — Censor Service со словарем из одного запрещенного слова и управляемой версией
+type fakeCensor struct {
+	version atomic.Int64
+	word    atomic.Value
+	rule    atomic.Int64 // идентификатор правила, которое срабатывает на запрещенное слово
+	checked atomic.Int64
+
+	mu    sync.Mutex
+	items []rescanItem // все полученные тексты
+}
+
+func (f *fakeCensor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	version := f.version.Load()
+	switch r.URL.Path {
+	case "/rules/version":
+		json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": map[string]int64{"version": version}})
+	case "/check/batch":
+		var items []rescanItem
+		json.NewDecoder(r.Body).Decode(&items)
+		f.mu.Lock()
+		f.items = append(f.items, items...)
+		f.mu.Unlock()
+		results := make([]map[string]any, len(items))
+		for i, item := range items {
+			f.checked.Add(1)
+			results[i] = map[string]any{"id": item.ID, "verdict": "accept"}
+			if strings.Contains(item.Text, f.word.Load().(string)) {
+				results[i] = map[string]any{"id": item.ID, "verdict": "reject", "matches": []map[string]any{{"rule_id": f.rule.Load()}}}
+			}
+		}
+		w.Header().Set(censorVersionHeader, strconv.FormatInt(version, 10))
+		json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": results})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newRescanTestApp(t *testing.T) (*App, *fakeCensor) {
+	t.Helper()
+	censor := &fakeCensor{}
+	censor.version.Store(1)
+	censor.word.Store("спам")
+	censor.rule.Store(1)
+	srv := httptest.NewServer(censor)
+	t.Cleanup(srv.Close)
+
+	cfg := testConfig(filepath.Join(t.TempDir(), "comments.db"))
+	cfg.CensorURL = srv.URL
+	cfg.Rescan.BatchSize = 2
+	app := NewApp(cfg)
+	t.Cleanup(func() { db.Close() })
+	return app, censor
+}
+
+func rescan(t *testing.T, app *App) RescanReport {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments/rescan", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data RescanReport `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Data
+}
+
+func TestRescanFlagsNewlyViolatingComments(t *testing.T) {
+	app, censor := newRescanTestApp(t)
+	createTestComment(t, app, `{"news_id":1,"text":"хорошая новость"}`)
+	bad := createTestComment(t, app, `{"news_id":1,"text":"купите казино"}`)
+	createTestComment(t, app, `{"news_id":1,"text":"казино, но на модерации","status":"pending"}`)
+	createTestComment(t, app, `{"news_id":2,"text":"интересно"}`)
+
+	if report := rescan(t, app); report.Scanned != 3 || len(report.Flagged) != 0 {
+		t.Fatalf("Первая проверка: ожидалось 3 проверенных без пометок, получено %+v", report)
+	}
+	if report := rescan(t, app); !report.UpToDate || censor.checked.Load() != 3 {
+		t.Errorf("Без изменения словаря повторная проверка не нужна: %+v, проверено %d", report, censor.checked.Load())
+	}
+
+	// Новая версия словаря запрещает слово «казино»
+	censor.word.Store("казино")
+	censor.version.Store(2)
+	report := rescan(t, app)
+	if report.Version != 2 || report.Scanned != 3 || len(report.Flagged) != 1 || report.Flagged[0] != bad.ID {
+		t.Fatalf("Ожидалась пометка комментария %d, получено %+v", bad.ID, report)
+	}
+
+	var status, verdict string
+	db.QueryRow(`SELECT status FROM comments WHERE id = ?`, bad.ID).Scan(&status)
+	db.QueryRow(`SELECT verdict FROM comment_flags WHERE comment_id = ? AND dictionary_version = 2`, bad.ID).Scan(&verdict)
+	if status != StatusPending || verdict != "reject" {
+		t.Errorf("Нарушающий комментарий должен уйти на модерацию с записью причины: %q %q", status, verdict)
+	}
+	if comments, _ := listComments(context.Background(), 1); len(comments) != 1 {
+		t.Errorf("Помеченный комментарий не должен показываться в списке: %+v", comments)
+	}
+}
+
+func TestRescanKeepsApprovedComments(t *testing.T) {
+	app, censor := newRescanTestApp(t)
+	rescan(t, app)
+	comment := createTestComment(t, app, `{"news_id":1,"text":"немного спама","status":"pending"}`)
+	if rr := setStatus(t, app, comment.ID, `{"status":"published"}`); rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// Словарь изменился, но на одобренный комментарий срабатывает то же правило
+	censor.version.Store(2)
+	if report := rescan(t, app); len(report.Flagged) != 0 {
+		t.Fatalf("Одобренный модератором комментарий не должен возвращаться на модерацию: %+v", report)
+	}
+
+	// Сработало другое правило — модератор его еще не видел
+	censor.rule.Store(2)
+	censor.version.Store(3)
+	if report := rescan(t, app); len(report.Flagged) != 1 || report.Flagged[0] != comment.ID {
+		t.Fatalf("Комментарий с новым нарушением должен вернуться на модерацию: %+v", report)
+	}
+}
+
+func TestRescanResumesAfterFailure(t *testing.T) {
+	app, censor := newRescanTestApp(t)
+	for i := 0; i < 5; i++ {
+		createTestComment(t, app, `{"news_id":1,"text":"комментарий `+strconv.Itoa(i)+`"}`)
+	}
+	// Прогресс первой версии: проверены первые два комментария (один пакет)
+	if err := app.rescanner.saveState(context.Background(), 1, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if report := rescan(t, app); report.Scanned != 3 {
+		t.Errorf("Проверка должна продолжиться с места остановки, проверено %d", report.Scanned)
+	}
+	if censor.checked.Load() != 3 {
+		t.Errorf("Ожидалось 3 проверенных текста, получено %d", censor.checked.Load())
+	}
+}
+
+func TestRescanNotConfigured(t *testing.T) {
+	app := newTestApp(t)
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments/rescan", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Без censor_service_url ожидался статус %d, получен %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestRescanChecksOriginalTextWithStoredScope(t *testing.T) {
+	app, censor := newRescanTestApp(t)
+	masked := createTestComment(t, app, `{"news_id":1,"text":"купите ••••••","original_text":"купите казино","language":"ru","policy":"kids"}`)
+
+	// Новая версия словаря запрещает слово, скрытое в опубликованном тексте
+	censor.word.Store("казино")
+	censor.version.Store(2)
+	report := rescan(t, app)
+	if len(report.Flagged) != 1 || report.Flagged[0] != masked.ID {
+		t.Fatalf("Замаскированный комментарий должен проверяться по исходному тексту: %+v", report)
+	}
+	want := rescanItem{ID: masked.ID, Text: "купите казино", Language: "ru", Policy: "kids"}
+	if len(censor.items) != 1 || censor.items[0] != want {
+		t.Errorf("Ожидалась проверка %+v, получено %+v", want, censor.items)
+	}
+}
+
+func TestRescanAlreadyInProgress(t *testing.T) {
+	app, _ := newRescanTestApp(t)
+	app.rescanner.mu.Lock()
+	defer app.rescanner.mu.Unlock()
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments/rescan", nil))
+	if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), httpx.CodeRequestInProgress) {
+		t.Errorf("Пока идет проверка, ожидался статус %d, получен %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+}
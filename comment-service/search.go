@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pkg/httpx"
+	"pkg/textx"
+)
+
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// parseCreatedAt разбирает created_at: драйвер отдает DATETIME как time.Time,
+// который database/sql сканирует в строку в формате RFC3339.
+func parseCreatedAt(s string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t
+	}
+	t, _ := time.Parse(sqliteTimeLayout, s)
+	return t
+}
+
+// migrate доводит схему существующей базы до текущей версии.
+func migrate(db *sql.DB) error {
+	hasAuthor, err := hasColumn(db, "comments", "author")
+	if err != nil {
+		return err
+	}
+	if !hasAuthor {
+		if _, err := db.Exec(`ALTER TABLE comments ADD COLUMN author TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+
+	hasStatus, err := hasColumn(db, "comments", "status")
+	if err != nil {
+		return err
+	}
+	if !hasStatus {
+		if _, err := db.Exec(`ALTER TABLE comments ADD COLUMN status TEXT NOT NULL DEFAULT 'published'`); err != nil {
+			return err
+		}
+	}
+
+	hasOriginal, err := hasColumn(db, "comments", "original_text")
+	if err != nil {
+		return err
+	}
+	if !hasOriginal {
+		if _, err := db.Exec(`ALTER TABLE comments ADD COLUMN original_text TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+
+	for _, column := range []string{"language", "policy", "approved_rules"} {
+		has, err := hasColumn(db, "comments", column)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if _, err := db.Exec(`ALTER TABLE comments ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+				return err
+			}
+		}
+	}
+
+	// FTS5 не входит в стандартную сборку go-sqlite3 и включается build-тегом sqlite_fts5
+	var fts5 bool
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err != nil {
+		return err
+	}
+	if !fts5 {
+		return errors.New("comment search requires SQLite FTS5: build with -tags sqlite_fts5")
+	}
+
+	var ftsSQL string
+	err = db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'comments_fts'`).Scan(&ftsSQL)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	ftsExists := err == nil
+	// Индекс FTS4 прежних версий заменяется индексом FTS5 по тексту и исходному тексту
+	if ftsExists && !strings.Contains(strings.ToLower(ftsSQL), "fts5") {
+		_, err := db.Exec(`
+			DROP TRIGGER IF EXISTS comments_fts_ai;
+			DROP TRIGGER IF EXISTS comments_fts_bd;
+			DROP TRIGGER IF EXISTS comments_fts_bu;
+			DROP TRIGGER IF EXISTS comments_fts_au;
+			DROP TABLE comments_fts;
+		`)
+		if err != nil {
+			return err
+		}
+		ftsExists = false
+	}
+
+	// original_text индексируется для поиска модераторов по тексту до маскирования
+	_, err = db.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS comments_fts USING fts5(text, original_text, content='comments', content_rowid='id', tokenize='unicode61');
+		CREATE TRIGGER IF NOT EXISTS comments_fts_ai AFTER INSERT ON comments BEGIN
+			INSERT INTO comments_fts(rowid, text, original_text) VALUES (new.id, new.text, new.original_text);
+		END;
+		CREATE TRIGGER IF NOT EXISTS comments_fts_ad AFTER DELETE ON comments BEGIN
+			INSERT INTO comments_fts(comments_fts, rowid, text, original_text) VALUES ('delete', old.id, old.text, old.original_text);
+		END;
+		CREATE TRIGGER IF NOT EXISTS comments_fts_au AFTER UPDATE ON comments BEGIN
+			INSERT INTO comments_fts(comments_fts, rowid, text, original_text) VALUES ('delete', old.id, old.text, old.original_text);
+			INSERT INTO comments_fts(rowid, text, original_text) VALUES (new.id, new.text, new.original_text);
+		END;
+		CREATE INDEX IF NOT EXISTS idx_author ON comments(author);
+		CREATE INDEX IF NOT EXISTS idx_created_at ON comments(created_at);
+		CREATE INDEX IF NOT EXISTS idx_status ON comments(status);
+	`)
+	if err != nil {
+		return err
+	}
+
+	if !ftsExists {
+		_, err = db.Exec(`INSERT INTO comments_fts(comments_fts) VALUES ('rebuild')`)
+	}
+	return err
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// ftsQuery превращает пользовательский запрос в безопасное выражение MATCH FTS5:
+// каждое слово берется в кавычки, завершающая * сохраняется как префиксный поиск.
+func ftsQuery(q string) string {
+	var terms []string
+	for _, word := range strings.Fields(q) {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.Trim(word, `"*`)
+		word = strings.ReplaceAll(word, `"`, "")
+		if word == "" {
+			continue
+		}
+		if prefix {
+			terms = append(terms, `"`+word+`"*`)
+		} else {
+			terms = append(terms, `"`+word+`"`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+func (a *App) SearchComments(w http.ResponseWriter, r *http.Request) {
+	comments, problem := a.searchComments(r.Context(), r.URL.Query())
+	if problem != nil {
+		httpx.SendProblem(w, r, problem)
+		return
+	}
+
+	httpx.SendResponse(w, http.StatusOK, comments)
+}
+
+// searchComments — разбирает параметры поиска и выполняет его; общая часть HTTP и gRPC API
+func (a *App) searchComments(ctx context.Context, query url.Values) ([]Comment, *httpx.Problem) {
+	var where []string
+	var args []interface{}
+
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		if textx.Length(q) > a.config.Limits.MaxSearchLength {
+			return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "q", Code: httpx.FieldTooLong, Message: "Search query too long"}})
+		}
+		match := ftsQuery(q)
+		if match == "" {
+			return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "q", Code: httpx.FieldInvalid, Message: "Invalid search query"}})
+		}
+		where = append(where, "id IN (SELECT rowid FROM comments_fts WHERE comments_fts MATCH ?)")
+		args = append(args, match)
+	}
+
+	if v := query.Get("news_id"); v != "" {
+		newsID, err := strconv.Atoi(v)
+		if err != nil || newsID < 1 {
+			return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "news_id", Code: httpx.FieldInvalid, Message: "Invalid news_id"}})
+		}
+		where = append(where, "news_id = ?")
+		args = append(args, newsID)
+	}
+
+	if v := query.Get("author"); v != "" {
+		where = append(where, "author = ?")
+		args = append(args, v)
+	}
+
+	if v := query.Get("status"); v != "" {
+		if !validStatus(v) {
+			return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "status", Code: httpx.FieldUnknown, Message: "Status must be published or pending"}})
+		}
+		where = append(where, "status = ?")
+		args = append(args, v)
+	}
+
+	var from, to time.Time
+	if v := query.Get("from"); v != "" {
+		t, err := httpx.ParseDate(v, false)
+		if err != nil {
+			return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "from", Code: httpx.FieldInvalid, Message: "Expected RFC3339 or YYYY-MM-DD"}})
+		}
+		from = t
+		where = append(where, "created_at >= ?")
+		args = append(args, t.Format(sqliteTimeLayout))
+	}
+	if v := query.Get("to"); v != "" {
+		t, err := httpx.ParseDate(v, true)
+		if err != nil {
+			return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "to", Code: httpx.FieldInvalid, Message: "Expected RFC3339 or YYYY-MM-DD"}})
+		}
+		to = t
+		where = append(where, "created_at <= ?")
+		args = append(args, t.Format(sqliteTimeLayout))
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "from", Code: httpx.FieldInvalid, Message: "from must not be after to"}})
+	}
+
+	if len(where) == 0 {
+		return nil, httpx.ValidationProblem([]httpx.FieldError{{Field: "q", Code: httpx.FieldRequired, Message: "At least one of q, news_id, author, status, from, to is required"}})
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize < 1 || pageSize > a.config.Limits.MaxPageSize {
+		pageSize = a.config.Limits.DefaultPageSize
+	}
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := db.QueryContext(ctx, `SELECT `+commentColumns+` FROM comments
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Database error")
+	}
+	defer rows.Close()
+	return scanComments(rows), nil
+}
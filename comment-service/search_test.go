@@ -0,0 +1,215 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	app := NewApp(testConfig(filepath.Join(t.TempDir(), "comments.db")))
+	t.Cleanup(func() { db.Close() })
+	return app
+}
+
+func testConfig(dbPath string) Config {
+	cfg := DefaultConfig()
+	cfg.DBPath = dbPath
+	return cfg
+}
+
+func createTestComment(t *testing.T, app *App, body string) Comment {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data Comment `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Data
+}
+
+func searchComments(t *testing.T, app *App, params url.Values) (int, []Comment) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/comments/search?"+params.Encode(), nil))
+	var resp struct {
+		Data []Comment `json:"data"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	return rr.Code, resp.Data
+}
+
+func TestSearchComments(t *testing.T) {
+	app := newTestApp(t)
+
+	createTestComment(t, app, `{"news_id":1,"author":"anna","text":"Отличная новость про Выборы"}`)
+	createTestComment(t, app, `{"news_id":1,"author":"boris","text":"Выборы перенесли, жаль"}`)
+	createTestComment(t, app, `{"news_id":2,"author":"anna","text":"Погода испортилась"}`)
+
+	cases := []struct {
+		params url.Values
+		want   int
+	}{
+		{url.Values{"q": {"выборы"}}, 2},
+		{url.Values{"q": {"ВЫБОРЫ"}, "author": {"boris"}}, 1},
+		{url.Values{"q": {"выбор*"}, "news_id": {"2"}}, 0},
+		{url.Values{"q": {"погод*"}}, 1},
+		{url.Values{"author": {"anna"}}, 2},
+		{url.Values{"q": {`"новость" OR) NEAR`}}, 0},
+		{url.Values{"news_id": {"1"}, "from": {time.Now().UTC().Format("2006-01-02")}, "to": {time.Now().UTC().Format("2006-01-02")}}, 2},
+		{url.Values{"news_id": {"1"}, "to": {"2000-01-01"}}, 0},
+	}
+	for _, c := range cases {
+		code, comments := searchComments(t, app, c.params)
+		if code != http.StatusOK || len(comments) != c.want {
+			t.Errorf("%v: ожидалось %d комментариев, получено %d (статус %d)", c.params, c.want, len(comments), code)
+		}
+	}
+
+	_, comments := searchComments(t, app, url.Values{"q": {"жаль"}})
+	if len(comments) != 1 || comments[0].Author != "boris" || comments[0].CreatedAt.IsZero() {
+		t.Errorf("Неверный результат поиска: %+v", comments)
+	}
+}
+
+func TestSearchCommentsValidation(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, params := range []url.Values{
+		{},
+		{"news_id": {"abc"}},
+		{"q": {"test"}, "from": {"yesterday"}},
+		{"q": {"test"}, "from": {"2024-02-01"}, "to": {"2024-01-01"}},
+		{"q": {"***"}},
+	} {
+		if code, _ := searchComments(t, app, params); code != http.StatusBadRequest {
+			t.Errorf("%v: ожидался статус %d, получен %d", params, http.StatusBadRequest, code)
+		}
+	}
+}
+
+func TestMigrateLegacySchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			news_id INTEGER NOT NULL,
+			parent_id INTEGER,
+			text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO comments (news_id, text) VALUES (1, 'старый комментарий');
+	`)
+	legacy.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp(testConfig(path))
+	t.Cleanup(func() { db.Close() })
+
+	_, comments := searchComments(t, app, url.Values{"q": {"старый"}})
+	if len(comments) != 1 {
+		t.Fatalf("Существующие комментарии должны попасть в поисковый индекс, получено %d", len(comments))
+	}
+	if comments[0].Status != StatusPublished {
+		t.Errorf("Существующие комментарии должны считаться опубликованными, получено %q", comments[0].Status)
+	}
+}
+
+func TestSearchCommentsOriginalText(t *testing.T) {
+	app := newTestApp(t)
+
+	masked := createTestComment(t, app, `{"news_id":1,"text":"Сам •••••","original_text":"Сам дурак"}`)
+	createTestComment(t, app, `{"news_id":1,"text":"Без замечаний"}`)
+
+	// Модератор находит комментарий по словам, скрытым маской
+	_, comments := searchComments(t, app, url.Values{"q": {"дурак"}})
+	if len(comments) != 1 || comments[0].ID != masked.ID || comments[0].OriginalText != "Сам дурак" {
+		t.Fatalf("Поиск должен учитывать исходный текст: %+v", comments)
+	}
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/comments/"+strconv.Itoa(masked.ID), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Комментарий не удален: %d %s", rr.Code, rr.Body.String())
+	}
+	if _, comments := searchComments(t, app, url.Values{"q": {"дурак"}}); len(comments) != 0 {
+		t.Errorf("Удаленный комментарий не должен находиться: %+v", comments)
+	}
+}
+
+func TestMigrateFTS4Index(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fts4.db")
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Индекс в том виде, в каком его создавали прежние версии
+	_, err = legacy.Exec(`
+		CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			news_id INTEGER NOT NULL,
+			parent_id INTEGER,
+			author TEXT NOT NULL DEFAULT '',
+			text TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'published',
+			original_text TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE VIRTUAL TABLE comments_fts USING fts4(content="comments", text, tokenize=unicode61);
+		CREATE TRIGGER comments_fts_ai AFTER INSERT ON comments BEGIN
+			INSERT INTO comments_fts(docid, text) VALUES (new.id, new.text);
+		END;
+		CREATE TRIGGER comments_fts_bd BEFORE DELETE ON comments BEGIN
+			DELETE FROM comments_fts WHERE docid = old.id;
+		END;
+		CREATE TRIGGER comments_fts_bu BEFORE UPDATE ON comments BEGIN
+			DELETE FROM comments_fts WHERE docid = old.id;
+		END;
+		CREATE TRIGGER comments_fts_au AFTER UPDATE ON comments BEGIN
+			INSERT INTO comments_fts(docid, text) VALUES (new.id, new.text);
+		END;
+		INSERT INTO comments (news_id, text, original_text) VALUES (1, 'старый •••••', 'старый дурак');
+	`)
+	legacy.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp(testConfig(path))
+	t.Cleanup(func() { db.Close() })
+
+	var ftsSQL string
+	db.QueryRow(`SELECT sql FROM sqlite_master WHERE name = 'comments_fts'`).Scan(&ftsSQL)
+	if !strings.Contains(ftsSQL, "fts5") {
+		t.Fatalf("Индекс FTS4 должен быть заменен на FTS5: %s", ftsSQL)
+	}
+	for _, q := range []string{"старый", "дурак"} {
+		if _, comments := searchComments(t, app, url.Values{"q": {q}}); len(comments) != 1 {
+			t.Errorf("%q: существующий комментарий должен попасть в новый индекс, получено %d", q, len(comments))
+		}
+	}
+	createTestComment(t, app, `{"news_id":1,"text":"новый комментарий"}`)
+	if _, comments := searchComments(t, app, url.Values{"q": {"новый"}}); len(comments) != 1 {
+		t.Errorf("Новый комментарий должен индексироваться, получено %d", len(comments))
+	}
+}
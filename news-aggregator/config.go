@@ -0,0 +1,37 @@
+package main
+
+import (
+	"pkg/config"
+	"pkg/server"
+)
+
+type Config struct {
+	Port            string                `yaml:"port" desc:"порт HTTP-сервера"`
+	GRPCPort        string                `yaml:"grpc_port" desc:"порт внутреннего gRPC API"`
+	DefaultPageSize int                   `yaml:"default_page_size" desc:"размер страницы по умолчанию"`
+	MaxPageSize     int                   `yaml:"max_page_size" desc:"максимальный размер страницы"`
+	Shutdown        server.ShutdownConfig `yaml:"shutdown"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Port:            "8083",
+		GRPCPort:        "9083",
+		DefaultPageSize: 10,
+		MaxPageSize:     100,
+		Shutdown:        server.DefaultShutdownConfig(),
+	}
+}
+
+func (c *Config) Validate() error {
+	var errs config.Errors
+	errs.Port("port", c.Port)
+	errs.Port("grpc_port", c.GRPCPort)
+	errs.Positive("default_page_size", c.DefaultPageSize)
+	errs.Positive("max_page_size", c.MaxPageSize)
+	if c.DefaultPageSize > c.MaxPageSize {
+		errs.Add("default_page_size", "must not exceed max_page_size (%d)", c.MaxPageSize)
+	}
+	c.Shutdown.Validate(&errs, "shutdown")
+	return errs.Err()
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Конфигурация по умолчанию должна быть корректной: %v", err)
+	}
+
+	cfg.DefaultPageSize = 200
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "default_page_size:") {
+		t.Errorf("Ожидалась ошибка default_page_size, получено: %v", err)
+	}
+}
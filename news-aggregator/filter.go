@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"pkg/httpx"
+)
+
+// Варианты сортировки списка новостей
+const (
+	SortDateDesc  = "date_desc"
+	SortDateAsc   = "date_asc"
+	SortRelevance = "relevance"
+)
+
+// NewsFilter — параметры фильтрации и сортировки списка новостей
+type NewsFilter struct {
+	Search   string
+	From     time.Time
+	To       time.Time
+	Source   string
+	Category string
+	Sort     string
+}
+
+// ParseNewsFilter — разбирает и проверяет параметры запроса
+func ParseNewsFilter(q url.Values) (NewsFilter, *httpx.FieldError) {
+	f := NewsFilter{
+		Search:   strings.TrimSpace(q.Get("search")),
+		Source:   strings.TrimSpace(q.Get("source")),
+		Category: strings.TrimSpace(q.Get("category")),
+		Sort:     q.Get("sort"),
+	}
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.From, err = httpx.ParseDate(v, false); err != nil {
+			return f, &httpx.FieldError{Field: "from", Code: httpx.FieldInvalid, Message: "Invalid from date, expected RFC3339 or YYYY-MM-DD"}
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.To, err = httpx.ParseDate(v, true); err != nil {
+			return f, &httpx.FieldError{Field: "to", Code: httpx.FieldInvalid, Message: "Invalid to date, expected RFC3339 or YYYY-MM-DD"}
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return f, &httpx.FieldError{Field: "from", Code: httpx.FieldInvalid, Message: "from must not be after to"}
+	}
+
+	switch f.Sort {
+	case "":
+		f.Sort = SortDateDesc
+		if f.Search != "" {
+			f.Sort = SortRelevance
+		}
+	case SortDateDesc, SortDateAsc:
+	case SortRelevance:
+		if f.Search == "" {
+			return f, &httpx.FieldError{Field: "sort", Code: httpx.FieldInvalid, Message: "sort=relevance requires search"}
+		}
+	default:
+		return f, &httpx.FieldError{Field: "sort", Code: httpx.FieldUnknown, Message: "Invalid sort, expected date_desc, date_asc or relevance"}
+	}
+
+	return f, nil
+}
+
+// Match — проверяет новость по фильтрам, кроме поискового запроса
+func (f NewsFilter) Match(n News) bool {
+	if !f.From.IsZero() && n.Date.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && n.Date.After(f.To) {
+		return false
+	}
+	if f.Source != "" && !strings.EqualFold(n.Source, f.Source) {
+		return false
+	}
+	if f.Category != "" && !strings.EqualFold(n.Category, f.Category) {
+		found := false
+		for _, tag := range n.Tags {
+			if strings.EqualFold(tag, f.Category) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SortNews — упорядочивает новости; результаты поиска уже отсортированы по релевантности
+func (f NewsFilter) SortNews(news []News) {
+	switch f.Sort {
+	case SortDateAsc:
+		sort.SliceStable(news, func(i, j int) bool { return news[i].Date.Before(news[j].Date) })
+	case SortDateDesc:
+		sort.SliceStable(news, func(i, j int) bool { return news[i].Date.After(news[j].Date) })
+	}
+}
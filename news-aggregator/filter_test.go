@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pkg/httpx"
+)
+
+func getNewsIDs(t *testing.T, app *App, query string) (int, []int) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news?"+query, nil))
+	var resp struct {
+		Data []News `json:"data"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	ids := make([]int, 0, len(resp.Data))
+	for _, n := range resp.Data {
+		ids = append(ids, n.ID)
+	}
+	return rr.Code, ids
+}
+
+func TestGetNewsFilters(t *testing.T) {
+	app := NewApp(DefaultConfig())
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{3, 2, 1}},
+		{"sort=date_asc", []int{1, 2, 3}},
+		{"from=2023-01-02", []int{3, 2}},
+		{"to=2023-01-02", []int{2, 1}},
+		{"from=2023-01-02T00:00:00Z&to=2023-01-02T23:59:59Z", []int{2}},
+		{"source=RIA", []int{3, 1}},
+		{"category=economy", []int{2}},
+		{"category=football", []int{3}},
+		{"search=новости&source=ria&sort=date_asc", []int{1, 3}},
+		{"category=weather", []int{}},
+	}
+	for _, c := range cases {
+		code, ids := getNewsIDs(t, app, c.query)
+		if code != http.StatusOK || !equalIDs(ids, c.want) {
+			t.Errorf("%q: ожидалось %v, получено %v (статус %d)", c.query, c.want, ids, code)
+		}
+	}
+}
+
+func TestGetNewsPagination(t *testing.T) {
+	app := NewApp(DefaultConfig())
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news?source=ria&page=2&page_size=1", nil))
+	var resp struct {
+		Data       []News           `json:"data"`
+		Pagination httpx.Pagination `json:"pagination"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	want := httpx.Pagination{Page: 2, PageSize: 1, Total: 2, PageCount: 2}
+	if rr.Code != http.StatusOK || len(resp.Data) != 1 || resp.Pagination != want {
+		t.Errorf("Ожидалась пагинация %+v, получено %d %s", want, rr.Code, rr.Body.String())
+	}
+}
+
+func TestGetNewsFilterValidation(t *testing.T) {
+	app := NewApp(DefaultConfig())
+
+	for _, query := range []string{
+		"from=01.01.2023",
+		"to=yesterday",
+		"from=2023-01-03&to=2023-01-01",
+		"sort=title",
+		"sort=relevance",
+	} {
+		if code, _ := getNewsIDs(t, app, query); code != http.StatusBadRequest {
+			t.Errorf("%q: ожидался статус %d, получен %d", query, http.StatusBadRequest, code)
+		}
+	}
+}
+
+func TestGetNewsFilterProblem(t *testing.T) {
+	app := NewApp(DefaultConfig())
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news?sort=random", nil))
+
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != httpx.ProblemContentType {
+		t.Fatalf("Ожидалась ошибка %d в формате problem+json, получено %d %s", http.StatusBadRequest, rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var p httpx.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Code != httpx.CodeValidationFailed || len(p.Errors) != 1 || p.Errors[0].Field != "sort" || p.Errors[0].Code != httpx.FieldUnknown {
+		t.Errorf("Неверное описание ошибки: %+v", p)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net/url"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/newspb"
+)
+
+// newsServer — внутренний gRPC API новостей
+type newsServer struct {
+	newspb.UnimplementedNewsServiceServer
+	app *App
+}
+
+func (s *newsServer) ListNews(ctx context.Context, req *newspb.ListNewsRequest) (*newspb.ListNewsResponse, error) {
+	// Фильтр разбирается тем же кодом, что и параметры HTTP-запроса
+	q := url.Values{}
+	for name, value := range map[string]string{
+		"search":   req.GetSearch(),
+		"from":     req.GetFrom(),
+		"to":       req.GetTo(),
+		"source":   req.GetSource(),
+		"category": req.GetCategory(),
+		"sort":     req.GetSort(),
+	} {
+		if value != "" {
+			q.Set(name, value)
+		}
+	}
+	filter, fieldErr := ParseNewsFilter(q)
+	if fieldErr != nil {
+		return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{*fieldErr}))
+	}
+
+	news, pagination := s.app.listNews(filter, int(req.GetPage()), int(req.GetPageSize()))
+	resp := &newspb.ListNewsResponse{Total: int32(pagination.Total)}
+	for _, n := range news {
+		resp.News = append(resp.News, newsToProto(n))
+	}
+	return resp, nil
+}
+
+func (s *newsServer) GetNews(ctx context.Context, req *newspb.GetNewsRequest) (*newspb.News, error) {
+	if req.GetId() < 1 {
+		return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid news ID"}}))
+	}
+	n, ok := findNews(int(req.GetId()))
+	if !ok {
+		return nil, grpcx.Error(errNewsNotFound)
+	}
+	return newsToProto(n), nil
+}
+
+func newsToProto(n News) *newspb.News {
+	msg := &newspb.News{
+		Id:       int64(n.ID),
+		Title:    n.Title,
+		Content:  n.Content,
+		Date:     timestamppb.New(n.Date),
+		Source:   n.Source,
+		Category: n.Category,
+		Tags:     n.Tags,
+		Score:    n.Score,
+	}
+	if n.Highlight != nil {
+		msg.Highlight = &newspb.Highlight{Title: n.Highlight.Title, Content: n.Highlight.Content}
+	}
+	return msg
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/newspb"
+)
+
+func newGRPCClient(t *testing.T) newspb.NewsServiceClient {
+	t.Helper()
+	app := NewApp(DefaultConfig())
+	lis := bufconn.Listen(1 << 20)
+	go app.grpc.Serve(lis)
+	t.Cleanup(app.grpc.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return newspb.NewNewsServiceClient(conn)
+}
+
+func TestGRPCListNews(t *testing.T) {
+	client := newGRPCClient(t)
+
+	resp, err := client.ListNews(context.Background(), &newspb.ListNewsRequest{Source: "ria", Sort: "date_asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetNews()) != 2 || resp.GetNews()[0].GetId() != 1 || resp.GetNews()[1].GetId() != 3 {
+		t.Errorf("Неверный список новостей: %v", resp.GetNews())
+	}
+
+	resp, err = client.ListNews(context.Background(), &newspb.ListNewsRequest{Source: "ria", Page: 2, PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetNews()) != 1 || resp.GetTotal() != 2 {
+		t.Errorf("Ожидалась одна новость из 2, получено %v, total %d", resp.GetNews(), resp.GetTotal())
+	}
+
+	resp, err = client.ListNews(context.Background(), &newspb.ListNewsRequest{Search: "третьей"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetNews()) != 1 || resp.GetNews()[0].GetScore() <= 0 || resp.GetNews()[0].GetHighlight() == nil {
+		t.Errorf("Результат поиска должен содержать score и highlight: %v", resp.GetNews())
+	}
+
+	_, err = client.ListNews(context.Background(), &newspb.ListNewsRequest{Sort: "random"})
+	if p, ok := grpcx.Problem(err); !ok || p.Code != httpx.CodeValidationFailed || len(p.Errors) != 1 || p.Errors[0].Field != "sort" {
+		t.Errorf("Ожидалась ошибка валидации sort, получено %v", err)
+	}
+}
+
+func TestGRPCGetNews(t *testing.T) {
+	client := newGRPCClient(t)
+
+	n, err := client.GetNews(context.Background(), &newspb.GetNewsRequest{Id: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.GetTitle() != "Новость 2" || n.GetDate().AsTime() != newsList[1].Date {
+		t.Errorf("Неверная новость: %v", n)
+	}
+
+	_, err = client.GetNews(context.Background(), &newspb.GetNewsRequest{Id: 100})
+	if p, ok := grpcx.Problem(err); !ok || p.Status != http.StatusNotFound || p.Code != httpx.CodeNotFound {
+		t.Errorf("Ожидалась ошибка not_found, получено %v", err)
+	}
+}
@@ -1,79 +1,69 @@
 package main
 
 import (
-	"encoding/json"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
-	"strings"
-	
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/hlog"
-)
+	"google.golang.org/grpc"
 
-type Config struct {
-	Port string
-}
+	"pkg/config"
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/newspb"
+	"pkg/server"
+)
 
 type App struct {
 	config Config
 	logger zerolog.Logger
 	router chi.Router
+	grpc   *grpc.Server
+	health *server.Health
+	index  *SearchIndex
 }
 
 type News struct {
-	ID      int    `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Date    string `json:"date"`
-}
-
-type Response struct {
-	Status string      `json:"status"`
-	Data   interface{} `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Date      time.Time  `json:"date"`
+	Source    string     `json:"source"`
+	Category  string     `json:"category"`
+	Tags      []string   `json:"tags,omitempty"`
+	Score     float64    `json:"score,omitempty"`
+	Highlight *Highlight `json:"highlight,omitempty"`
 }
 
 var newsList = []News{
-	{ID: 1, Title: "Новость 1", Content: "Содержимое первой новости", Date: "2023-01-01"},
-	{ID: 2, Title: "Новость 2", Content: "Содержимое второй новости", Date: "2023-01-02"},
-	{ID: 3, Title: "Новость 3", Content: "Содержимое третьей новости", Date: "2023-01-03"},
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func LoggerMiddleware(logger *zerolog.Logger) func(http.Handler) http.Handler {
-	return hlog.NewHandler(*logger)
+	{ID: 1, Title: "Новость 1", Content: "Содержимое первой новости", Date: time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC), Source: "ria", Category: "politics", Tags: []string{"elections"}},
+	{ID: 2, Title: "Новость 2", Content: "Содержимое второй новости", Date: time.Date(2023, 1, 2, 12, 30, 0, 0, time.UTC), Source: "tass", Category: "economy", Tags: []string{"markets"}},
+	{ID: 3, Title: "Новость 3", Content: "Содержимое третьей новости", Date: time.Date(2023, 1, 3, 18, 15, 0, 0, time.UTC), Source: "ria", Category: "sport", Tags: []string{"football"}},
 }
 
 func NewApp(config Config) *App {
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
-
-	r := chi.NewRouter()
-
-	r.Use(middleware.Recoverer)
-	r.Use(LoggerMiddleware(&logger))
+	logger := server.NewLogger("news-aggregator")
+	health := server.NewHealth()
+	r := server.NewRouter(logger, health)
 
 	app := &App{
 		config: config,
 		logger: logger,
 		router: r,
+		grpc:   grpcx.NewServer(logger),
+		health: health,
+		index:  NewSearchIndex(newsList),
 	}
 
 	r.Get("/", app.Home)
-	r.Get("/health", app.HealthCheck)
 	r.Get("/news", app.GetNews)
 	r.Get("/news/{id}", app.GetNewsByID)
 
+	newspb.RegisterNewsServiceServer(app.grpc, &newsServer{app: app})
+
 	return app
 }
 
@@ -83,28 +73,52 @@ func (a *App) Home(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("News Aggregator OK"))
 }
 
-func (a *App) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{Status: "ok"})
-}
-
 func (a *App) GetNews(w http.ResponseWriter, r *http.Request) {
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	filter, fieldErr := ParseNewsFilter(r.URL.Query())
+	if fieldErr != nil {
+		httpx.SendValidationError(w, r, *fieldErr)
+		return
+	}
+
+	news, pagination := a.listNews(filter, page, pageSize)
+	httpx.SendPage(w, http.StatusOK, news, pagination)
+}
+
+// listNews — страница новостей, подходящих под фильтр, и ее пагинация; общая часть HTTP и gRPC API
+func (a *App) listNews(filter NewsFilter, page, pageSize int) ([]News, *httpx.Pagination) {
 	if page < 1 {
 		page = 1
 	}
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	if pageSize < 1 || pageSize > a.config.MaxPageSize {
+		pageSize = a.config.DefaultPageSize
 	}
-	search := r.URL.Query().Get("search")
 
-	var filteredNews []News
-	for _, n := range newsList {
-		if search == "" || strings.Contains(strings.ToLower(n.Title), strings.ToLower(search)) || strings.Contains(strings.ToLower(n.Content), strings.ToLower(search)) {
+	filteredNews := []News{}
+	if filter.Search == "" {
+		for _, n := range newsList {
+			if filter.Match(n) {
+				filteredNews = append(filteredNews, n)
+			}
+		}
+	} else {
+		byID := make(map[int]News, len(newsList))
+		for _, n := range newsList {
+			byID[n.ID] = n
+		}
+		for _, res := range a.index.Search(filter.Search) {
+			n := byID[res.ID]
+			if !filter.Match(n) {
+				continue
+			}
+			n.Score = res.Score
+			highlight := res.Highlight
+			n.Highlight = &highlight
 			filteredNews = append(filteredNews, n)
 		}
 	}
+	filter.SortNews(filteredNews)
 
 	start := (page - 1) * pageSize
 	end := start + pageSize
@@ -115,57 +129,57 @@ func (a *App) GetNews(w http.ResponseWriter, r *http.Request) {
 		end = len(filteredNews)
 	}
 
-	paginatedNews := filteredNews[start:end]
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Response{
-		Status: "success",
-		Data:   paginatedNews,
-	})
+	return filteredNews[start:end], httpx.NewPagination(page, pageSize, len(filteredNews))
 }
 
 func (a *App) GetNewsByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil || id < 1 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(Response{
-			Status: "error",
-			Error:  "Invalid news ID",
-		})
+		httpx.SendValidationError(w, r, httpx.FieldError{Field: "id", Code: httpx.FieldInvalid, Message: "Invalid news ID"})
 		return
 	}
 
+	n, ok := findNews(id)
+	if !ok {
+		httpx.SendProblem(w, r, errNewsNotFound)
+		return
+	}
+	httpx.SendResponse(w, http.StatusOK, n)
+}
+
+var errNewsNotFound = httpx.NewProblem(http.StatusNotFound, httpx.CodeNotFound, "News not found")
+
+func findNews(id int) (News, bool) {
 	for _, n := range newsList {
 		if n.ID == id {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(Response{
-				Status: "success",
-				Data:   n,
-			})
-			return
+			return n, true
 		}
 	}
-
-	w.WriteHeader(http.StatusNotFound)
-	json.NewEncoder(w).Encode(Response{
-		Status: "error",
-		Error:  "News not found",
-	})
+	return News{}, false
 }
 
 func (a *App) Run() error {
-	return http.ListenAndServe(":"+a.config.Port, a.router)
+	srv := &server.Server{
+		Addr:            ":" + a.config.Port,
+		Handler:         a.router,
+		Logger:          a.logger,
+		Health:          a.health,
+		GRPCAddr:        ":" + a.config.GRPCPort,
+		GRPC:            a.grpc,
+		DrainDelay:      a.config.Shutdown.DrainDelay,
+		ShutdownTimeout: a.config.Shutdown.Timeout,
+	}
+	return srv.Run()
 }
 
 func main() {
-	config := Config{
-		Port: getEnv("PORT", "8083"),
-	}
+	cfg := DefaultConfig()
+	config.MustLoad("news-aggregator", &cfg)
 
-	app := NewApp(config)
+	app := NewApp(cfg)
 
-	log.Printf("News Aggregator запущен на порту %s", config.Port)
+	log.Printf("News Aggregator запущен на порту %s", cfg.Port)
 	if err := app.Run(); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+)
+
+// Поля новости, участвующие в поиске
+const (
+	fieldTitle = iota
+	fieldContent
+	fieldCount
+)
+
+// fieldBoost — вес совпадения в каждом поле: совпадение в заголовке важнее
+var fieldBoost = [fieldCount]float64{fieldTitle: 3, fieldContent: 1}
+
+// snippetRadius — сколько символов текста показывать вокруг первого совпадения
+const snippetRadius = 80
+
+// Highlight — фрагменты новости с выделенными совпадениями
+type Highlight struct {
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// SearchResult — найденная новость и ее релевантность
+type SearchResult struct {
+	ID        int
+	Score     float64
+	Highlight Highlight
+}
+
+// token — слово текста с позицией в исходной строке
+type token struct {
+	term  string // слово в нижнем регистре
+	stem  string // основа слова
+	start int    // смещение начала в байтах
+	end   int    // смещение конца в байтах
+}
+
+// posting — вхождение основы в поле документа
+type posting struct {
+	doc   int
+	field int
+	pos   int
+}
+
+// indexedDoc — проиндексированная новость
+type indexedDoc struct {
+	id     int
+	fields [fieldCount]string
+	tokens [fieldCount][]token
+}
+
+// SearchIndex — инвертированный индекс по заголовкам и текстам новостей
+type SearchIndex struct {
+	docs     []indexedDoc
+	postings map[string][]posting // основа → вхождения
+	vocab    []string             // отсортированный словарь слов для префиксного поиска
+	stems    map[string]string    // слово → основа
+}
+
+// NewSearchIndex — строит индекс по списку новостей
+func NewSearchIndex(news []News) *SearchIndex {
+	idx := &SearchIndex{
+		postings: make(map[string][]posting),
+		stems:    make(map[string]string),
+	}
+	for _, n := range news {
+		doc := indexedDoc{id: n.ID}
+		doc.fields[fieldTitle] = n.Title
+		doc.fields[fieldContent] = n.Content
+		docNum := len(idx.docs)
+		for f := 0; f < fieldCount; f++ {
+			doc.tokens[f] = tokenize(doc.fields[f])
+			for pos, t := range doc.tokens[f] {
+				idx.postings[t.stem] = append(idx.postings[t.stem], posting{doc: docNum, field: f, pos: pos})
+				idx.stems[t.term] = t.stem
+			}
+		}
+		idx.docs = append(idx.docs, doc)
+	}
+	for term := range idx.stems {
+		idx.vocab = append(idx.vocab, term)
+	}
+	sort.Strings(idx.vocab)
+	return idx
+}
+
+// tokenize — разбивает текст на слова и вычисляет их основы
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+	return tokens
+}
+
+func newToken(text string, start, end int) token {
+	term := strings.ToLower(text[start:end])
+	return token{term: term, stem: stem(term), start: start, end: end}
+}
+
+// stem — возвращает основу слова, выбирая стеммер по алфавиту
+func stem(term string) string {
+	for _, r := range term {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return russian.Stem(strings.ReplaceAll(term, "ё", "е"), true)
+		}
+	}
+	return english.Stem(term, true)
+}
+
+// clauseKind — тип условия запроса
+type clauseKind int
+
+const (
+	clauseTerm clauseKind = iota
+	clausePrefix
+	clausePhrase
+)
+
+// clause — одно условие запроса; все условия должны выполняться одновременно
+type clause struct {
+	kind   clauseKind
+	terms  []token
+	prefix string
+}
+
+// parseQuery — разбирает запрос: слова, "фразы в кавычках" и префиксы вида слово*
+func parseQuery(q string) []clause {
+	var clauses []clause
+	for len(q) > 0 {
+		q = strings.TrimLeftFunc(q, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '"' })
+		if q == "" {
+			break
+		}
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			phrase := q[1:]
+			if end >= 0 {
+				phrase, q = q[1:end+1], q[end+2:]
+			} else {
+				q = ""
+			}
+			switch terms := tokenize(phrase); len(terms) {
+			case 0:
+			case 1:
+				clauses = append(clauses, clause{kind: clauseTerm, terms: terms})
+			default:
+				clauses = append(clauses, clause{kind: clausePhrase, terms: terms})
+			}
+			continue
+		}
+		end := strings.IndexFunc(q, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		word := q
+		if end >= 0 {
+			word, q = q[:end], q[end:]
+		} else {
+			q = ""
+		}
+		if strings.HasPrefix(q, "*") {
+			clauses = append(clauses, clause{kind: clausePrefix, prefix: strings.ToLower(word)})
+			q = q[1:]
+			continue
+		}
+		clauses = append(clauses, clause{kind: clauseTerm, terms: tokenize(word)})
+	}
+	return clauses
+}
+
+// hit — совпадение условия в поле документа: позиция первого слова и длина в словах
+type hit struct {
+	field int
+	pos   int
+	size  int
+}
+
+// match — находит документы, удовлетворяющие условию
+func (idx *SearchIndex) match(c clause) map[int][]hit {
+	result := make(map[int][]hit)
+	switch c.kind {
+	case clauseTerm:
+		for _, p := range idx.postings[c.terms[0].stem] {
+			result[p.doc] = append(result[p.doc], hit{field: p.field, pos: p.pos, size: 1})
+		}
+	case clausePrefix:
+		seen := make(map[string]bool)
+		i := sort.SearchStrings(idx.vocab, c.prefix)
+		for ; i < len(idx.vocab) && strings.HasPrefix(idx.vocab[i], c.prefix); i++ {
+			s := idx.stems[idx.vocab[i]]
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			for _, p := range idx.postings[s] {
+				if strings.HasPrefix(idx.docs[p.doc].tokens[p.field][p.pos].term, c.prefix) {
+					result[p.doc] = append(result[p.doc], hit{field: p.field, pos: p.pos, size: 1})
+				}
+			}
+		}
+	case clausePhrase:
+		for _, p := range idx.postings[c.terms[0].stem] {
+			tokens := idx.docs[p.doc].tokens[p.field]
+			if p.pos+len(c.terms) > len(tokens) {
+				continue
+			}
+			matched := true
+			for k := 1; k < len(c.terms); k++ {
+				if tokens[p.pos+k].stem != c.terms[k].stem {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				result[p.doc] = append(result[p.doc], hit{field: p.field, pos: p.pos, size: len(c.terms)})
+			}
+		}
+	}
+	return result
+}
+
+// Search — ищет новости по запросу и возвращает их в порядке убывания релевантности
+func (idx *SearchIndex) Search(query string) []SearchResult {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	var candidates map[int]bool
+	scores := make(map[int]float64)
+	hits := make(map[int][]hit)
+	for _, c := range clauses {
+		matches := idx.match(c)
+		next := make(map[int]bool)
+		idf := math.Log(1 + float64(len(idx.docs))/float64(len(matches)+1))
+		for doc, docHits := range matches {
+			if candidates != nil && !candidates[doc] {
+				continue
+			}
+			next[doc] = true
+			var tf [fieldCount]int
+			for _, h := range docHits {
+				tf[h.field]++
+			}
+			for f, n := range tf {
+				if n > 0 {
+					scores[doc] += idf * fieldBoost[f] * (1 + math.Log(float64(n)))
+				}
+			}
+			hits[doc] = append(hits[doc], docHits...)
+		}
+		candidates = next
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	results := make([]SearchResult, 0, len(candidates))
+	for doc := range candidates {
+		d := idx.docs[doc]
+		results = append(results, SearchResult{
+			ID:    d.id,
+			Score: math.Round(scores[doc]*1000) / 1000,
+			Highlight: Highlight{
+				Title:   highlight(d.fields[fieldTitle], d.tokens[fieldTitle], hits[doc], fieldTitle, false),
+				Content: highlight(d.fields[fieldContent], d.tokens[fieldContent], hits[doc], fieldContent, true),
+			},
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// highlight — оборачивает совпадения в <mark>; при snippet возвращает только фрагмент вокруг первого совпадения.
+// Текст экранируется, поэтому результат безопасно вставлять в HTML.
+func highlight(text string, tokens []token, hits []hit, field int, snippet bool) string {
+	type span struct{ start, end int }
+	var spans []span
+	for _, h := range hits {
+		if h.field == field {
+			spans = append(spans, span{tokens[h.pos].start, tokens[h.pos+h.size-1].end})
+		}
+	}
+	if len(spans) == 0 {
+		return ""
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	from, to := 0, len(text)
+	if snippet {
+		from = backRunes(text, spans[0].start, snippetRadius)
+		to = forwardRunes(text, spans[0].end, snippetRadius)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	cur := from
+	for _, s := range spans {
+		if s.start < cur || s.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[cur:s.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[s.start:s.end]))
+		b.WriteString("</mark>")
+		cur = s.end
+	}
+	b.WriteString(html.EscapeString(text[cur:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// backRunes — смещение на n символов назад от pos
+func backRunes(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	return pos
+}
+
+// forwardRunes — смещение на n символов вперед от pos
+func forwardRunes(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
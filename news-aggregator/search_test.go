@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+var searchFixture = []News{
+	{ID: 1, Title: "Выборы в городе", Content: "Жители города выбирают мэра. Новости о выборах будут выходить каждый день."},
+	{ID: 2, Title: "Погода на неделю", Content: "Синоптики обещают дожди; выборы перенесут, если погода испортится."},
+	{ID: 3, Title: "Running the marathon", Content: "Thousands of runners ran through the city <center>."},
+	{ID: 4, Title: "Городские новости", Content: "Новый парк открылся в центре города."},
+}
+
+func searchIDs(results []SearchResult) []int {
+	ids := make([]int, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSearchStemmingAndRanking(t *testing.T) {
+	idx := NewSearchIndex(searchFixture)
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		// Русская морфология и буст заголовка: новость 1 совпала в заголовке
+		{"выборов", []int{1, 2}},
+		{"новость", []int{4, 1}},
+		// Английская морфология
+		{"run", []int{3}},
+		// Фраза: слова должны идти подряд
+		{`"новости о выборах"`, []int{1}},
+		{`"выборы новости"`, nil},
+		// Префикс
+		{"город*", []int{1, 4}},
+		{"marath*", []int{3}},
+		// Все условия должны выполняться
+		{"погода выборы", []int{2}},
+		{"несуществующее", nil},
+	}
+	for _, c := range cases {
+		if got := searchIDs(idx.Search(c.query)); !equalIDs(got, c.want) {
+			t.Errorf("%q: ожидалось %v, получено %v", c.query, c.want, got)
+		}
+	}
+}
+
+func TestSearchHighlight(t *testing.T) {
+	idx := NewSearchIndex(searchFixture)
+
+	results := idx.Search("city")
+	if len(results) != 1 {
+		t.Fatalf("Ожидался 1 результат, получено %d", len(results))
+	}
+	want := "Thousands of runners ran through the <mark>city</mark> &lt;center&gt;."
+	if results[0].Highlight.Content != want {
+		t.Errorf("Неверный фрагмент: %q", results[0].Highlight.Content)
+	}
+	if results[0].Highlight.Title != "" {
+		t.Errorf("Заголовок без совпадений не должен подсвечиваться: %q", results[0].Highlight.Title)
+	}
+
+	results = NewSearchIndex([]News{{ID: 1, Content: strings.Repeat("а ", 100) + "Новости о выборах" + strings.Repeat(" б", 100)}}).
+		Search(`"новости о выборах"`)
+	want = "…" + strings.Repeat("а ", 40) + "<mark>Новости о выборах</mark>" + strings.Repeat(" б", 40) + "…"
+	if len(results) != 1 || results[0].Highlight.Content != want {
+		t.Errorf("Неверный фрагмент фразы: %+v", results)
+	}
+}
+
+func TestGetNewsSearch(t *testing.T) {
+	app := NewApp(DefaultConfig())
+
+	rr := httptest.NewRecorder()
+	app.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news?search="+url.QueryEscape("втор*"), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус %d, получен %d", http.StatusOK, rr.Code)
+	}
+
+	var resp struct {
+		Data []News `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != 2 || resp.Data[0].Highlight == nil || resp.Data[0].Score == 0 {
+		t.Errorf("Неверный результат поиска: %+v", resp.Data)
+	}
+}
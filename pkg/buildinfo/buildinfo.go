@@ -0,0 +1,38 @@
+// Package buildinfo — версия сборки сервиса.
+package buildinfo
+
+import "runtime/debug"
+
+// version задается при сборке: -ldflags "-X pkg/buildinfo.version=1.2.3"
+var version string
+
+// Version — версия сервиса: значение из -ldflags, иначе ревизия VCS из сведений о сборке, иначе "dev"
+func Version() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,266 @@
+// Package config — типизированная конфигурация сервисов: файл YAML,
+// переменные окружения и флаги командной строки с проверкой при запуске.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validator — конфигурация, которая умеет проверять себя после загрузки
+type Validator interface {
+	Validate() error
+}
+
+// Options — результат разбора служебных флагов
+type Options struct {
+	// File — путь к файлу конфигурации (флаг --config или CONFIG_FILE)
+	File string
+	// PrintConfig — запрошен вывод итоговой конфигурации (--print-config)
+	PrintConfig bool
+}
+
+// field — параметр конфигурации, найденный в структуре по тегам
+type field struct {
+	key    string // путь в файле: limits.max_search_length
+	env    string // переменная окружения: LIMITS_MAX_SEARCH_LENGTH или значение тега env
+	flag   string // флаг командной строки: limits.max-search-length
+	desc   string
+	secret bool
+	value  reflect.Value
+}
+
+// Load — заполняет cfg, уже содержащий значения по умолчанию, из файла YAML,
+// переменных окружения и флагов командной строки (в порядке возрастания приоритета)
+// и проверяет результат.
+//
+// Ключи файла задаются тегом yaml. Имя переменной окружения по умолчанию — путь ключа
+// в верхнем регистре (limits.max_search_length → LIMITS_MAX_SEARCH_LENGTH), его можно
+// переопределить тегом env. Имя флага — путь ключа через дефис (--limits.max-search-length).
+// Поля с тегом secret:"true" скрываются при выводе.
+func Load(name string, cfg Validator, args []string, lookupEnv func(string) (string, bool)) (Options, error) {
+	var opts Options
+	fields := collectFields(reflect.ValueOf(cfg).Elem(), "", "")
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.File, "config", "", "путь к файлу конфигурации YAML (или CONFIG_FILE)")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "вывести итоговую конфигурацию и выйти")
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		fs.Func(f.flag, f.desc, func(s string) error {
+			flagValues[f.flag] = s
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return opts, fmt.Errorf("usage of %s:\n%s", name, usage(fs))
+		}
+		return opts, fmt.Errorf("config: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("config: unexpected argument %q", fs.Arg(0))
+	}
+
+	if opts.File == "" {
+		opts.File, _ = lookupEnv("CONFIG_FILE")
+	}
+	if opts.File != "" {
+		if err := loadFile(opts.File, cfg); err != nil {
+			return opts, err
+		}
+	}
+
+	for _, f := range fields {
+		if raw, ok := lookupEnv(f.env); ok && raw != "" {
+			if err := setValue(f.value, raw); err != nil {
+				return opts, fmt.Errorf("config: env %s: %w", f.env, err)
+			}
+		}
+	}
+	for _, f := range fields {
+		if raw, ok := flagValues[f.flag]; ok {
+			if err := setValue(f.value, raw); err != nil {
+				return opts, fmt.Errorf("config: flag --%s: %w", f.flag, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// MustLoad — загружает конфигурацию из аргументов и окружения процесса.
+// При ошибке печатает ее и завершает процесс с кодом 2, при --print-config
+// печатает итоговую конфигурацию и завершает процесс с кодом 0.
+func MustLoad(name string, cfg Validator) {
+	opts, err := Load(name, cfg, os.Args[1:], os.LookupEnv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if opts.PrintConfig {
+		if err := Print(os.Stdout, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+}
+
+// Print — выводит конфигурацию в формате YAML, заменяя непустые секреты на [REDACTED]
+func Print(w io.Writer, cfg interface{}) error {
+	node := toNode(reflect.Indirect(reflect.ValueOf(cfg)))
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func loadFile(path string, cfg interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func collectFields(v reflect.Value, keyPrefix, envPrefix string) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if !sf.IsExported() || name == "-" || name == "" {
+			continue
+		}
+		key := keyPrefix + name
+		env := envPrefix + strings.ToUpper(name)
+		if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+			fields = append(fields, collectFields(v.Field(i), key+".", env+"_")...)
+			continue
+		}
+		if tag := sf.Tag.Get("env"); tag != "" {
+			env = tag
+		}
+		fields = append(fields, field{
+			key:    key,
+			env:    env,
+			flag:   strings.ReplaceAll(key, "_", "-"),
+			desc:   sf.Tag.Get("desc"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+	return fields
+}
+
+// setValue — разбирает строковое значение переменной окружения или флага в поле
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// toNode — строит YAML-представление конфигурации; длительности выводятся как 30s, секреты скрываются
+func toNode(v reflect.Value) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if !sf.IsExported() || name == "-" || name == "" {
+			continue
+		}
+		fv := v.Field(i)
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+
+		var value *yaml.Node
+		switch {
+		case sf.Type == durationType:
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(fv.Int()).String()}
+		case sf.Type.Kind() == reflect.Struct:
+			value = toNode(fv)
+		case sf.Tag.Get("secret") == "true" && !fv.IsZero():
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: "[REDACTED]"}
+		default:
+			value = &yaml.Node{}
+			if err := value.Encode(fv.Interface()); err != nil {
+				value = &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(fv.Interface())}
+			}
+		}
+		node.Content = append(node.Content, key, value)
+	}
+	return node
+}
+
+func usage(fs *flag.FlagSet) string {
+	var b strings.Builder
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+	return b.String()
+}
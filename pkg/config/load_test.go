@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Port    string        `yaml:"port" desc:"порт"`
+	Token   string        `yaml:"token" env:"API_TOKEN" secret:"true"`
+	Timeout time.Duration `yaml:"timeout"`
+	Limits  struct {
+		MaxLength int      `yaml:"max_length"`
+		Words     []string `yaml:"words"`
+	} `yaml:"limits"`
+}
+
+func (c *testConfig) Validate() error {
+	var errs Errors
+	errs.Port("port", c.Port)
+	errs.PositiveDuration("timeout", c.Timeout)
+	errs.Positive("limits.max_length", c.Limits.MaxLength)
+	return errs.Err()
+}
+
+func defaultTestConfig() *testConfig {
+	cfg := &testConfig{Port: "8080", Timeout: 30 * time.Second}
+	cfg.Limits.MaxLength = 100
+	return cfg
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "port: \"9000\"\ntimeout: 5s\nlimits:\n  max_length: 50\n  words: [a, b]\n")
+
+	cfg := defaultTestConfig()
+	_, err := Load("test", cfg, []string{"--config", path, "--limits.max-length", "70"}, env(map[string]string{
+		"TIMEOUT":   "7s",
+		"API_TOKEN": "secret",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != "9000" {
+		t.Errorf("Значение из файла должно заменять значение по умолчанию: %q", cfg.Port)
+	}
+	if cfg.Timeout != 7*time.Second {
+		t.Errorf("Переменная окружения должна заменять значение из файла: %s", cfg.Timeout)
+	}
+	if cfg.Limits.MaxLength != 70 {
+		t.Errorf("Флаг должен заменять остальные источники: %d", cfg.Limits.MaxLength)
+	}
+	if cfg.Token != "secret" || strings.Join(cfg.Limits.Words, ",") != "a,b" {
+		t.Errorf("Неверная конфигурация: %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "неизвестный ключ в файле",
+			args: []string{"--config", writeFile(t, "prot: 8080\n")},
+			want: []string{"field prot not found"},
+		},
+		{
+			name: "некорректное значение в окружении",
+			env:  map[string]string{"LIMITS_MAX_LENGTH": "many"},
+			want: []string{"env LIMITS_MAX_LENGTH", `invalid integer "many"`},
+		},
+		{
+			name: "все ошибки проверки сразу",
+			args: []string{"--port", "0", "--timeout", "0s"},
+			want: []string{`port: must be a port number between 1 and 65535, got "0"`, "timeout: must be a positive duration"},
+		},
+		{
+			name: "неизвестный флаг",
+			args: []string{"--verbose"},
+			want: []string{"flag provided but not defined: -verbose"},
+		},
+	}
+	for _, c := range cases {
+		_, err := Load("test", defaultTestConfig(), c.args, env(c.env))
+		if err == nil {
+			t.Errorf("%s: ожидалась ошибка", c.name)
+			continue
+		}
+		for _, want := range c.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: в ошибке %q нет %q", c.name, err, want)
+			}
+		}
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := defaultTestConfig()
+	opts, err := Load("test", cfg, []string{"--print-config", "--token", "s3cret"}, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.PrintConfig {
+		t.Error("Флаг --print-config не распознан")
+	}
+
+	var b strings.Builder
+	if err := Print(&b, cfg); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if strings.Contains(out, "s3cret") || !strings.Contains(out, "token: '[REDACTED]'") {
+		t.Errorf("Секрет должен быть скрыт:\n%s", out)
+	}
+	if !strings.Contains(out, "timeout: 30s") || !strings.Contains(out, "max_length: 100") {
+		t.Errorf("Неверный вывод конфигурации:\n%s", out)
+	}
+}
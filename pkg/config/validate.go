@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors — накапливает ошибки проверки конфигурации, чтобы сообщить обо всех сразу
+type Errors struct {
+	problems []string
+}
+
+// Add — добавляет ошибку для ключа конфигурации
+func (e *Errors) Add(key, format string, args ...interface{}) {
+	e.problems = append(e.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+// Port — номер порта от 1 до 65535
+func (e *Errors) Port(key, value string) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 65535 {
+		e.Add(key, "must be a port number between 1 and 65535, got %q", value)
+	}
+}
+
+// URL — абсолютный http(s) URL
+func (e *Errors) URL(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		e.Add(key, "must be an absolute http or https URL, got %q", value)
+	}
+}
+
+// HostPort — адрес вида host:port
+func (e *Errors) HostPort(key, value string) {
+	host, port, err := net.SplitHostPort(value)
+	if err != nil || host == "" {
+		e.Add(key, "must be an address in host:port form, got %q", value)
+		return
+	}
+	e.Port(key, port)
+}
+
+// OneOf — одно из допустимых значений
+func (e *Errors) OneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.Add(key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// Required — непустая строка
+func (e *Errors) Required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(key, "is required")
+	}
+}
+
+// Positive — целое больше нуля
+func (e *Errors) Positive(key string, value int) {
+	if value < 1 {
+		e.Add(key, "must be positive, got %d", value)
+	}
+}
+
+// PositiveDuration — длительность больше нуля
+func (e *Errors) PositiveDuration(key string, value time.Duration) {
+	if value <= 0 {
+		e.Add(key, "must be a positive duration, got %s", value)
+	}
+}
+
+// NonNegativeDuration — длительность не меньше нуля
+func (e *Errors) NonNegativeDuration(key string, value time.Duration) {
+	if value < 0 {
+		e.Add(key, "must not be negative, got %s", value)
+	}
+}
+
+// Err — ошибка со всеми найденными проблемами или nil
+func (e *Errors) Err() error {
+	if len(e.problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n  %s", strings.Join(e.problems, "\n  "))
+}
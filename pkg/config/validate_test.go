@@ -0,0 +1,28 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrorsHostPortAndOneOf(t *testing.T) {
+	var errs Errors
+	errs.HostPort("ok", "news-aggregator:9083")
+	errs.OneOf("ok", "grpc", "http", "grpc")
+	if err := errs.Err(); err != nil {
+		t.Fatalf("Корректные значения не должны давать ошибок: %v", err)
+	}
+
+	errs.HostPort("no_port", "news-aggregator")
+	errs.HostPort("bad_port", "news-aggregator:0")
+	errs.OneOf("transport", "soap", "http", "grpc")
+	err := errs.Err()
+	if err == nil {
+		t.Fatal("Ожидались ошибки проверки")
+	}
+	for _, key := range []string{"no_port:", "bad_port:", "transport: must be one of http, grpc"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Ошибка должна упоминать %q: %v", key, err)
+		}
+	}
+}
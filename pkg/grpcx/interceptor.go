@@ -0,0 +1,80 @@
+package grpcx
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"pkg/httpx"
+)
+
+// RequestIDMetadata — ключ метаданных gRPC, в котором передается идентификатор запроса
+const RequestIDMetadata = "x-request-id"
+
+// NewServer — создает gRPC-сервер с общими перехватчиками: восстановление после паники,
+// request ID из метаданных и журнал вызовов в том же формате, что и у HTTP
+func NewServer(logger zerolog.Logger, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(RequestIDInterceptor, LoggerInterceptor(logger), RecovererInterceptor),
+	}, opts...)
+	return grpc.NewServer(opts...)
+}
+
+// RequestIDInterceptor — берет request ID из метаданных вызова или генерирует новый
+// и сохраняет его в контексте, где его найдет httpx.RequestID
+func RequestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = httpx.NewRequestID()
+	}
+	return handler(httpx.WithRequestID(ctx, requestID), req)
+}
+
+// LoggerInterceptor — кладет логгер в контекст вызова и пишет по строке на вызов
+// с методом, кодом gRPC, длительностью и request ID. Подключается после RequestIDInterceptor.
+func LoggerInterceptor(logger zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		l := logger
+		if id := httpx.RequestID(ctx); id != "" {
+			l = logger.With().Str("request_id", id).Logger()
+		}
+		start := time.Now()
+		resp, err := handler(l.WithContext(ctx), req)
+		l.Info().
+			Str("method", info.FullMethod).
+			Str("code", status.Code(err).String()).
+			Dur("duration", time.Since(start)).
+			Msg("rpc")
+		return resp, err
+	}
+}
+
+// RecovererInterceptor — превращает панику обработчика в ошибку Internal
+func RecovererInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			zerolog.Ctx(ctx).Error().Interface("panic", rec).Bytes("stack", debug.Stack()).Str("method", info.FullMethod).Msg("rpc panic")
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// ClientRequestIDInterceptor — передает request ID из контекста во внутренний сервис
+func ClientRequestIDInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := httpx.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadata, id)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
@@ -0,0 +1,84 @@
+package grpcx
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"pkg/httpx"
+	"pkg/pb/censorpb"
+)
+
+type censorFunc func(ctx context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error)
+
+type testCensor struct {
+	censorpb.UnimplementedCensorServiceServer
+	check censorFunc
+}
+
+func (s testCensor) CheckText(ctx context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error) {
+	return s.check(ctx, req)
+}
+
+func dialTestServer(t *testing.T, check censorFunc) censorpb.CensorServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(zerolog.Nop())
+	censorpb.RegisterCensorServiceServer(srv, testCensor{check: check})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(ClientRequestIDInterceptor),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return censorpb.NewCensorServiceClient(conn)
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	var got string
+	client := dialTestServer(t, func(ctx context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error) {
+		got = httpx.RequestID(ctx)
+		return &censorpb.CheckTextResponse{}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(httpx.WithRequestID(context.Background(), "req-1"), time.Second)
+	defer cancel()
+	if _, err := client.CheckText(ctx, &censorpb.CheckTextRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "req-1" {
+		t.Errorf("Request ID не передан во внутренний сервис: %q", got)
+	}
+
+	if _, err := client.CheckText(context.Background(), &censorpb.CheckTextRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got == "" {
+		t.Error("Для вызова без request ID должен генерироваться новый")
+	}
+}
+
+func TestRecovererInterceptor(t *testing.T) {
+	client := dialTestServer(t, func(ctx context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error) {
+		panic("boom")
+	})
+
+	_, err := client.CheckText(context.Background(), &censorpb.CheckTextRequest{})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Паника обработчика должна давать Internal, получено %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package grpcx — общие части внутреннего gRPC API: перенос ошибок RFC 7807
+// через статусы gRPC, передача request ID и журнал вызовов.
+package grpcx
+
+import (
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"pkg/httpx"
+)
+
+// ErrorDomain — домен ErrorInfo, в котором Reason содержит код ошибки httpx
+const ErrorDomain = "httpx"
+
+// httpStatusKey — ключ метаданных ErrorInfo с исходным HTTP-статусом ошибки
+const httpStatusKey = "http_status"
+
+// Error — переводит описание ошибки в статус gRPC. Код ошибки и HTTP-статус
+// передаются в ErrorInfo, ошибки полей — в BadRequest, чтобы шлюз восстановил Problem без потерь.
+func Error(p *httpx.Problem) error {
+	st := status.New(Code(p.Status), p.Detail)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   p.Code,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{httpStatusKey: strconv.Itoa(p.Status)},
+	}}
+	if len(p.Errors) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range p.Errors {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Reason:      f.Code,
+				Description: f.Message,
+			})
+		}
+		details = append(details, br)
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// Problem — восстанавливает описание ошибки из статуса gRPC, созданного Error.
+// Возвращает false, если ошибка не является статусом gRPC или не содержит ErrorInfo.
+func Problem(err error) (*httpx.Problem, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	var p *httpx.Problem
+	var fields []httpx.FieldError
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != ErrorDomain {
+				continue
+			}
+			code, err := strconv.Atoi(d.GetMetadata()[httpStatusKey])
+			if err != nil {
+				code = HTTPStatus(st.Code())
+			}
+			p = httpx.NewProblem(code, d.GetReason(), st.Message())
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				fields = append(fields, httpx.FieldError{Field: v.GetField(), Code: v.GetReason(), Message: v.GetDescription()})
+			}
+		}
+	}
+	if p == nil {
+		return nil, false
+	}
+	p.Errors = fields
+	return p, true
+}
+
+// Code — код gRPC, соответствующий HTTP-статусу
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httpStatus >= 500 {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// HTTPStatus — HTTP-статус, соответствующий коду gRPC
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
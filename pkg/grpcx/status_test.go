@@ -0,0 +1,47 @@
+package grpcx
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"pkg/httpx"
+)
+
+func TestProblemRoundTrip(t *testing.T) {
+	p := httpx.ValidationProblem([]httpx.FieldError{{Field: "text", Code: httpx.FieldTooLong, Message: "Text too long"}})
+
+	err := Error(p)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Ожидался код %s, получен %s", codes.InvalidArgument, status.Code(err))
+	}
+
+	got, ok := Problem(err)
+	if !ok {
+		t.Fatal("Описание ошибки не восстановлено из статуса gRPC")
+	}
+	if got.Status != http.StatusBadRequest || got.Code != httpx.CodeValidationFailed || got.Detail != p.Detail || got.Type != p.Type {
+		t.Errorf("Неверное описание ошибки: %+v", got)
+	}
+	if len(got.Errors) != 1 || got.Errors[0] != p.Errors[0] {
+		t.Errorf("Неверные ошибки полей: %+v", got.Errors)
+	}
+}
+
+func TestProblemKeepsHTTPStatus(t *testing.T) {
+	got, ok := Problem(Error(httpx.NewProblem(http.StatusMethodNotAllowed, httpx.CodeMethodNotAllowed, "Method not allowed")))
+	if !ok || got.Status != http.StatusMethodNotAllowed {
+		t.Errorf("HTTP-статус без точного кода gRPC должен сохраняться: %+v", got)
+	}
+}
+
+func TestProblemWithoutDetails(t *testing.T) {
+	for _, err := range []error{status.Error(codes.Unavailable, "connection refused"), errors.New("plain")} {
+		if p, ok := Problem(err); ok {
+			t.Errorf("%v: статус без ErrorInfo не должен давать описание ошибки: %+v", err, p)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+)
+
+// RequestIDHeader — заголовок, в котором передается идентификатор запроса
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID — возвращает идентификатор текущего запроса или пустую строку
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID — сохраняет идентификатор запроса в контексте
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDMiddleware — мидлвар для генерации/пропуска request ID;
+// идентификатор возвращается клиенту в заголовке X-Request-ID
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// NewRequestID — генерирует уникальный request ID
+func NewRequestID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// LoggerMiddleware — мидлвар, который кладет логгер в контекст запроса
+// и пишет по строке на каждый запрос с методом, путем, статусом, длительностью и request ID.
+// Должен подключаться после RequestIDMiddleware.
+func LoggerMiddleware(logger zerolog.Logger) func(http.Handler) http.Handler {
+	access := hlog.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
+		hlog.FromRequest(r).Info().
+			Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Int("status", status).
+			Int("size", size).
+			Dur("duration", duration).
+			Msg("request")
+	})
+	return func(next http.Handler) http.Handler {
+		withRequestID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := RequestID(r.Context()); id != "" {
+				zerolog.Ctx(r.Context()).UpdateContext(func(c zerolog.Context) zerolog.Context {
+					return c.Str("request_id", id)
+				})
+			}
+			access(next).ServeHTTP(w, r)
+		})
+		return hlog.NewHandler(logger)(withRequestID)
+	}
+}
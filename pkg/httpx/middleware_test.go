@@ -0,0 +1,64 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var got string
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = RequestID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if got != "req-42" || rr.Header().Get(RequestIDHeader) != "req-42" {
+		t.Errorf("Входящий request ID должен сохраняться: контекст %q, заголовок %q", got, rr.Header().Get(RequestIDHeader))
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got == "" || rr.Header().Get(RequestIDHeader) != got {
+		t.Errorf("Request ID должен генерироваться и возвращаться клиенту: контекст %q, заголовок %q", got, rr.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).With().Str("service", "test").Logger()
+	h := RequestIDMiddleware(LoggerMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/comments?x=1", nil)
+	req.Header.Set(RequestIDHeader, "req-7")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Ожидалась одна JSON-запись в логе: %v (%q)", err, buf.String())
+	}
+	want := map[string]interface{}{
+		"service":    "test",
+		"request_id": "req-7",
+		"method":     http.MethodPost,
+		"path":       "/comments",
+		"status":     float64(http.StatusTeapot),
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("Поле %s: ожидалось %v, получено %v", k, v, entry[k])
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Error("В записи лога нет длительности запроса")
+	}
+}
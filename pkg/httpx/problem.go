@@ -0,0 +1,104 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType — тип содержимого ответов с ошибкой (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Машиночитаемые коды ошибок
+const (
+	CodeInvalidBody         = "invalid_body"
+	CodeValidationFailed    = "validation_failed"
+	CodeNotFound            = "not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeForbiddenWords      = "forbidden_words"
+	CodeSpamDetected        = "spam_detected"
+	CodeInternal            = "internal_error"
+	CodeUpstreamError       = "upstream_error"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeUpstreamTimeout     = "upstream_timeout"
+	CodeIdempotencyMismatch = "idempotency_key_reused"
+	CodeRequestInProgress   = "request_in_progress"
+)
+
+// Коды ошибок отдельных полей
+const (
+	FieldRequired = "required"
+	FieldInvalid  = "invalid"
+	FieldTooLong  = "too_long"
+	FieldUnknown  = "unknown_value"
+	FieldNotFound = "not_found"
+)
+
+// FieldError — ошибка валидации конкретного поля или параметра
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem — описание ошибки в формате RFC 7807 с машиночитаемым кодом
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem — создает описание ошибки; type строится из кода
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// ValidationProblem — ошибка валидации с перечнем некорректных полей
+func ValidationProblem(fields []FieldError) *Problem {
+	p := NewProblem(http.StatusBadRequest, CodeValidationFailed, "Request validation failed")
+	p.Errors = fields
+	return p
+}
+
+// SendProblem — отправляет ошибку в формате application/problem+json,
+// дополняя ее путем запроса и request ID
+func SendProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	problem := *p
+	problem.Instance = r.URL.Path
+	problem.RequestID = RequestID(r.Context())
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// SendError — отправляет ошибку с кодом и описанием
+func SendError(w http.ResponseWriter, r *http.Request, statusCode int, code, detail string) {
+	SendProblem(w, r, NewProblem(statusCode, code, detail))
+}
+
+// SendValidationError — отправляет ошибку валидации полей
+func SendValidationError(w http.ResponseWriter, r *http.Request, fields ...FieldError) {
+	SendProblem(w, r, ValidationProblem(fields))
+}
+
+// NotFound — обработчик неизвестных маршрутов
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	SendError(w, r, http.StatusNotFound, CodeNotFound, "Route not found")
+}
+
+// MethodNotAllowed — обработчик неподдерживаемых методов
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	SendError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed")
+}
@@ -0,0 +1,33 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendValidationError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/news?sort=x", nil)
+	req = req.WithContext(WithRequestID(req.Context(), "req-1"))
+	rr := httptest.NewRecorder()
+
+	SendValidationError(rr, req, FieldError{Field: "sort", Code: FieldUnknown, Message: "Invalid sort"})
+
+	if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("Ожидалась ошибка %d в формате problem+json, получено %d %s", http.StatusBadRequest, rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var p Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != "/problems/validation_failed" || p.Title != "Bad Request" || p.Status != http.StatusBadRequest || p.Code != CodeValidationFailed {
+		t.Errorf("Неверное описание ошибки: %+v", p)
+	}
+	if p.Instance != "/news" || p.RequestID != "req-1" {
+		t.Errorf("Неверные instance/request_id: %+v", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "sort" || p.Errors[0].Code != FieldUnknown {
+		t.Errorf("Неверные ошибки полей: %+v", p.Errors)
+	}
+}
@@ -0,0 +1,19 @@
+package httpx
+
+import "time"
+
+// ParseDate — разбирает параметр запроса с датой в формате RFC3339 или YYYY-MM-DD. Для даты без времени
+// endOfDay сдвигает границу на конец дня, чтобы верхняя граница диапазона ("to") включала весь день.
+func ParseDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
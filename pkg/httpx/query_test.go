@@ -0,0 +1,31 @@
+package httpx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"2023-01-02", false, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2023-01-02", true, time.Date(2023, 1, 2, 23, 59, 59, int(time.Second-time.Nanosecond), time.UTC)},
+		{"2023-01-02T12:00:00Z", true, time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)},
+		{"2023-01-02T15:00:00+03:00", false, time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.value, tt.endOfDay)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("%q (endOfDay=%v): ожидалось %v, получено %v (%v)", tt.value, tt.endOfDay, tt.want, got, err)
+		}
+	}
+
+	for _, value := range []string{"", "01.01.2023", "yesterday", "2023-13-01"} {
+		if _, err := ParseDate(value, false); err == nil {
+			t.Errorf("%q: ожидалась ошибка", value)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package httpx — общие для всех сервисов HTTP-хелперы: конверт ответа,
+// ошибки в формате RFC 7807, request ID и логирование запросов.
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Response — универсальная структура успешного ответа
+type Response struct {
+	Status     string      `json:"status"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination — структура пагинации
+type Pagination struct {
+	Page      int `json:"page"`
+	PageSize  int `json:"page_size"`
+	Total     int `json:"total"`
+	PageCount int `json:"page_count"`
+}
+
+// NewPagination — пагинация страницы page размером pageSize из total элементов
+func NewPagination(page, pageSize, total int) *Pagination {
+	pageCount := 0
+	if pageSize > 0 {
+		pageCount = (total + pageSize - 1) / pageSize
+	}
+	return &Pagination{Page: page, PageSize: pageSize, Total: total, PageCount: pageCount}
+}
+
+// SendJSON — отправляет значение как JSON с указанным статусом
+func SendJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// SendResponse — отправляет успешный ответ в конверте Response
+func SendResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	SendJSON(w, statusCode, Response{Status: "success", Data: data})
+}
+
+// SendPage — отправляет страницу списка вместе с пагинацией
+func SendPage(w http.ResponseWriter, statusCode int, data interface{}, pagination *Pagination) {
+	SendJSON(w, statusCode, Response{Status: "success", Data: data, Pagination: pagination})
+}
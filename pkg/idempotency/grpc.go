@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+)
+
+// protoContentType — тип сохраненного ответа gRPC: сообщение, упакованное в Any
+const protoContentType = "application/x-protobuf"
+
+// UnaryServerInterceptor — идемпотентность вызовов перечисленных методов gRPC с метаданными idempotency-key.
+// Хеш строится по методу и запросу (или по хешу исходного запроса из HashMetadata); сохраняются и ответы,
+// и ошибки в формате httpx.Problem.
+func UnaryServerInterceptor(store Store, methods ...string) grpc.UnaryServerInterceptor {
+	idempotent := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		idempotent[m] = true
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		msg, ok := req.(proto.Message)
+		if !idempotent[info.FullMethod] || !ok {
+			return handler(ctx, req)
+		}
+		var key, upstream string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(Metadata); len(values) > 0 {
+				key = values[0]
+			}
+			if values := md.Get(HashMetadata); len(values) > 0 {
+				upstream = values[0]
+			}
+		}
+		if key == "" {
+			return handler(ctx, req)
+		}
+		if len(key) > MaxKeyLength {
+			return nil, grpcx.Error(httpx.ValidationProblem([]httpx.FieldError{{Field: Metadata, Code: httpx.FieldTooLong, Message: "Idempotency key is too long"}}))
+		}
+
+		payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		if upstream != "" {
+			payload = []byte(upstream)
+		}
+		hash := Hash([]byte(info.FullMethod), payload)
+		ctx = WithRequestHash(WithKey(ctx, key), hash)
+		var reply interface{}
+		resp, replayed, problem := Execute(ctx, store, key, hash, func() Response {
+			reply, err = handler(ctx, req)
+			return encodeGRPC(reply, err)
+		})
+		if problem != nil {
+			return nil, grpcx.Error(problem)
+		}
+		if !replayed {
+			return reply, err
+		}
+		return decodeGRPC(resp)
+	}
+}
+
+// UnaryClientInterceptor — передает ключ идемпотентности и хеш исходного запроса из контекста в метаданных вызова
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if key := Key(ctx); key != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, Metadata, key)
+	}
+	if hash := RequestHash(ctx); hash != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, HashMetadata, hash)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// encodeGRPC — ответ или ошибка вызова в виде Response; ошибки без httpx.Problem считаются сбоем и не сохраняются
+func encodeGRPC(reply interface{}, err error) Response {
+	if err != nil {
+		p, ok := grpcx.Problem(err)
+		if !ok {
+			p = httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, err.Error())
+		}
+		body, _ := json.Marshal(p)
+		return Response{Status: p.Status, ContentType: httpx.ProblemContentType, Body: body}
+	}
+	msg, ok := reply.(proto.Message)
+	if !ok {
+		return Response{Status: http.StatusInternalServerError}
+	}
+	packed, err := anypb.New(msg)
+	if err != nil {
+		return Response{Status: http.StatusInternalServerError}
+	}
+	body, err := proto.Marshal(packed)
+	if err != nil {
+		return Response{Status: http.StatusInternalServerError}
+	}
+	return Response{Status: http.StatusOK, ContentType: protoContentType, Body: body}
+}
+
+// decodeGRPC — восстанавливает ответ или ошибку вызова из Response
+func decodeGRPC(resp Response) (interface{}, error) {
+	if resp.ContentType != protoContentType {
+		var p httpx.Problem
+		if err := json.Unmarshal(resp.Body, &p); err != nil || p.Code == "" {
+			p = *httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Invalid stored response")
+		}
+		return nil, grpcx.Error(&p)
+	}
+	var packed anypb.Any
+	if err := proto.Unmarshal(resp.Body, &packed); err != nil {
+		return nil, err
+	}
+	return packed.UnmarshalNew()
+}
@@ -0,0 +1,92 @@
+package idempotency
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"pkg/grpcx"
+	"pkg/httpx"
+	"pkg/pb/censorpb"
+)
+
+// countingCensor — отклоняет текст "bad" и считает вызовы
+type countingCensor struct {
+	censorpb.UnimplementedCensorServiceServer
+	calls int
+}
+
+func (s *countingCensor) CheckText(_ context.Context, req *censorpb.CheckTextRequest) (*censorpb.CheckTextResponse, error) {
+	s.calls++
+	if req.GetText() == "bad" {
+		return nil, grpcx.Error(httpx.NewProblem(http.StatusBadRequest, httpx.CodeForbiddenWords, "Text contains forbidden words"))
+	}
+	return &censorpb.CheckTextResponse{}, nil
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	censor := &countingCensor{}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpcx.NewServer(zerolog.Nop(), grpc.ChainUnaryInterceptor(
+		UnaryServerInterceptor(NewMemoryStore(time.Hour), censorpb.CensorService_CheckText_FullMethodName),
+	))
+	censorpb.RegisterCensorServiceServer(srv, censor)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := censorpb.NewCensorServiceClient(conn)
+	ctx := WithKey(context.Background(), "key-1")
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CheckText(ctx, &censorpb.CheckTextRequest{Text: "ok"}); err != nil {
+			t.Fatalf("Вызов %d: %v", i+1, err)
+		}
+	}
+	if censor.calls != 1 {
+		t.Errorf("Повтор с тем же ключом не должен вызывать сервис, вызовов: %d", censor.calls)
+	}
+
+	_, err = client.CheckText(ctx, &censorpb.CheckTextRequest{Text: "other"})
+	if p, ok := grpcx.Problem(err); !ok || p.Code != httpx.CodeIdempotencyMismatch || p.Status != http.StatusUnprocessableEntity {
+		t.Errorf("Ожидалась ошибка %s, получено %v", httpx.CodeIdempotencyMismatch, err)
+	}
+
+	// С хешем исходного запроса повтор сравнивается по нему, а не по сообщению
+	ctx = WithRequestHash(WithKey(context.Background(), "key-3"), "client-hash")
+	for _, text := range []string{"first", "second"} {
+		if _, err := client.CheckText(ctx, &censorpb.CheckTextRequest{Text: text}); err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+	}
+	if censor.calls != 2 {
+		t.Errorf("Повтор с тем же хешем исходного запроса не должен вызывать сервис, всего вызовов: %d", censor.calls)
+	}
+
+	// Ошибка сохраняется и воспроизводится с исходным кодом
+	ctx = WithKey(context.Background(), "key-2")
+	for i := 0; i < 2; i++ {
+		_, err = client.CheckText(ctx, &censorpb.CheckTextRequest{Text: "bad"})
+		if p, ok := grpcx.Problem(err); !ok || p.Code != httpx.CodeForbiddenWords {
+			t.Errorf("Вызов %d: ожидалась ошибка %s, получено %v", i+1, httpx.CodeForbiddenWords, err)
+		}
+	}
+	if censor.calls != 3 {
+		t.Errorf("Ожидалось 3 вызова сервиса, получено %d", censor.calls)
+	}
+}
@@ -0,0 +1,202 @@
+// Package idempotency — ключи идемпотентности для повторяемых запросов на создание:
+// повтор запроса с тем же заголовком Idempotency-Key получает сохраненный ответ,
+// а повтор с другим телом отклоняется с 422.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"pkg/httpx"
+)
+
+// Заголовки идемпотентных запросов
+const (
+	Header         = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+	// HashHeader — хеш исходного запроса клиента, который передается внутренним сервисам вместе с ключом.
+	// Тело запроса к внутреннему сервису зависит не только от клиента (статус комментария — от оценки спама),
+	// поэтому повторы сравниваются по исходному запросу. Заголовок передают только доверенные сервисы.
+	HashHeader = "Idempotency-Request-Hash"
+)
+
+// Ключи метаданных gRPC, в которых передаются ключ идемпотентности и хеш исходного запроса
+const (
+	Metadata     = "idempotency-key"
+	HashMetadata = "idempotency-request-hash"
+)
+
+// MaxKeyLength — максимальная длина ключа
+const MaxKeyLength = 255
+
+// DefaultTTL — сколько хранится ответ на запрос с ключом
+const DefaultTTL = 24 * time.Hour
+
+// Response — сохраненный ответ на запрос
+type Response struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Record — запись о запросе с ключом; Done — запрос завершен и Response заполнен
+type Record struct {
+	Hash     string
+	Done     bool
+	Response Response
+}
+
+// Store — хранилище ключей идемпотентности
+type Store interface {
+	// Reserve — занимает ключ для выполнения запроса с хешем hash и возвращает nil;
+	// если ключ уже занят, возвращает его запись
+	Reserve(ctx context.Context, key, hash string) (*Record, error)
+	// Complete — сохраняет ответ на запрос с занятым ключом
+	Complete(ctx context.Context, key string, resp Response) error
+	// Release — освобождает ключ запроса, ответ на который не сохраняется
+	Release(ctx context.Context, key string) error
+}
+
+type (
+	keyContextKey  struct{}
+	hashContextKey struct{}
+)
+
+// WithKey — сохраняет ключ идемпотентности в контексте для передачи во внутренние сервисы
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// Key — ключ идемпотентности текущего запроса или пустая строка
+func Key(ctx context.Context) string {
+	key, _ := ctx.Value(keyContextKey{}).(string)
+	return key
+}
+
+// WithRequestHash — сохраняет в контексте хеш запроса с ключом для передачи во внутренние сервисы
+func WithRequestHash(ctx context.Context, hash string) context.Context {
+	return context.WithValue(ctx, hashContextKey{}, hash)
+}
+
+// RequestHash — хеш текущего запроса с ключом или пустая строка
+func RequestHash(ctx context.Context) string {
+	hash, _ := ctx.Value(hashContextKey{}).(string)
+	return hash
+}
+
+// Hash — хеш запроса, с которым сравниваются повторы
+func Hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Execute — выполняет fn не более одного раза для ключа. Повтор с тем же хешем получает
+// сохраненный ответ (replayed = true), с другим хешем — ошибку 422, а пока первый запрос
+// выполняется — 409. Ответы со статусом 5xx не сохраняются, чтобы запрос можно было повторить.
+func Execute(ctx context.Context, store Store, key, hash string, fn func() Response) (resp Response, replayed bool, problem *httpx.Problem) {
+	rec, err := store.Reserve(ctx, key, hash)
+	if err != nil {
+		return Response{}, false, httpx.NewProblem(http.StatusInternalServerError, httpx.CodeInternal, "Idempotency store error")
+	}
+	if rec != nil {
+		switch {
+		case rec.Hash != hash:
+			return Response{}, false, httpx.NewProblem(http.StatusUnprocessableEntity, httpx.CodeIdempotencyMismatch, "Idempotency-Key was already used with a different request")
+		case !rec.Done:
+			return Response{}, false, httpx.NewProblem(http.StatusConflict, httpx.CodeRequestInProgress, "A request with this Idempotency-Key is still in progress")
+		}
+		return rec.Response, true, nil
+	}
+
+	// Ключ освобождается и при панике в fn, иначе повторы получали бы 409 до истечения срока
+	saved := false
+	defer func() {
+		if !saved {
+			store.Release(context.WithoutCancel(ctx), key)
+		}
+	}()
+	resp = fn()
+	if resp.Status < http.StatusInternalServerError {
+		saved = store.Complete(context.WithoutCancel(ctx), key, resp) == nil
+	}
+	return resp, false, nil
+}
+
+// Middleware — обрабатывает запросы с заголовком Idempotency-Key через Execute;
+// запросы без заголовка проходят без изменений. Если передан HashHeader, повторы сравниваются по нему,
+// а не по телу запроса.
+func Middleware(store Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(Header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > MaxKeyLength {
+				httpx.SendValidationError(w, r, httpx.FieldError{Field: Header, Code: httpx.FieldTooLong, Message: "Idempotency-Key is too long"})
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				httpx.SendError(w, r, http.StatusBadRequest, httpx.CodeInvalidBody, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := Hash([]byte(r.Method), []byte(r.URL.Path), body)
+			if upstream := r.Header.Get(HashHeader); upstream != "" {
+				hash = Hash([]byte(r.Method), []byte(r.URL.Path), []byte(upstream))
+			}
+			ctx := WithRequestHash(WithKey(r.Context(), key), hash)
+			rec := &recorder{header: w.Header(), status: http.StatusOK}
+			resp, replayed, problem := Execute(ctx, store, key, hash, func() Response {
+				next.ServeHTTP(rec, r.WithContext(ctx))
+				return Response{Status: rec.status, ContentType: rec.header.Get("Content-Type"), Body: rec.body.Bytes()}
+			})
+			if problem != nil {
+				httpx.SendProblem(w, r, problem)
+				return
+			}
+			if replayed {
+				w.Header().Set(ReplayedHeader, "true")
+			}
+			if resp.ContentType != "" {
+				w.Header().Set("Content-Type", resp.ContentType)
+			}
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+		})
+	}
+}
+
+// recorder — запоминает ответ обработчика; заголовки пишутся сразу в исходный ответ
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(p)
+}
@@ -0,0 +1,148 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pkg/httpx"
+)
+
+func newTestHandler(calls *atomic.Int32) http.Handler {
+	return Middleware(NewMemoryStore(time.Hour))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if Key(r.Context()) == "" {
+			http.Error(w, "ключ не передан в контекст", http.StatusInternalServerError)
+			return
+		}
+		httpx.SendResponse(w, http.StatusCreated, map[string]int32{"id": n})
+	}))
+}
+
+func post(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMiddlewareReplaysResponse(t *testing.T) {
+	var calls atomic.Int32
+	h := newTestHandler(&calls)
+
+	first := post(h, "key-1", `{"text":"a"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(ReplayedHeader) != "" {
+		t.Fatalf("Неверный первый ответ: %d %v", first.Code, first.Header())
+	}
+	retry := post(h, "key-1", `{"text":"a"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("Повтор должен получить исходный ответ: %d %s %v", retry.Code, retry.Body.String(), retry.Header())
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Обработчик должен выполниться один раз, выполнен %d", calls.Load())
+	}
+
+	// Без ключа и с другим ключом запрос выполняется заново
+	post(h, "", `{"text":"a"}`)
+	post(h, "key-2", `{"text":"a"}`)
+	if calls.Load() != 3 {
+		t.Errorf("Ожидалось 3 выполнения, получено %d", calls.Load())
+	}
+}
+
+func TestMiddlewareRejectsDifferentBody(t *testing.T) {
+	var calls atomic.Int32
+	h := newTestHandler(&calls)
+
+	post(h, "key-1", `{"text":"a"}`)
+	rr := post(h, "key-1", `{"text":"b"}`)
+	var p httpx.Problem
+	json.Unmarshal(rr.Body.Bytes(), &p)
+	if rr.Code != http.StatusUnprocessableEntity || p.Code != httpx.CodeIdempotencyMismatch {
+		t.Errorf("Ожидалась ошибка %d %s, получено %d %s", http.StatusUnprocessableEntity, httpx.CodeIdempotencyMismatch, rr.Code, rr.Body.String())
+	}
+
+	rr = post(h, strings.Repeat("k", MaxKeyLength+1), `{}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Слишком длинный ключ должен отклоняться, получен статус %d", rr.Code)
+	}
+}
+
+func TestMiddlewareUpstreamHash(t *testing.T) {
+	var calls atomic.Int32
+	h := newTestHandler(&calls)
+	postHash := func(body, hash string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body))
+		req.Header.Set(Header, "key-1")
+		req.Header.Set(HashHeader, hash)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Шлюз повторяет тот же запрос клиента, но тело для внутреннего сервиса изменилось (другой статус)
+	first := postHash(`{"text":"a","status":"published"}`, "client-hash")
+	retry := postHash(`{"text":"a","status":"pending"}`, "client-hash")
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || calls.Load() != 1 {
+		t.Errorf("Повтор с тем же хешем клиента должен получить исходный ответ: %d %s", retry.Code, retry.Body.String())
+	}
+	if rr := postHash(`{"text":"a","status":"published"}`, "other-hash"); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Другой запрос клиента с тем же ключом должен отклоняться, получен статус %d", rr.Code)
+	}
+}
+
+func TestExecuteInProgressAndFailures(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go Execute(ctx, store, "key", "hash", func() Response {
+		close(started)
+		<-release
+		return Response{Status: http.StatusOK}
+	})
+	<-started
+	_, _, problem := Execute(ctx, store, "key", "hash", func() Response { return Response{Status: http.StatusOK} })
+	if problem == nil || problem.Status != http.StatusConflict {
+		t.Errorf("Пока запрос выполняется, повтор должен получать 409, получено %v", problem)
+	}
+	close(release)
+
+	// Ответ 5xx не сохраняется: повтор выполняется заново
+	Execute(ctx, store, "failed", "hash", func() Response { return Response{Status: http.StatusBadGateway} })
+	var retried bool
+	Execute(ctx, store, "failed", "hash", func() Response {
+		retried = true
+		return Response{Status: http.StatusOK}
+	})
+	if !retried {
+		t.Error("После ответа 5xx повтор должен выполняться заново")
+	}
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	store.Reserve(ctx, "key", "hash")
+	store.Complete(ctx, "key", Response{Status: http.StatusOK})
+	if rec, _ := store.Reserve(ctx, "key", "hash"); rec == nil || !rec.Done {
+		t.Fatalf("Ожидалась сохраненная запись, получено %+v", rec)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if rec, _ := store.Reserve(ctx, "key", "other"); rec != nil {
+		t.Errorf("Истекший ключ должен заниматься заново, получено %+v", rec)
+	}
+}
@@ -0,0 +1,68 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval — как часто MemoryStore удаляет истекшие ключи
+const sweepInterval = time.Minute
+
+// MemoryStore — хранилище ключей в памяти процесса
+type MemoryStore struct {
+	ttl       time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	record  Record
+	expires time.Time
+}
+
+// NewMemoryStore — хранилище, в котором ответы хранятся ttl
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{ttl: ttl, now: time.Now, entries: make(map[string]*memoryEntry)}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, key, hash string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if e, ok := s.entries[key]; ok && !now.After(e.expires) {
+		rec := e.record
+		return &rec, nil
+	}
+	s.entries[key] = &memoryEntry{record: Record{Hash: hash}, expires: now.Add(s.ttl)}
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, resp Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.record.Done = true
+		e.record.Response = resp
+		e.expires = s.now().Add(s.ttl)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,379 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.28.3
+// source: pb/censorpb/censor.proto
+
+package censorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CheckTextRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// author — для учета частоты комментариев автора
+	Author string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	// language — язык текста; пустой определяется по тексту
+	Language string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	// policy — политика раздела или сайта-партнера; пустая — политика по умолчанию
+	Policy        string `protobuf:"bytes,4,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckTextRequest) Reset() {
+	*x = CheckTextRequest{}
+	mi := &file_pb_censorpb_censor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckTextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckTextRequest) ProtoMessage() {}
+
+func (x *CheckTextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_censorpb_censor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckTextRequest.ProtoReflect.Descriptor instead.
+func (*CheckTextRequest) Descriptor() ([]byte, []int) {
+	return file_pb_censorpb_censor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckTextRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CheckTextRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *CheckTextRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CheckTextRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// CheckTextResponse — решение accept, queue или reject и оценка спама от 0 до 1
+type CheckTextResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Verdict   string                 `protobuf:"bytes,1,opt,name=verdict,proto3" json:"verdict,omitempty"`
+	SpamScore float64                `protobuf:"fixed64,2,opt,name=spam_score,json=spamScore,proto3" json:"spam_score,omitempty"`
+	Signals   []*SpamSignal          `protobuf:"bytes,3,rep,name=signals,proto3" json:"signals,omitempty"`
+	// mask — фрагменты, совпавшие с правилами словаря с действием mask; их нужно скрыть перед публикацией
+	Mask []*TextSpan `protobuf:"bytes,4,rep,name=mask,proto3" json:"mask,omitempty"`
+	// language — язык, по которому выбраны правила словаря
+	Language string `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`
+	// policy — политика, по которой принято решение
+	Policy        string `protobuf:"bytes,6,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckTextResponse) Reset() {
+	*x = CheckTextResponse{}
+	mi := &file_pb_censorpb_censor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckTextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckTextResponse) ProtoMessage() {}
+
+func (x *CheckTextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_censorpb_censor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckTextResponse.ProtoReflect.Descriptor instead.
+func (*CheckTextResponse) Descriptor() ([]byte, []int) {
+	return file_pb_censorpb_censor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckTextResponse) GetVerdict() string {
+	if x != nil {
+		return x.Verdict
+	}
+	return ""
+}
+
+func (x *CheckTextResponse) GetSpamScore() float64 {
+	if x != nil {
+		return x.SpamScore
+	}
+	return 0
+}
+
+func (x *CheckTextResponse) GetSignals() []*SpamSignal {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *CheckTextResponse) GetMask() []*TextSpan {
+	if x != nil {
+		return x.Mask
+	}
+	return nil
+}
+
+func (x *CheckTextResponse) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CheckTextResponse) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// TextSpan — фрагмент текста [start, end) в байтах UTF-8
+type TextSpan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TextSpan) Reset() {
+	*x = TextSpan{}
+	mi := &file_pb_censorpb_censor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TextSpan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextSpan) ProtoMessage() {}
+
+func (x *TextSpan) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_censorpb_censor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextSpan.ProtoReflect.Descriptor instead.
+func (*TextSpan) Descriptor() ([]byte, []int) {
+	return file_pb_censorpb_censor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TextSpan) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *TextSpan) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// SpamSignal — вклад одного правила в оценку спама
+type SpamSignal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Detail        string                 `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpamSignal) Reset() {
+	*x = SpamSignal{}
+	mi := &file_pb_censorpb_censor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpamSignal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpamSignal) ProtoMessage() {}
+
+func (x *SpamSignal) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_censorpb_censor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpamSignal.ProtoReflect.Descriptor instead.
+func (*SpamSignal) Descriptor() ([]byte, []int) {
+	return file_pb_censorpb_censor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SpamSignal) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *SpamSignal) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SpamSignal) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+var File_pb_censorpb_censor_proto protoreflect.FileDescriptor
+
+const file_pb_censorpb_censor_proto_rawDesc = "" +
+	"\n" +
+	"\x18pb/censorpb/censor.proto\x12\tcensor.v1\"r\n" +
+	"\x10CheckTextRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x16\n" +
+	"\x06author\x18\x02 \x01(\tR\x06author\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12\x16\n" +
+	"\x06policy\x18\x04 \x01(\tR\x06policy\"\xda\x01\n" +
+	"\x11CheckTextResponse\x12\x18\n" +
+	"\averdict\x18\x01 \x01(\tR\averdict\x12\x1d\n" +
+	"\n" +
+	"spam_score\x18\x02 \x01(\x01R\tspamScore\x12/\n" +
+	"\asignals\x18\x03 \x03(\v2\x15.censor.v1.SpamSignalR\asignals\x12'\n" +
+	"\x04mask\x18\x04 \x03(\v2\x13.censor.v1.TextSpanR\x04mask\x12\x1a\n" +
+	"\blanguage\x18\x05 \x01(\tR\blanguage\x12\x16\n" +
+	"\x06policy\x18\x06 \x01(\tR\x06policy\"2\n" +
+	"\bTextSpan\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\"N\n" +
+	"\n" +
+	"SpamSignal\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail2W\n" +
+	"\rCensorService\x12F\n" +
+	"\tCheckText\x12\x1b.censor.v1.CheckTextRequest\x1a\x1c.censor.v1.CheckTextResponseB\x11Z\x0fpkg/pb/censorpbb\x06proto3"
+
+var (
+	file_pb_censorpb_censor_proto_rawDescOnce sync.Once
+	file_pb_censorpb_censor_proto_rawDescData []byte
+)
+
+func file_pb_censorpb_censor_proto_rawDescGZIP() []byte {
+	file_pb_censorpb_censor_proto_rawDescOnce.Do(func() {
+		file_pb_censorpb_censor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pb_censorpb_censor_proto_rawDesc), len(file_pb_censorpb_censor_proto_rawDesc)))
+	})
+	return file_pb_censorpb_censor_proto_rawDescData
+}
+
+var file_pb_censorpb_censor_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_pb_censorpb_censor_proto_goTypes = []any{
+	(*CheckTextRequest)(nil),  // 0: censor.v1.CheckTextRequest
+	(*CheckTextResponse)(nil), // 1: censor.v1.CheckTextResponse
+	(*TextSpan)(nil),          // 2: censor.v1.TextSpan
+	(*SpamSignal)(nil),        // 3: censor.v1.SpamSignal
+}
+var file_pb_censorpb_censor_proto_depIdxs = []int32{
+	3, // 0: censor.v1.CheckTextResponse.signals:type_name -> censor.v1.SpamSignal
+	2, // 1: censor.v1.CheckTextResponse.mask:type_name -> censor.v1.TextSpan
+	0, // 2: censor.v1.CensorService.CheckText:input_type -> censor.v1.CheckTextRequest
+	1, // 3: censor.v1.CensorService.CheckText:output_type -> censor.v1.CheckTextResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_pb_censorpb_censor_proto_init() }
+func file_pb_censorpb_censor_proto_init() {
+	if File_pb_censorpb_censor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pb_censorpb_censor_proto_rawDesc), len(file_pb_censorpb_censor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pb_censorpb_censor_proto_goTypes,
+		DependencyIndexes: file_pb_censorpb_censor_proto_depIdxs,
+		MessageInfos:      file_pb_censorpb_censor_proto_msgTypes,
+	}.Build()
+	File_pb_censorpb_censor_proto = out.File
+	file_pb_censorpb_censor_proto_goTypes = nil
+	file_pb_censorpb_censor_proto_depIdxs = nil
+}
@@ -0,0 +1,129 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: pb/censorpb/censor.proto
+
+package censorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CensorService_CheckText_FullMethodName = "/censor.v1.CensorService/CheckText"
+)
+
+// CensorServiceClient is the client API for CensorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CensorService — внутренний API Censor Service
+type CensorServiceClient interface {
+	// CheckText — проверяет текст на запрещенные слова; при их наличии
+	// возвращает InvalidArgument с причиной forbidden_words, иначе — оценку спама
+	CheckText(ctx context.Context, in *CheckTextRequest, opts ...grpc.CallOption) (*CheckTextResponse, error)
+}
+
+type censorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCensorServiceClient(cc grpc.ClientConnInterface) CensorServiceClient {
+	return &censorServiceClient{cc}
+}
+
+func (c *censorServiceClient) CheckText(ctx context.Context, in *CheckTextRequest, opts ...grpc.CallOption) (*CheckTextResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckTextResponse)
+	err := c.cc.Invoke(ctx, CensorService_CheckText_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CensorServiceServer is the server API for CensorService service.
+// All implementations must embed UnimplementedCensorServiceServer
+// for forward compatibility.
+//
+// CensorService — внутренний API Censor Service
+type CensorServiceServer interface {
+	// CheckText — проверяет текст на запрещенные слова; при их наличии
+	// возвращает InvalidArgument с причиной forbidden_words, иначе — оценку спама
+	CheckText(context.Context, *CheckTextRequest) (*CheckTextResponse, error)
+	mustEmbedUnimplementedCensorServiceServer()
+}
+
+// UnimplementedCensorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCensorServiceServer struct{}
+
+func (UnimplementedCensorServiceServer) CheckText(context.Context, *CheckTextRequest) (*CheckTextResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckText not implemented")
+}
+func (UnimplementedCensorServiceServer) mustEmbedUnimplementedCensorServiceServer() {}
+func (UnimplementedCensorServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeCensorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CensorServiceServer will
+// result in compilation errors.
+type UnsafeCensorServiceServer interface {
+	mustEmbedUnimplementedCensorServiceServer()
+}
+
+func RegisterCensorServiceServer(s grpc.ServiceRegistrar, srv CensorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCensorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CensorService_ServiceDesc, srv)
+}
+
+func _CensorService_CheckText_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckTextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CensorServiceServer).CheckText(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CensorService_CheckText_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CensorServiceServer).CheckText(ctx, req.(*CheckTextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CensorService_ServiceDesc is the grpc.ServiceDesc for CensorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CensorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "censor.v1.CensorService",
+	HandlerType: (*CensorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckText",
+			Handler:    _CensorService_CheckText_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/censorpb/censor.proto",
+}
@@ -0,0 +1,722 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.28.3
+// source: pb/commentpb/comment.proto
+
+package commentpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Comment struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	NewsId    int64                  `protobuf:"varint,2,opt,name=news_id,json=newsId,proto3" json:"news_id,omitempty"`
+	ParentId  *int64                 `protobuf:"varint,3,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Author    string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Text      string                 `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// status — published или pending (ожидает модерации)
+	Status string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	// original_text — текст до маскировки запрещенных слов; только для модераторов,
+	// в списках комментариев к новостям не возвращается
+	OriginalText string `protobuf:"bytes,8,opt,name=original_text,json=originalText,proto3" json:"original_text,omitempty"`
+	// html — текст, размеченный безопасным подмножеством Markdown
+	Html string `protobuf:"bytes,9,opt,name=html,proto3" json:"html,omitempty"`
+	// previous_status — статус до изменения; только в ответе SetCommentStatus
+	PreviousStatus string `protobuf:"bytes,10,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Comment) Reset() {
+	*x = Comment{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comment) ProtoMessage() {}
+
+func (x *Comment) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comment.ProtoReflect.Descriptor instead.
+func (*Comment) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Comment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Comment) GetNewsId() int64 {
+	if x != nil {
+		return x.NewsId
+	}
+	return 0
+}
+
+func (x *Comment) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *Comment) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Comment) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Comment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Comment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Comment) GetOriginalText() string {
+	if x != nil {
+		return x.OriginalText
+	}
+	return ""
+}
+
+func (x *Comment) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *Comment) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
+}
+
+type CreateCommentRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	NewsId   int64                  `protobuf:"varint,1,opt,name=news_id,json=newsId,proto3" json:"news_id,omitempty"`
+	ParentId *int64                 `protobuf:"varint,2,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Author   string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Text     string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	// status — published (по умолчанию) или pending
+	Status string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	// original_text — исходный текст, если text замаскирован
+	OriginalText string `protobuf:"bytes,6,opt,name=original_text,json=originalText,proto3" json:"original_text,omitempty"`
+	// language и policy — язык и политика, по которым Censor Service проверил текст;
+	// сохраняются для повторной проверки и не возвращаются в Comment
+	Language      string `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	Policy        string `protobuf:"bytes,8,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCommentRequest) Reset() {
+	*x = CreateCommentRequest{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCommentRequest) ProtoMessage() {}
+
+func (x *CreateCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCommentRequest.ProtoReflect.Descriptor instead.
+func (*CreateCommentRequest) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateCommentRequest) GetNewsId() int64 {
+	if x != nil {
+		return x.NewsId
+	}
+	return 0
+}
+
+func (x *CreateCommentRequest) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *CreateCommentRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *CreateCommentRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CreateCommentRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateCommentRequest) GetOriginalText() string {
+	if x != nil {
+		return x.OriginalText
+	}
+	return ""
+}
+
+func (x *CreateCommentRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CreateCommentRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+type ListCommentsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	NewsId int64                  `protobuf:"varint,1,opt,name=news_id,json=newsId,proto3" json:"news_id,omitempty"`
+	// news_ids — дополнительные новости, комментарии к которым нужны в том же ответе
+	NewsIds       []int64 `protobuf:"varint,2,rep,packed,name=news_ids,json=newsIds,proto3" json:"news_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommentsRequest) Reset() {
+	*x = ListCommentsRequest{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommentsRequest) ProtoMessage() {}
+
+func (x *ListCommentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCommentsRequest.ProtoReflect.Descriptor instead.
+func (*ListCommentsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListCommentsRequest) GetNewsId() int64 {
+	if x != nil {
+		return x.NewsId
+	}
+	return 0
+}
+
+func (x *ListCommentsRequest) GetNewsIds() []int64 {
+	if x != nil {
+		return x.NewsIds
+	}
+	return nil
+}
+
+type CountCommentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NewsIds       []int64                `protobuf:"varint,1,rep,packed,name=news_ids,json=newsIds,proto3" json:"news_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountCommentsRequest) Reset() {
+	*x = CountCommentsRequest{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountCommentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountCommentsRequest) ProtoMessage() {}
+
+func (x *CountCommentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountCommentsRequest.ProtoReflect.Descriptor instead.
+func (*CountCommentsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CountCommentsRequest) GetNewsIds() []int64 {
+	if x != nil {
+		return x.NewsIds
+	}
+	return nil
+}
+
+type CountCommentsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// counts — число комментариев по ID новости; новости без комментариев получают 0
+	Counts        map[int64]int32 `protobuf:"bytes,1,rep,name=counts,proto3" json:"counts,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountCommentsResponse) Reset() {
+	*x = CountCommentsResponse{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountCommentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountCommentsResponse) ProtoMessage() {}
+
+func (x *CountCommentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountCommentsResponse.ProtoReflect.Descriptor instead.
+func (*CountCommentsResponse) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CountCommentsResponse) GetCounts() map[int64]int32 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+type SearchCommentsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Q      string                 `protobuf:"bytes,1,opt,name=q,proto3" json:"q,omitempty"`
+	NewsId int64                  `protobuf:"varint,2,opt,name=news_id,json=newsId,proto3" json:"news_id,omitempty"`
+	Author string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	// from и to — RFC3339 или YYYY-MM-DD
+	From          string `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To            string `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Page          int32  `protobuf:"varint,6,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Status        string `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchCommentsRequest) Reset() {
+	*x = SearchCommentsRequest{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchCommentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchCommentsRequest) ProtoMessage() {}
+
+func (x *SearchCommentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchCommentsRequest.ProtoReflect.Descriptor instead.
+func (*SearchCommentsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchCommentsRequest) GetQ() string {
+	if x != nil {
+		return x.Q
+	}
+	return ""
+}
+
+func (x *SearchCommentsRequest) GetNewsId() int64 {
+	if x != nil {
+		return x.NewsId
+	}
+	return 0
+}
+
+func (x *SearchCommentsRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *SearchCommentsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *SearchCommentsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *SearchCommentsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchCommentsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchCommentsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type SetCommentStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetCommentStatusRequest) Reset() {
+	*x = SetCommentStatusRequest{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetCommentStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCommentStatusRequest) ProtoMessage() {}
+
+func (x *SetCommentStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCommentStatusRequest.ProtoReflect.Descriptor instead.
+func (*SetCommentStatusRequest) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetCommentStatusRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SetCommentStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListCommentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comments      []*Comment             `protobuf:"bytes,1,rep,name=comments,proto3" json:"comments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommentsResponse) Reset() {
+	*x = ListCommentsResponse{}
+	mi := &file_pb_commentpb_comment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommentsResponse) ProtoMessage() {}
+
+func (x *ListCommentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_commentpb_comment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCommentsResponse.ProtoReflect.Descriptor instead.
+func (*ListCommentsResponse) Descriptor() ([]byte, []int) {
+	return file_pb_commentpb_comment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListCommentsResponse) GetComments() []*Comment {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+var File_pb_commentpb_comment_proto protoreflect.FileDescriptor
+
+const file_pb_commentpb_comment_proto_rawDesc = "" +
+	"\n" +
+	"\x1apb/commentpb/comment.proto\x12\n" +
+	"comment.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc3\x02\n" +
+	"\aComment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\anews_id\x18\x02 \x01(\x03R\x06newsId\x12 \n" +
+	"\tparent_id\x18\x03 \x01(\x03H\x00R\bparentId\x88\x01\x01\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12#\n" +
+	"\roriginal_text\x18\b \x01(\tR\foriginalText\x12\x12\n" +
+	"\x04html\x18\t \x01(\tR\x04html\x12'\n" +
+	"\x0fprevious_status\x18\n" +
+	" \x01(\tR\x0epreviousStatusB\f\n" +
+	"\n" +
+	"_parent_id\"\xfc\x01\n" +
+	"\x14CreateCommentRequest\x12\x17\n" +
+	"\anews_id\x18\x01 \x01(\x03R\x06newsId\x12 \n" +
+	"\tparent_id\x18\x02 \x01(\x03H\x00R\bparentId\x88\x01\x01\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x12\n" +
+	"\x04text\x18\x04 \x01(\tR\x04text\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12#\n" +
+	"\roriginal_text\x18\x06 \x01(\tR\foriginalText\x12\x1a\n" +
+	"\blanguage\x18\a \x01(\tR\blanguage\x12\x16\n" +
+	"\x06policy\x18\b \x01(\tR\x06policyB\f\n" +
+	"\n" +
+	"_parent_id\"I\n" +
+	"\x13ListCommentsRequest\x12\x17\n" +
+	"\anews_id\x18\x01 \x01(\x03R\x06newsId\x12\x19\n" +
+	"\bnews_ids\x18\x02 \x03(\x03R\anewsIds\"1\n" +
+	"\x14CountCommentsRequest\x12\x19\n" +
+	"\bnews_ids\x18\x01 \x03(\x03R\anewsIds\"\x99\x01\n" +
+	"\x15CountCommentsResponse\x12E\n" +
+	"\x06counts\x18\x01 \x03(\v2-.comment.v1.CountCommentsResponse.CountsEntryR\x06counts\x1a9\n" +
+	"\vCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x03R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xc3\x01\n" +
+	"\x15SearchCommentsRequest\x12\f\n" +
+	"\x01q\x18\x01 \x01(\tR\x01q\x12\x17\n" +
+	"\anews_id\x18\x02 \x01(\x03R\x06newsId\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12\x12\n" +
+	"\x04page\x18\x06 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\"A\n" +
+	"\x17SetCommentStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"G\n" +
+	"\x14ListCommentsResponse\x12/\n" +
+	"\bcomments\x18\x01 \x03(\v2\x13.comment.v1.CommentR\bcomments2\xa6\x03\n" +
+	"\x0eCommentService\x12F\n" +
+	"\rCreateComment\x12 .comment.v1.CreateCommentRequest\x1a\x13.comment.v1.Comment\x12Q\n" +
+	"\fListComments\x12\x1f.comment.v1.ListCommentsRequest\x1a .comment.v1.ListCommentsResponse\x12T\n" +
+	"\rCountComments\x12 .comment.v1.CountCommentsRequest\x1a!.comment.v1.CountCommentsResponse\x12U\n" +
+	"\x0eSearchComments\x12!.comment.v1.SearchCommentsRequest\x1a .comment.v1.ListCommentsResponse\x12L\n" +
+	"\x10SetCommentStatus\x12#.comment.v1.SetCommentStatusRequest\x1a\x13.comment.v1.CommentB\x12Z\x10pkg/pb/commentpbb\x06proto3"
+
+var (
+	file_pb_commentpb_comment_proto_rawDescOnce sync.Once
+	file_pb_commentpb_comment_proto_rawDescData []byte
+)
+
+func file_pb_commentpb_comment_proto_rawDescGZIP() []byte {
+	file_pb_commentpb_comment_proto_rawDescOnce.Do(func() {
+		file_pb_commentpb_comment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pb_commentpb_comment_proto_rawDesc), len(file_pb_commentpb_comment_proto_rawDesc)))
+	})
+	return file_pb_commentpb_comment_proto_rawDescData
+}
+
+var file_pb_commentpb_comment_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_pb_commentpb_comment_proto_goTypes = []any{
+	(*Comment)(nil),                 // 0: comment.v1.Comment
+	(*CreateCommentRequest)(nil),    // 1: comment.v1.CreateCommentRequest
+	(*ListCommentsRequest)(nil),     // 2: comment.v1.ListCommentsRequest
+	(*CountCommentsRequest)(nil),    // 3: comment.v1.CountCommentsRequest
+	(*CountCommentsResponse)(nil),   // 4: comment.v1.CountCommentsResponse
+	(*SearchCommentsRequest)(nil),   // 5: comment.v1.SearchCommentsRequest
+	(*SetCommentStatusRequest)(nil), // 6: comment.v1.SetCommentStatusRequest
+	(*ListCommentsResponse)(nil),    // 7: comment.v1.ListCommentsResponse
+	nil,                             // 8: comment.v1.CountCommentsResponse.CountsEntry
+	(*timestamppb.Timestamp)(nil),   // 9: google.protobuf.Timestamp
+}
+var file_pb_commentpb_comment_proto_depIdxs = []int32{
+	9, // 0: comment.v1.Comment.created_at:type_name -> google.protobuf.Timestamp
+	8, // 1: comment.v1.CountCommentsResponse.counts:type_name -> comment.v1.CountCommentsResponse.CountsEntry
+	0, // 2: comment.v1.ListCommentsResponse.comments:type_name -> comment.v1.Comment
+	1, // 3: comment.v1.CommentService.CreateComment:input_type -> comment.v1.CreateCommentRequest
+	2, // 4: comment.v1.CommentService.ListComments:input_type -> comment.v1.ListCommentsRequest
+	3, // 5: comment.v1.CommentService.CountComments:input_type -> comment.v1.CountCommentsRequest
+	5, // 6: comment.v1.CommentService.SearchComments:input_type -> comment.v1.SearchCommentsRequest
+	6, // 7: comment.v1.CommentService.SetCommentStatus:input_type -> comment.v1.SetCommentStatusRequest
+	0, // 8: comment.v1.CommentService.CreateComment:output_type -> comment.v1.Comment
+	7, // 9: comment.v1.CommentService.ListComments:output_type -> comment.v1.ListCommentsResponse
+	4, // 10: comment.v1.CommentService.CountComments:output_type -> comment.v1.CountCommentsResponse
+	7, // 11: comment.v1.CommentService.SearchComments:output_type -> comment.v1.ListCommentsResponse
+	0, // 12: comment.v1.CommentService.SetCommentStatus:output_type -> comment.v1.Comment
+	8, // [8:13] is the sub-list for method output_type
+	3, // [3:8] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_pb_commentpb_comment_proto_init() }
+func file_pb_commentpb_comment_proto_init() {
+	if File_pb_commentpb_comment_proto != nil {
+		return
+	}
+	file_pb_commentpb_comment_proto_msgTypes[0].OneofWrappers = []any{}
+	file_pb_commentpb_comment_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pb_commentpb_comment_proto_rawDesc), len(file_pb_commentpb_comment_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pb_commentpb_comment_proto_goTypes,
+		DependencyIndexes: file_pb_commentpb_comment_proto_depIdxs,
+		MessageInfos:      file_pb_commentpb_comment_proto_msgTypes,
+	}.Build()
+	File_pb_commentpb_comment_proto = out.File
+	file_pb_commentpb_comment_proto_goTypes = nil
+	file_pb_commentpb_comment_proto_depIdxs = nil
+}
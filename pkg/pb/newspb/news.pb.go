@@ -0,0 +1,479 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.28.3
+// source: pb/newspb/news.proto
+
+package newspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type News struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title    string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content  string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Date     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	Source   string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	Category string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	Tags     []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	// score и highlight заполняются только при поиске
+	Score         float64    `protobuf:"fixed64,8,opt,name=score,proto3" json:"score,omitempty"`
+	Highlight     *Highlight `protobuf:"bytes,9,opt,name=highlight,proto3" json:"highlight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *News) Reset() {
+	*x = News{}
+	mi := &file_pb_newspb_news_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *News) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*News) ProtoMessage() {}
+
+func (x *News) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_newspb_news_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use News.ProtoReflect.Descriptor instead.
+func (*News) Descriptor() ([]byte, []int) {
+	return file_pb_newspb_news_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *News) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *News) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *News) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *News) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *News) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *News) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *News) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *News) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *News) GetHighlight() *Highlight {
+	if x != nil {
+		return x.Highlight
+	}
+	return nil
+}
+
+type Highlight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Highlight) Reset() {
+	*x = Highlight{}
+	mi := &file_pb_newspb_news_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Highlight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Highlight) ProtoMessage() {}
+
+func (x *Highlight) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_newspb_news_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Highlight.ProtoReflect.Descriptor instead.
+func (*Highlight) Descriptor() ([]byte, []int) {
+	return file_pb_newspb_news_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Highlight) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Highlight) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type ListNewsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Page     int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Search   string                 `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`
+	// from и to — RFC3339 или YYYY-MM-DD
+	From     string `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To       string `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Source   string `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Category string `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	// sort — date_desc, date_asc или relevance
+	Sort          string `protobuf:"bytes,8,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNewsRequest) Reset() {
+	*x = ListNewsRequest{}
+	mi := &file_pb_newspb_news_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNewsRequest) ProtoMessage() {}
+
+func (x *ListNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_newspb_news_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNewsRequest.ProtoReflect.Descriptor instead.
+func (*ListNewsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_newspb_news_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListNewsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListNewsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListNewsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListNewsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ListNewsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ListNewsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListNewsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ListNewsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type ListNewsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	News  []*News                `protobuf:"bytes,1,rep,name=news,proto3" json:"news,omitempty"`
+	// total — число новостей, подходящих под фильтр, на всех страницах
+	Total         int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNewsResponse) Reset() {
+	*x = ListNewsResponse{}
+	mi := &file_pb_newspb_news_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNewsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNewsResponse) ProtoMessage() {}
+
+func (x *ListNewsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_newspb_news_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNewsResponse.ProtoReflect.Descriptor instead.
+func (*ListNewsResponse) Descriptor() ([]byte, []int) {
+	return file_pb_newspb_news_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListNewsResponse) GetNews() []*News {
+	if x != nil {
+		return x.News
+	}
+	return nil
+}
+
+func (x *ListNewsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetNewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNewsRequest) Reset() {
+	*x = GetNewsRequest{}
+	mi := &file_pb_newspb_news_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNewsRequest) ProtoMessage() {}
+
+func (x *GetNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_newspb_news_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNewsRequest.ProtoReflect.Descriptor instead.
+func (*GetNewsRequest) Descriptor() ([]byte, []int) {
+	return file_pb_newspb_news_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetNewsRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+var File_pb_newspb_news_proto protoreflect.FileDescriptor
+
+const file_pb_newspb_news_proto_rawDesc = "" +
+	"\n" +
+	"\x14pb/newspb/news.proto\x12\anews.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x86\x02\n" +
+	"\x04News\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12.\n" +
+	"\x04date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12\x14\n" +
+	"\x05score\x18\b \x01(\x01R\x05score\x120\n" +
+	"\thighlight\x18\t \x01(\v2\x12.news.v1.HighlightR\thighlight\";\n" +
+	"\tHighlight\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"\xc6\x01\n" +
+	"\x0fListNewsRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06search\x18\x03 \x01(\tR\x06search\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x12\n" +
+	"\x04sort\x18\b \x01(\tR\x04sort\"K\n" +
+	"\x10ListNewsResponse\x12!\n" +
+	"\x04news\x18\x01 \x03(\v2\r.news.v1.NewsR\x04news\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\" \n" +
+	"\x0eGetNewsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id2\x81\x01\n" +
+	"\vNewsService\x12?\n" +
+	"\bListNews\x12\x18.news.v1.ListNewsRequest\x1a\x19.news.v1.ListNewsResponse\x121\n" +
+	"\aGetNews\x12\x17.news.v1.GetNewsRequest\x1a\r.news.v1.NewsB\x0fZ\rpkg/pb/newspbb\x06proto3"
+
+var (
+	file_pb_newspb_news_proto_rawDescOnce sync.Once
+	file_pb_newspb_news_proto_rawDescData []byte
+)
+
+func file_pb_newspb_news_proto_rawDescGZIP() []byte {
+	file_pb_newspb_news_proto_rawDescOnce.Do(func() {
+		file_pb_newspb_news_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pb_newspb_news_proto_rawDesc), len(file_pb_newspb_news_proto_rawDesc)))
+	})
+	return file_pb_newspb_news_proto_rawDescData
+}
+
+var file_pb_newspb_news_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_pb_newspb_news_proto_goTypes = []any{
+	(*News)(nil),                  // 0: news.v1.News
+	(*Highlight)(nil),             // 1: news.v1.Highlight
+	(*ListNewsRequest)(nil),       // 2: news.v1.ListNewsRequest
+	(*ListNewsResponse)(nil),      // 3: news.v1.ListNewsResponse
+	(*GetNewsRequest)(nil),        // 4: news.v1.GetNewsRequest
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_pb_newspb_news_proto_depIdxs = []int32{
+	5, // 0: news.v1.News.date:type_name -> google.protobuf.Timestamp
+	1, // 1: news.v1.News.highlight:type_name -> news.v1.Highlight
+	0, // 2: news.v1.ListNewsResponse.news:type_name -> news.v1.News
+	2, // 3: news.v1.NewsService.ListNews:input_type -> news.v1.ListNewsRequest
+	4, // 4: news.v1.NewsService.GetNews:input_type -> news.v1.GetNewsRequest
+	3, // 5: news.v1.NewsService.ListNews:output_type -> news.v1.ListNewsResponse
+	0, // 6: news.v1.NewsService.GetNews:output_type -> news.v1.News
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_pb_newspb_news_proto_init() }
+func file_pb_newspb_news_proto_init() {
+	if File_pb_newspb_news_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pb_newspb_news_proto_rawDesc), len(file_pb_newspb_news_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pb_newspb_news_proto_goTypes,
+		DependencyIndexes: file_pb_newspb_news_proto_depIdxs,
+		MessageInfos:      file_pb_newspb_news_proto_msgTypes,
+	}.Build()
+	File_pb_newspb_news_proto = out.File
+	file_pb_newspb_news_proto_goTypes = nil
+	file_pb_newspb_news_proto_depIdxs = nil
+}
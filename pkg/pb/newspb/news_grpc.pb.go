@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: pb/newspb/news.proto
+
+package newspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NewsService_ListNews_FullMethodName = "/news.v1.NewsService/ListNews"
+	NewsService_GetNews_FullMethodName  = "/news.v1.NewsService/GetNews"
+)
+
+// NewsServiceClient is the client API for NewsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NewsService — внутренний API News Aggregator
+type NewsServiceClient interface {
+	// ListNews — страница новостей с поиском и фильтрами
+	ListNews(ctx context.Context, in *ListNewsRequest, opts ...grpc.CallOption) (*ListNewsResponse, error)
+	// GetNews — новость по ID
+	GetNews(ctx context.Context, in *GetNewsRequest, opts ...grpc.CallOption) (*News, error)
+}
+
+type newsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNewsServiceClient(cc grpc.ClientConnInterface) NewsServiceClient {
+	return &newsServiceClient{cc}
+}
+
+func (c *newsServiceClient) ListNews(ctx context.Context, in *ListNewsRequest, opts ...grpc.CallOption) (*ListNewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNewsResponse)
+	err := c.cc.Invoke(ctx, NewsService_ListNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *newsServiceClient) GetNews(ctx context.Context, in *GetNewsRequest, opts ...grpc.CallOption) (*News, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(News)
+	err := c.cc.Invoke(ctx, NewsService_GetNews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NewsServiceServer is the server API for NewsService service.
+// All implementations must embed UnimplementedNewsServiceServer
+// for forward compatibility.
+//
+// NewsService — внутренний API News Aggregator
+type NewsServiceServer interface {
+	// ListNews — страница новостей с поиском и фильтрами
+	ListNews(context.Context, *ListNewsRequest) (*ListNewsResponse, error)
+	// GetNews — новость по ID
+	GetNews(context.Context, *GetNewsRequest) (*News, error)
+	mustEmbedUnimplementedNewsServiceServer()
+}
+
+// UnimplementedNewsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNewsServiceServer struct{}
+
+func (UnimplementedNewsServiceServer) ListNews(context.Context, *ListNewsRequest) (*ListNewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNews not implemented")
+}
+func (UnimplementedNewsServiceServer) GetNews(context.Context, *GetNewsRequest) (*News, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNews not implemented")
+}
+func (UnimplementedNewsServiceServer) mustEmbedUnimplementedNewsServiceServer() {}
+func (UnimplementedNewsServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeNewsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NewsServiceServer will
+// result in compilation errors.
+type UnsafeNewsServiceServer interface {
+	mustEmbedUnimplementedNewsServiceServer()
+}
+
+func RegisterNewsServiceServer(s grpc.ServiceRegistrar, srv NewsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedNewsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NewsService_ServiceDesc, srv)
+}
+
+func _NewsService_ListNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).ListNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_ListNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).ListNews(ctx, req.(*ListNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NewsService_GetNews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NewsServiceServer).GetNews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NewsService_GetNews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NewsServiceServer).GetNews(ctx, req.(*GetNewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NewsService_ServiceDesc is the grpc.ServiceDesc for NewsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NewsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "news.v1.NewsService",
+	HandlerType: (*NewsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListNews",
+			Handler:    _NewsService_ListNews_Handler,
+		},
+		{
+			MethodName: "GetNews",
+			Handler:    _NewsService_GetNews_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/newspb/news.proto",
+}
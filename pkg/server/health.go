@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pkg/buildinfo"
+	"pkg/httpx"
+)
+
+// CheckTimeout — сколько ждать одну проверку готовности
+const CheckTimeout = 2 * time.Second
+
+// Check — проверка зависимости сервиса; nil означает, что зависимость доступна
+type Check func(ctx context.Context) error
+
+// Health — состояние сервиса для проб /livez и /readyz
+type Health struct {
+	mu       sync.RWMutex
+	names    []string
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+// LivenessReport — ответ /livez с версией сервиса
+type LivenessReport struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
+
+// ReadinessReport — ответ /readyz с результатом каждой проверки
+type ReadinessReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func NewHealth() *Health {
+	return &Health{checks: make(map[string]Check)}
+}
+
+// AddCheck — регистрирует проверку готовности под именем name
+func (h *Health) AddCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+}
+
+// SetDraining — переводит сервис в режим остановки: /readyz начинает отвечать 503,
+// чтобы балансировщик перестал направлять сюда новые запросы
+func (h *Health) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Draining — сообщает, идет ли остановка сервиса
+func (h *Health) Draining() bool {
+	return h.draining.Load()
+}
+
+// Livez — проба живости: процесс запущен и обрабатывает запросы
+func (h *Health) Livez(w http.ResponseWriter, r *http.Request) {
+	httpx.SendJSON(w, http.StatusOK, LivenessReport{Status: "ok", Version: buildinfo.Version()})
+}
+
+// Readyz — проба готовности: все зависимости доступны и сервис не останавливается
+func (h *Health) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.Draining() {
+		httpx.SendJSON(w, http.StatusServiceUnavailable, ReadinessReport{Status: "draining"})
+		return
+	}
+
+	report := h.Check(r.Context())
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	httpx.SendJSON(w, status, report)
+}
+
+// Check — параллельно выполняет все проверки готовности
+func (h *Health) Check(ctx context.Context) ReadinessReport {
+	h.mu.RLock()
+	names := append([]string(nil), h.names...)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = h.checks[name]
+	}
+	h.mu.RUnlock()
+
+	results := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+			defer cancel()
+			results[i] = check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := ReadinessReport{Status: "ok", Checks: make(map[string]string, len(names))}
+	for i, name := range names {
+		if results[i] != nil {
+			report.Status = "unavailable"
+			report.Checks[name] = results[i].Error()
+			continue
+		}
+		report.Checks[name] = "ok"
+	}
+	return report
+}
+
+// HTTPCheck — проверка, что сервис по адресу url отвечает успешным статусом
+func HTTPCheck(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
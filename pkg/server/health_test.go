@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyz(t *testing.T) {
+	health := NewHealth()
+	dbErr := error(nil)
+	health.AddCheck("database", func(ctx context.Context) error { return dbErr })
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+	health.AddCheck("upstream", HTTPCheck(http.DefaultClient, upstream.URL))
+
+	readyz := func() (int, ReadinessReport) {
+		rr := httptest.NewRecorder()
+		health.Readyz(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report ReadinessReport
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		return rr.Code, report
+	}
+
+	if code, report := readyz(); code != http.StatusOK || report.Checks["database"] != "ok" || report.Checks["upstream"] != "ok" {
+		t.Errorf("Ожидалась готовность, получено %d %+v", code, report)
+	}
+
+	dbErr = errors.New("database is locked")
+	upstream.Close()
+	code, report := readyz()
+	if code != http.StatusServiceUnavailable || report.Status != "unavailable" {
+		t.Errorf("Ожидался статус 503, получено %d %+v", code, report)
+	}
+	if report.Checks["database"] != "database is locked" || report.Checks["upstream"] == "ok" {
+		t.Errorf("Неверные результаты проверок: %+v", report.Checks)
+	}
+
+	dbErr = nil
+	health.SetDraining()
+	if code, report := readyz(); code != http.StatusServiceUnavailable || report.Status != "draining" {
+		t.Errorf("Во время остановки ожидался статус draining, получено %d %+v", code, report)
+	}
+}
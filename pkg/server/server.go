@@ -0,0 +1,188 @@
+// Package server — общий запуск HTTP-сервисов: логгер, базовый роутер,
+// пробы живости и готовности и сервер с корректным завершением по SIGINT/SIGTERM.
+// Рядом с HTTP сервер может обслуживать внутренний gRPC API.
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+
+	"pkg/config"
+	"pkg/httpx"
+)
+
+// Значения по умолчанию для остановки сервера
+const (
+	DefaultDrainDelay      = 5 * time.Second
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// ShutdownConfig — параметры остановки сервера в конфигурации сервиса
+type ShutdownConfig struct {
+	DrainDelay time.Duration `yaml:"drain_delay" desc:"сколько /readyz отвечает 503 перед остановкой"`
+	Timeout    time.Duration `yaml:"timeout" desc:"время на завершение активных запросов"`
+}
+
+// DefaultShutdownConfig — параметры остановки по умолчанию
+func DefaultShutdownConfig() ShutdownConfig {
+	return ShutdownConfig{DrainDelay: DefaultDrainDelay, Timeout: DefaultShutdownTimeout}
+}
+
+// Validate — проверяет параметры остановки; prefix — путь раздела в конфигурации
+func (c ShutdownConfig) Validate(errs *config.Errors, prefix string) {
+	errs.NonNegativeDuration(prefix+".drain_delay", c.DrainDelay)
+	errs.PositiveDuration(prefix+".timeout", c.Timeout)
+}
+
+// NewLogger — создает логгер сервиса; каждая запись содержит имя сервиса
+func NewLogger(service string) zerolog.Logger {
+	return zerolog.New(os.Stdout).With().Timestamp().Str("service", service).Logger()
+}
+
+// NewRouter — создает роутер с общими мидлварами, обработчиками ошибок
+// маршрутизации в формате problem+json и пробами /livez, /readyz (и /health как псевдонимом /livez).
+// Мидлвары сервиса передаются в middlewares: chi не позволяет добавлять их после маршрутов.
+func NewRouter(logger zerolog.Logger, health *Health, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.Recoverer)
+	r.Use(httpx.RequestIDMiddleware)
+	r.Use(httpx.LoggerMiddleware(logger))
+	r.Use(middlewares...)
+	r.NotFound(httpx.NotFound)
+	r.MethodNotAllowed(httpx.MethodNotAllowed)
+	r.Get("/health", health.Livez)
+	r.Get("/livez", health.Livez)
+	r.Get("/readyz", health.Readyz)
+	return r
+}
+
+// Server — HTTP-сервер сервиса с корректным завершением
+type Server struct {
+	Addr    string
+	Handler http.Handler
+	Logger  zerolog.Logger
+	Health  *Health
+	// GRPCAddr и GRPC — необязательный gRPC-сервер, который запускается и останавливается вместе с HTTP
+	GRPCAddr string
+	GRPC     *grpc.Server
+	// DrainDelay — сколько /readyz отвечает 503 до остановки приема соединений,
+	// чтобы балансировщик успел исключить экземпляр
+	DrainDelay time.Duration
+	// ShutdownTimeout — время на завершение активных запросов; 0 — DefaultShutdownTimeout
+	ShutdownTimeout time.Duration
+	// OnShutdown — вызываются после завершения активных запросов (закрытие БД и т.п.)
+	OnShutdown []func() error
+}
+
+// Run — запускает сервер и блокируется до его остановки по SIGINT/SIGTERM.
+// Повторный сигнал во время остановки завершает процесс сразу.
+func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return s.Serve(ctx)
+}
+
+// Serve — запускает сервер и останавливает его при отмене ctx:
+// сначала /readyz переходит в 503 на DrainDelay, затем сервер перестает принимать
+// соединения и ждет активные запросы не дольше ShutdownTimeout.
+func (s *Server) Serve(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.Logger.Info().Str("addr", s.Addr).Msg("server started")
+		errCh <- srv.ListenAndServe()
+	}()
+
+	grpcErrCh := make(chan error, 1)
+	if s.GRPC != nil {
+		lis, err := net.Listen("tcp", s.GRPCAddr)
+		if err != nil {
+			srv.Close()
+			return err
+		}
+		go func() {
+			s.Logger.Info().Str("addr", s.GRPCAddr).Msg("grpc server started")
+			grpcErrCh <- s.GRPC.Serve(lis)
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		if s.GRPC != nil {
+			s.GRPC.Stop()
+		}
+		return err
+	case err := <-grpcErrCh:
+		srv.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownTimeout := s.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+
+	if s.Health != nil {
+		s.Health.SetDraining()
+		s.Logger.Info().Dur("delay", s.DrainDelay).Msg("draining")
+		time.Sleep(s.DrainDelay)
+	}
+
+	s.Logger.Info().Msg("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if s.GRPC != nil {
+		go stopGRPC(shutdownCtx, s.GRPC)
+	}
+	err := srv.Shutdown(shutdownCtx)
+	if serveErr := <-errCh; err == nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		err = serveErr
+	}
+	if s.GRPC != nil {
+		if serveErr := <-grpcErrCh; err == nil {
+			err = serveErr
+		}
+	}
+
+	for _, fn := range s.OnShutdown {
+		if cerr := fn(); cerr != nil {
+			s.Logger.Error().Err(cerr).Msg("shutdown hook failed")
+		}
+	}
+	return err
+}
+
+// stopGRPC — ждет завершения активных вызовов gRPC, а по истечении ctx обрывает их
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
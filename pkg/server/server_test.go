@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"pkg/httpx"
+)
+
+func TestNewRouter(t *testing.T) {
+	r := NewRouter(zerolog.Nop(), NewHealth())
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/health", "/livez", "/readyz"} {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != http.StatusOK || rr.Header().Get(httpx.RequestIDHeader) == "" {
+			t.Errorf("%s должен отвечать 200 с X-Request-ID, получено %d %v", path, rr.Code, rr.Header())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rr.Code != http.StatusNotFound || rr.Header().Get("Content-Type") != httpx.ProblemContentType {
+		t.Errorf("Неизвестный маршрут должен возвращать 404 problem+json, получено %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/ping", nil))
+	if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Content-Type") != httpx.ProblemContentType {
+		t.Errorf("Неподдерживаемый метод должен возвращать 405 problem+json, получено %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func getStatus(url string) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	addr := freeAddr(t)
+	health := NewHealth()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := NewRouter(zerolog.Nop(), health)
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	var hookCalled atomic.Bool
+	srv := &Server{
+		Addr:            addr,
+		Handler:         r,
+		Logger:          zerolog.Nop(),
+		Health:          health,
+		DrainDelay:      200 * time.Millisecond,
+		ShutdownTimeout: 2 * time.Second,
+		OnShutdown: []func() error{func() error {
+			hookCalled.Store(true)
+			return nil
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+
+	for i := 0; i < 50 && getStatus("http://"+addr+"/readyz") != http.StatusOK; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			respCh <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Сервер не принял запрос")
+	}
+	cancel()
+
+	// Во время задержки сервер еще принимает запросы, но уже не готов
+	time.Sleep(50 * time.Millisecond)
+	if code := getStatus("http://" + addr + "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("Во время остановки /readyz должен отвечать 503, получено %d", code)
+	}
+	if code := getStatus("http://" + addr + "/livez"); code != http.StatusOK {
+		t.Errorf("Во время остановки /livez должен отвечать 200, получено %d", code)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if hookCalled.Load() {
+		t.Error("OnShutdown вызван до завершения активного запроса")
+	}
+	close(release)
+
+	if body := <-respCh; body != "done" {
+		t.Errorf("Активный запрос должен завершиться при остановке, получено %q", body)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Serve вернул ошибку при штатной остановке: %v", err)
+	}
+	if !hookCalled.Load() {
+		t.Error("OnShutdown не вызван после остановки")
+	}
+}
+
+func TestServeGRPC(t *testing.T) {
+	grpcSrv := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcSrv, grpchealth.NewServer())
+	srv := &Server{
+		Addr:            freeAddr(t),
+		Handler:         NewRouter(zerolog.Nop(), NewHealth()),
+		Logger:          zerolog.Nop(),
+		GRPCAddr:        freeAddr(t),
+		GRPC:            grpcSrv,
+		ShutdownTimeout: time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+
+	conn, err := grpc.NewClient(srv.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	callCtx, callCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer callCancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(callCtx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("gRPC-сервер должен обслуживать вызовы рядом с HTTP: %v %v", resp, err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve вернул ошибку при штатной остановке: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("gRPC-сервер не остановился вместе с HTTP")
+	}
+}
@@ -0,0 +1,221 @@
+package textx
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// linkSchemes — схемы ссылок, которые допускаются в разметке; остальные (javascript:, data: и т. п.)
+// выводятся обычным текстом
+var linkSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// linkRel — атрибут rel ссылок из пользовательского текста
+const linkRel = "nofollow noopener ugc"
+
+// RenderMarkdown — безопасное подмножество Markdown в HTML. Любой HTML во входном тексте экранируется,
+// поэтому результат можно вставлять в страницу как есть.
+//
+// Блоки: абзацы через пустую строку (перевод строки внутри абзаца — <br>), списки «- », «* » и «1. »,
+// цитаты «> » и блоки кода между строками ```. Внутри строк: `код`, **жирный**, *курсив*, ~~зачеркнутый~~,
+// ссылки [текст](http://...) и экранирование символов разметки обратной косой чертой.
+func RenderMarkdown(s string) string {
+	var sb strings.Builder
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case strings.HasPrefix(strings.TrimSpace(line), "```"):
+			i = renderCode(&sb, lines, i+1)
+		case strings.HasPrefix(line, ">"):
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(lines[i], ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(lines[i], ">"), " "))
+			}
+			sb.WriteString("<blockquote>")
+			sb.WriteString(RenderMarkdown(strings.Join(quote, "\n")))
+			sb.WriteString("</blockquote>")
+		case listItem(line, false) != "":
+			i = renderList(&sb, lines, i, false)
+		case listItem(line, true) != "":
+			i = renderList(&sb, lines, i, true)
+		default:
+			var para []string
+			for ; i < len(lines) && !blockStart(lines[i]); i++ {
+				para = append(para, strings.TrimSpace(lines[i]))
+			}
+			sb.WriteString("<p>")
+			for j, l := range para {
+				if j > 0 {
+					sb.WriteString("<br>")
+				}
+				renderInline(&sb, l)
+			}
+			sb.WriteString("</p>")
+		}
+	}
+	return sb.String()
+}
+
+// blockStart — строка заканчивает абзац: пустая или начинает другой блок
+func blockStart(line string) bool {
+	return strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "```") ||
+		strings.HasPrefix(line, ">") || listItem(line, false) != "" || listItem(line, true) != ""
+}
+
+// renderCode — блок кода от строки start до закрывающей ```; незакрытый блок продолжается до конца текста
+func renderCode(sb *strings.Builder, lines []string, start int) int {
+	i := start
+	for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+		i++
+	}
+	sb.WriteString("<pre><code>")
+	sb.WriteString(html.EscapeString(strings.Join(lines[start:i], "\n")))
+	sb.WriteString("</code></pre>")
+	return i + 1
+}
+
+// listItem — текст пункта списка или пустая строка, если строка не пункт
+func listItem(line string, ordered bool) string {
+	if !ordered {
+		for _, marker := range []string{"- ", "* "} {
+			if item, ok := strings.CutPrefix(line, marker); ok && strings.TrimSpace(item) != "" {
+				return item
+			}
+		}
+		return ""
+	}
+	digits := 0
+	for digits < len(line) && digits < 9 && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if item, ok := strings.CutPrefix(line[digits:], ". "); digits > 0 && ok && strings.TrimSpace(item) != "" {
+		return item
+	}
+	return ""
+}
+
+func renderList(sb *strings.Builder, lines []string, i int, ordered bool) int {
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	sb.WriteString("<" + tag + ">")
+	for ; i < len(lines); i++ {
+		item := listItem(lines[i], ordered)
+		if item == "" {
+			break
+		}
+		sb.WriteString("<li>")
+		renderInline(sb, strings.TrimSpace(item))
+		sb.WriteString("</li>")
+	}
+	sb.WriteString("</" + tag + ">")
+	return i
+}
+
+// inlineEscapable — символы, которые можно экранировать обратной косой чертой
+const inlineEscapable = "\\`*_~[]()>#-!."
+
+// renderInline — разметка внутри строки; незакрытые маркеры выводятся как текст
+func renderInline(sb *strings.Builder, s string) {
+	plain := 0 // начало текста, еще не записанного в sb
+	flush := func(end int) {
+		sb.WriteString(html.EscapeString(s[plain:end]))
+	}
+	for i := 0; i < len(s); {
+		var n int
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && strings.IndexByte(inlineEscapable, s[i+1]) >= 0:
+			flush(i)
+			sb.WriteString(html.EscapeString(s[i+1 : i+2]))
+			n = 2
+		case s[i] == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				flush(i)
+				sb.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				n = end + 2
+			}
+		case strings.HasPrefix(s[i:], "**"):
+			n = renderSpan(sb, s, i, "**", "strong", flush)
+		case strings.HasPrefix(s[i:], "~~"):
+			n = renderSpan(sb, s, i, "~~", "del", flush)
+		case s[i] == '*':
+			n = renderSpan(sb, s, i, "*", "em", flush)
+		case s[i] == '[':
+			n = renderLink(sb, s, i, flush)
+		}
+		if n == 0 {
+			i++
+			continue
+		}
+		i += n
+		plain = i
+	}
+	flush(len(s))
+}
+
+// renderSpan — выделение между маркерами marker; возвращает длину разобранного фрагмента или 0.
+// Как в CommonMark, после открывающего маркера и перед закрывающим не должно быть пробела.
+func renderSpan(sb *strings.Builder, s string, i int, marker, tag string, flush func(int)) int {
+	inner := s[i+len(marker):]
+	if inner == "" || inner[0] == ' ' {
+		return 0
+	}
+	end := closingMarker(inner, marker)
+	if end <= 0 {
+		return 0
+	}
+	flush(i)
+	sb.WriteString("<" + tag + ">")
+	renderInline(sb, inner[:end])
+	sb.WriteString("</" + tag + ">")
+	return len(marker)*2 + end
+}
+
+// closingMarker — позиция закрывающего маркера или -1. В серии одинаковых символов закрывающим считается
+// конец серии («***» закрывает «**» после вложенного «*»); одиночный маркер закрывается только одиночным.
+func closingMarker(s, marker string) int {
+	for from := 0; from < len(s); {
+		k := strings.Index(s[from:], marker)
+		if k < 0 {
+			return -1
+		}
+		k += from
+		run := k
+		for run < len(s) && s[run] == marker[0] {
+			run++
+		}
+		if end := run - len(marker); end > 0 && s[end-1] != ' ' && (len(marker) > 1 || run-k == 1) {
+			return end
+		}
+		from = run
+	}
+	return -1
+}
+
+// renderLink — ссылка [текст](адрес); возвращает длину разобранного фрагмента или 0.
+// Ссылка с недопустимой схемой выводится как текст без адреса.
+func renderLink(sb *strings.Builder, s string, i int, flush func(int)) int {
+	labelEnd := strings.Index(s[i:], "](")
+	if labelEnd <= 1 {
+		return 0
+	}
+	hrefEnd := strings.IndexByte(s[i+labelEnd+2:], ')')
+	if hrefEnd <= 0 {
+		return 0
+	}
+	label := s[i+1 : i+labelEnd]
+	href := s[i+labelEnd+2 : i+labelEnd+2+hrefEnd]
+	flush(i)
+	if u, err := url.Parse(href); err == nil && linkSchemes[strings.ToLower(u.Scheme)] {
+		sb.WriteString(`<a href="` + html.EscapeString(u.String()) + `" rel="` + linkRel + `">`)
+		renderInline(sb, label)
+		sb.WriteString("</a>")
+	} else {
+		renderInline(sb, label)
+	}
+	return labelEnd + 2 + hrefEnd + 1
+}
@@ -0,0 +1,68 @@
+package textx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"пустой текст", "", ""},
+		{"абзацы и переводы строк", "Первая\nвторая\n\nТретья", "<p>Первая<br>вторая</p><p>Третья</p>"},
+		{"выделение", "**жирный** *курсив* ~~нет~~ `a<b`", "<p><strong>жирный</strong> <em>курсив</em> <del>нет</del> <code>a&lt;b</code></p>"},
+		{"вложенное выделение", "**очень *важно***", "<p><strong>очень <em>важно</em></strong></p>"},
+		{"незакрытые маркеры", "2 * 3 = 6, **ой", "<p>2 * 3 = 6, **ой</p>"},
+		{"одни маркеры", "**** ~~ * `", "<p>**** ~~ * `</p>"},
+		{"экранирование", `\*не курсив\*`, "<p>*не курсив*</p>"},
+		{"ссылка", "[новость](https://example.com/a?b=1&c=2)", `<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener ugc">новость</a></p>`},
+		{"список", "- один\n- **два**\n\n1. первый\n2. второй", "<ul><li>один</li><li><strong>два</strong></li></ul><ol><li>первый</li><li>второй</li></ol>"},
+		{"цитата", "> цитата\n> *вторая*\nответ", "<blockquote><p>цитата<br><em>вторая</em></p></blockquote><p>ответ</p>"},
+		{"блок кода", "```\n<b>**не жирный**</b>\n```\nпосле", "<pre><code>&lt;b&gt;**не жирный**&lt;/b&gt;</code></pre><p>после</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.in); got != tt.want {
+				t.Errorf("Ожидалось\n%s\nполучено\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownXSS(t *testing.T) {
+	tests := map[string]string{
+		"тег script":               `<script>alert(1)</script>`,
+		"атрибут-обработчик":       `<img src=x onerror=alert(1)>`,
+		"схема javascript":         `[клик](javascript:alert(1))`,
+		"схема в верхнем регистре": `[клик](JaVaScRiPt:alert(1))`,
+		"схема data":               `[клик](data:text/html;base64,PHNjcmlwdD4=)`,
+		"выход из атрибута":        `[клик](https://example.com/"onmouseover="alert(1))`,
+		"разметка в коде":          "`<iframe src=//evil>`",
+		"тег в ссылке":             `[<svg onload=alert(1)>](https://example.com)`,
+	}
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := strings.ToLower(RenderMarkdown(in))
+			for _, bad := range []string{"<script", "<img", "<iframe", "<svg", "javascript:", "data:", `"onmouseover`} {
+				if strings.Contains(got, bad) {
+					t.Errorf("Результат содержит %q: %s", bad, got)
+				}
+			}
+		})
+	}
+}
+
+func FuzzRenderMarkdown(f *testing.F) {
+	for _, seed := range []string{"**a *b***", "[x](http://a)", "> - `c`", "```\n<b>", "\\*"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got := RenderMarkdown(Sanitize(s))
+		if strings.Contains(strings.ToLower(got), "<script") {
+			t.Errorf("Разметка не должна пропускать теги: %q → %q", s, got)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package textx
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// zeroWidthJoiner — соединяет эмодзи в один видимый символ, например «семью» из трех человечков
+const zeroWidthJoiner = '\u200d'
+
+// Normalize — приводит введенный текст к виду для хранения: очищает его (Sanitize),
+// приводит к форме NFC и убирает пробелы по краям
+func Normalize(s string) string {
+	return strings.TrimSpace(norm.NFC.String(Sanitize(s)))
+}
+
+// Length — длина текста в видимых символах (кластерах графем), а не в байтах: «ё» из «е» и комбинирующего
+// знака, эмодзи с модификатором цвета кожи, последовательность эмодзи через ZWJ и флаг из двух региональных
+// индикаторов считаются одним символом. Это упрощение правил UAX #29, достаточное для ограничений длины.
+func Length(s string) int {
+	n := 0
+	joined := false   // предыдущий символ — ZWJ: следующий продолжает кластер
+	flagHalf := false // предыдущий региональный индикатор начал флаг
+	for _, r := range s {
+		switch {
+		case n > 0 && (joined || extendsCluster(r)):
+		case n > 0 && flagHalf && isRegionalIndicator(r):
+			flagHalf = false
+			joined = false
+			continue
+		default:
+			n++
+		}
+		joined = r == zeroWidthJoiner
+		flagHalf = isRegionalIndicator(r) && !flagHalf
+	}
+	return n
+}
+
+// extendsCluster — символ, который не начинает новый кластер: комбинирующие знаки, ZWJ, селекторы вариантов,
+// модификаторы цвета кожи и теги флагов
+func extendsCluster(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zeroWidthJoiner ||
+		(r >= '\ufe00' && r <= '\ufe0f') ||
+		(r >= '\U0001f3fb' && r <= '\U0001f3ff') ||
+		(r >= '\U000e0020' && r <= '\U000e007f')
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= '\U0001f1e6' && r <= '\U0001f1ff'
+}
@@ -0,0 +1,60 @@
+package textx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"пробелы по краям", "  \n\tПривет, мир!\n ", "Привет, мир!"},
+		{"комбинирующий знак в NFC", "е\u0308ж и и\u0306од", "\u0451ж и \u0439од"},
+		{"эмодзи не меняются", " \U0001f44d\U0001f3fd ", "\U0001f44d\U0001f3fd"},
+		{"только пробелы и управляющие символы", " \x00\u202e\t ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Ожидалось %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"пусто", "", 0},
+		{"латиница", "hello", 5},
+		{"кириллица", "Привет", 6},
+		{"комбинирующие знаки", "е\u0308ж", 2},
+		{"несколько знаков на букве", "a\u0323\u0301", 1},
+		{"эмодзи", "😀😀", 2},
+		{"модификатор цвета кожи", "\U0001f44d\U0001f3fd", 1},
+		{"селектор варианта", "\u2764\ufe0f", 1},
+		{"последовательность через ZWJ", "\U0001f468\u200d\U0001f469\u200d\U0001f467", 1},
+		{"флаги", "\U0001f1f7\U0001f1fa\U0001f1fa\U0001f1f8", 2},
+		{"нечетный региональный индикатор", "\U0001f1f7\U0001f1fa\U0001f1fa", 2},
+		{"смешанный текст", "Ура 🎉 е\u0308!", 8},
+		{"знак в начале", "\u0301a", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Length(tt.in); got != tt.want {
+				t.Errorf("%q: ожидалась длина %d, получено %d", tt.in, tt.want, got)
+			}
+		})
+	}
+
+	// Ограничение в 1000 символов — это 1000 русских букв, а не 500, как при подсчете байтов
+	if got := Length(strings.Repeat("ж", 1000)); got != 1000 {
+		t.Errorf("Ожидалась длина 1000, получено %d", got)
+	}
+}
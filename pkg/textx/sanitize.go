@@ -0,0 +1,85 @@
+// Package textx — обработка пользовательского текста: очистка ввода и безопасная разметка Markdown.
+package textx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Sanitize — очищает введенный текст: удаляет некорректные последовательности UTF-8, управляющие символы,
+// кроме перевода строки и табуляции, символы управления направлением письма, которыми можно выдать
+// один текст за другой, и невидимые символы формата (категория Cf: пробелы нулевой ширины, мягкий перенос,
+// U+FEFF), которыми можно разбить запрещенное слово. ZWJ и ZWNJ остаются там, где влияют на отображение
+// (эмодзи, арабское и индийские письма), теги — в флагах регионов. Переводы строк \r\n и \r приводятся к \n.
+func Sanitize(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+	for i, r := range runes {
+		switch {
+		case r == '\r':
+			out = append(out, '\n')
+		case r == '\n' || r == '\t':
+			out = append(out, r)
+		case unicode.IsControl(r), isBidiControl(r):
+		case unicode.Is(unicode.Cf, r):
+			if keepFormat(r, lastRune(out), nextVisible(runes[i+1:])) {
+				out = append(out, r)
+			}
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// isBidiControl — встраивания, переопределения и изоляты направления письма (U+202A–U+202E, U+2066–U+2069)
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// Символы формата, которые нужны для отображения
+const (
+	zeroWidthNonJoiner = '\u200c'
+	blackFlag          = '\U0001f3f4' // начало флага региона из тегов U+E0020–U+E007F
+)
+
+// keepFormat — оставить символ формата r между prev и next. ZWJ и ZWNJ рядом с буквами и цифрами
+// латиницы, кириллицы и греческого ничего не меняют в отображении и удаляются, теги допускаются
+// только в последовательности флага.
+func keepFormat(r, prev, next rune) bool {
+	switch {
+	case r == zeroWidthJoiner || r == zeroWidthNonJoiner:
+		return prev != 0 && next != 0 && !isAlphanumeric(prev) && !isAlphanumeric(next)
+	case isTag(r):
+		return prev == blackFlag || isTag(prev)
+	}
+	return false
+}
+
+// isAlphanumeric — буква латиницы, кириллицы или греческого либо цифра
+func isAlphanumeric(r rune) bool {
+	return unicode.In(r, unicode.Latin, unicode.Cyrillic, unicode.Greek, unicode.Nd)
+}
+
+func isTag(r rune) bool {
+	return r >= '\U000e0020' && r <= '\U000e007f'
+}
+
+func lastRune(runes []rune) rune {
+	if len(runes) == 0 {
+		return 0
+	}
+	return runes[len(runes)-1]
+}
+
+// nextVisible — первый символ, который не будет удален как управляющий или символ формата
+func nextVisible(runes []rune) rune {
+	for _, r := range runes {
+		if !unicode.IsControl(r) && !unicode.Is(unicode.Cf, r) {
+			return r
+		}
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package textx
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"обычный текст", "Привет, мир! 😀", "Привет, мир! 😀"},
+		{"переводы строк и табуляция", "раз\r\nдва\rтри\tчетыре", "раз\nдва\nтри\tчетыре"},
+		{"управляющие символы", "ка\x00зи\x1bно\x7f\u0085", "казино"},
+		{"некорректный UTF-8", "при\xffвет\xc3", "привет"},
+		{"направление письма", "abc\u202edcba\u2066x\u2069", "abcdcbax"},
+		{"комбинирующие символы сохраняются", "е\u0308ж", "е\u0308ж"},
+		{"символы нулевой ширины", "qwe\u200brty q\u2060w\ufeffe\u00adr\u180ety", "qwerty qwerty"},
+		{"ZWJ и ZWNJ между буквами", "qwe\u200drty спа\u200cм 1\u200d2", "qwerty спам 12"},
+		{"подряд идущие ZWJ", "qw\u200d\u200d\u200d\u200berty", "qwerty"},
+		{"ZWJ в эмодзи", "\U0001f468\u200d\U0001f469\u200d\U0001f467 \U0001f3f3\ufe0f\u200d\U0001f308", "\U0001f468\u200d\U0001f469\u200d\U0001f467 \U0001f3f3\ufe0f\u200d\U0001f308"},
+		{"ZWNJ в персидском", "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645", "\u0645\u06cc\u200c\u062e\u0648\u0627\u0647\u0645"},
+		{"ZWJ по краям", "\u200d\U0001f600\u200d", "\U0001f600"},
+		{"флаг региона из тегов", "\U0001f3f4\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f", "\U0001f3f4\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f"},
+		{"теги вне флага", "qw\U000e0061erty", "qwerty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.in); got != tt.want {
+				t.Errorf("Ожидалось %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}